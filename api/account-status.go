@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// accountStatusTTL is how long the status of an account is cached. Restrictions and deletions made
+// by this server apply immediately, those made by other instances apply within the TTL.
+const accountStatusTTL = 30 * time.Second
+
+var (
+	errAccountRestricted = errors.New("Account has been restricted.")
+	errAccountDeleted    = errors.New("Account no longer exists in our records. Attempt account recovery")
+)
+
+type accountStatus struct {
+	restricted bool
+	deleted    bool
+	expiresAt  time.Time
+}
+
+// accountStatusCache caches whether accounts are restricted or deleted, so that authenticated
+// requests can be rejected without querying the database on every request.
+type accountStatusCache struct {
+	store    db.Store
+	ttl      time.Duration
+	mutex    sync.Mutex
+	statuses map[uuid.UUID]accountStatus
+	// generation is incremented by every invalidation, statuses read from the database before an invalidation are not cached.
+	generation uint64
+}
+
+func newAccountStatusCache(store db.Store, ttl time.Duration) *accountStatusCache {
+	return &accountStatusCache{
+		store:    store,
+		ttl:      ttl,
+		statuses: make(map[uuid.UUID]accountStatus),
+	}
+}
+
+// check returns an error describing why the account cannot be used, or nil when it is active.
+// Accounts that no longer exist are treated as deleted.
+func (cache *accountStatusCache) check(ctx context.Context, authID uuid.UUID) error {
+	status, err := cache.get(ctx, authID)
+	if err != nil {
+		return err
+	}
+
+	if status.restricted {
+		return errAccountRestricted
+	}
+	if status.deleted {
+		return errAccountDeleted
+	}
+	return nil
+}
+
+func (cache *accountStatusCache) get(ctx context.Context, authID uuid.UUID) (accountStatus, error) {
+	now := time.Now()
+
+	cache.mutex.Lock()
+	status, ok := cache.statuses[authID]
+	generation := cache.generation
+	cache.mutex.Unlock()
+	if ok && now.Before(status.expiresAt) {
+		return status, nil
+	}
+
+	auth, err := cache.store.GetAuth(ctx, authID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			return accountStatus{}, err
+		}
+		auth.Deleted = true
+	}
+
+	status = accountStatus{
+		restricted: auth.Restricted,
+		deleted:    auth.Deleted,
+		expiresAt:  now.Add(cache.ttl),
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.pruneLocked(now)
+	// the status may have changed since it was read, when an invalidation happened meanwhile
+	if cache.generation == generation {
+		cache.statuses[authID] = status
+	}
+
+	return status, nil
+}
+
+// invalidate removes the cached status of an account, the next request reads it from the database.
+func (cache *accountStatusCache) invalidate(authID uuid.UUID) {
+	cache.mutex.Lock()
+	delete(cache.statuses, authID)
+	cache.generation++
+	cache.mutex.Unlock()
+}
+
+// pruneLocked removes expired statuses, the caller must hold the mutex.
+func (cache *accountStatusCache) pruneLocked(now time.Time) {
+	for authID, status := range cache.statuses {
+		if !now.Before(status.expiresAt) {
+			delete(cache.statuses, authID)
+		}
+	}
+}
+
+// accountStatusMiddleware creates a gin middleware that rejects requests of restricted or deleted accounts,
+// whose tokens remain valid until they expire.
+func accountStatusMiddleware(statuses *accountStatusCache) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		err := statuses.check(ctx, authPayload.UserID)
+		if err != nil {
+			if err == errAccountRestricted || err == errAccountDeleted {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, apiErrorResponse(err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, apiErrorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// revokeAccountAccess applies a restriction or deletion of an account immediately,
+// rejecting its further requests and closing its open chat connections.
+func (server *Server) revokeAccountAccess(authID uuid.UUID) {
+	server.accountStatuses.invalidate(authID)
+	server.websocket.DisconnectUser(authID)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthStore returns the auth of its accounts and counts the reads, reads wait for block when it is set.
+type fakeAuthStore struct {
+	db.Store
+
+	mutex sync.Mutex
+	auths map[uuid.UUID]db.GetAuthRow
+	reads int
+	block chan struct{}
+}
+
+func (store *fakeAuthStore) GetAuth(ctx context.Context, id uuid.UUID) (db.GetAuthRow, error) {
+	store.mutex.Lock()
+	auth, ok := store.auths[id]
+	store.reads++
+	block := store.block
+	store.mutex.Unlock()
+
+	if block != nil {
+		<-block
+	}
+	if !ok {
+		return db.GetAuthRow{}, sql.ErrNoRows
+	}
+	return auth, nil
+}
+
+func (store *fakeAuthStore) set(auth db.GetAuthRow) {
+	store.mutex.Lock()
+	store.auths[auth.ID] = auth
+	store.mutex.Unlock()
+}
+
+func (store *fakeAuthStore) readCount() int {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.reads
+}
+
+func TestAccountStatusCache(t *testing.T) {
+	authID := uuid.New()
+	store := &fakeAuthStore{auths: map[uuid.UUID]db.GetAuthRow{authID: {ID: authID}}}
+	cache := newAccountStatusCache(store, 50*time.Millisecond)
+
+	require.NoError(t, cache.check(context.Background(), authID))
+	require.NoError(t, cache.check(context.Background(), authID))
+	require.Equal(t, 1, store.readCount())
+
+	// changes are only read once the status expires or is invalidated
+	store.set(db.GetAuthRow{ID: authID, Restricted: true})
+	require.NoError(t, cache.check(context.Background(), authID))
+	time.Sleep(60 * time.Millisecond)
+	require.ErrorIs(t, cache.check(context.Background(), authID), errAccountRestricted)
+	require.Equal(t, 2, store.readCount())
+
+	store.set(db.GetAuthRow{ID: authID, Deleted: true})
+	cache.invalidate(authID)
+	require.ErrorIs(t, cache.check(context.Background(), authID), errAccountDeleted)
+	require.Equal(t, 3, store.readCount())
+
+	// accounts that do not exist are deleted
+	require.ErrorIs(t, cache.check(context.Background(), uuid.New()), errAccountDeleted)
+}
+
+func TestAccountStatusCacheInvalidateDuringRead(t *testing.T) {
+	authID := uuid.New()
+	store := &fakeAuthStore{
+		auths: map[uuid.UUID]db.GetAuthRow{authID: {ID: authID}},
+		block: make(chan struct{}),
+	}
+	cache := newAccountStatusCache(store, time.Minute)
+
+	checked := make(chan error)
+	go func() {
+		checked <- cache.check(context.Background(), authID)
+	}()
+	require.Eventually(t, func() bool { return store.readCount() == 1 }, time.Second, time.Millisecond)
+
+	// the account is restricted while its previous status is being read
+	store.set(db.GetAuthRow{ID: authID, Restricted: true})
+	cache.invalidate(authID)
+	close(store.block)
+	require.NoError(t, <-checked)
+
+	// the stale status is not cached
+	require.ErrorIs(t, cache.check(context.Background(), authID), errAccountRestricted)
+	require.Equal(t, 2, store.readCount())
+}
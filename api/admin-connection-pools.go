@@ -0,0 +1,15 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) adminListConnectionPools(ctx *gin.Context) {
+	if _, valid := server.validateAdminAuth(ctx); !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved connection pools successfully", server.websocket.PoolStats()))
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gentcod/nlp-to-sql/egress"
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (server *Server) adminListConnectionRules(ctx *gin.Context) {
+	if _, valid := server.validateAdminAuth(ctx); !valid {
+		return
+	}
+
+	rules, err := server.store.ListConnectionRules(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	resp := make([]ConnectionRule, len(rules))
+	for i, rule := range rules {
+		resp[i] = getConnectionRule(rule)
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved connection rules successfully", resp))
+}
+
+func (server *Server) adminCreateConnectionRule(ctx *gin.Context) {
+	var req createConnectionRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	target := strings.TrimSpace(req.Target)
+	if err := egress.ValidateRule(target, req.Port, req.DbType); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	admin, valid := server.validateAdminAuth(ctx)
+	if !valid {
+		return
+	}
+
+	rule, err := server.store.CreateConnectionRule(ctx, db.CreateConnectionRuleParams{
+		ID:          uuid.New(),
+		Target:      target,
+		Port:        sql.NullInt32{Int32: req.Port, Valid: req.Port != 0},
+		DbType:      sql.NullString{String: req.DbType, Valid: req.DbType != ""},
+		Description: req.Description,
+		CreatedBy:   uuid.NullUUID{UUID: admin.ID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID: admin.ID,
+		Action:  db.AuditConnectionRuleCreated,
+		Metadata: map[string]any{
+			"rule_id": rule.ID,
+			"target":  rule.Target,
+			"port":    req.Port,
+			"db_type": req.DbType,
+		},
+	})
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Created connection rule successfully", getConnectionRule(rule)))
+}
+
+func (server *Server) adminDeleteConnectionRule(ctx *gin.Context) {
+	ruleID, err := uuid.Parse(ctx.Param("ruleId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	admin, valid := server.validateAdminAuth(ctx)
+	if !valid {
+		return
+	}
+
+	deleted, err := server.store.DeleteConnectionRule(ctx, ruleID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	if deleted == 0 {
+		msg := "connection rule not found"
+		ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
+		return
+	}
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID: admin.ID,
+		Action:  db.AuditConnectionRuleDeleted,
+		Metadata: map[string]any{
+			"rule_id": ruleID,
+		},
+	})
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Deleted connection rule successfully", ""))
+}
+
+func getConnectionRule(rule db.ConnectionRule) ConnectionRule {
+	resp := ConnectionRule{
+		ID:          rule.ID,
+		Target:      rule.Target,
+		Description: rule.Description,
+		CreatedBy:   rule.CreatedBy,
+		CreatedAt:   rule.CreatedAt,
+	}
+	if rule.Port.Valid {
+		resp.Port = &rule.Port.Int32
+	}
+	if rule.DbType.Valid {
+		resp.DbType = &rule.DbType.String
+	}
+	return resp
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/mailer"
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const defaultAdminInvitationDuration = 72 * time.Hour
+
+func (server *Server) adminCreateInvitation(ctx *gin.Context) {
+	var req createAdminInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	admin, valid := server.validateAdminAuth(ctx)
+	if !valid {
+		return
+	}
+
+	token, hash, err := util.NewSecretToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	duration := server.config.AdminInvitationDuration
+	if duration <= 0 {
+		duration = defaultAdminInvitationDuration
+	}
+
+	invitation, err := server.store.CreateAdminInvitation(ctx, db.CreateAdminInvitationParams{
+		ID:        uuid.New(),
+		Email:     req.Email,
+		TokenHash: hash,
+		InvitedBy: uuid.NullUUID{UUID: admin.ID, Valid: true},
+		ExpiresAt: time.Now().Add(duration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	if err := server.sendAdminInvitation(ctx, admin.Email, invitation, token); err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID: admin.ID,
+		Action:  db.AuditAdminInvited,
+		Metadata: map[string]any{
+			"invitation_id": invitation.ID,
+			"email":         invitation.Email,
+		},
+	})
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Invitation sent successfully", getAdminInvitation(invitation)))
+}
+
+func (server *Server) adminListInvitations(ctx *gin.Context) {
+	if _, valid := server.validateAdminAuth(ctx); !valid {
+		return
+	}
+
+	invitations, err := server.store.ListPendingAdminInvitations(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	resp := make([]AdminInvitation, len(invitations))
+	for i, invitation := range invitations {
+		resp[i] = getAdminInvitation(invitation)
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved pending invitations successfully", resp))
+}
+
+func (server *Server) adminRevokeInvitation(ctx *gin.Context) {
+	invitationID, err := uuid.Parse(ctx.Param("invitationId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	admin, valid := server.validateAdminAuth(ctx)
+	if !valid {
+		return
+	}
+
+	revoked, err := server.store.RevokeAdminInvitation(ctx, invitationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	if revoked == 0 {
+		msg := "invitation not found or no longer pending"
+		ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
+		return
+	}
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID: admin.ID,
+		Action:  db.AuditAdminInviteRevoked,
+		Metadata: map[string]any{
+			"invitation_id": invitationID,
+		},
+	})
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Invitation revoked successfully", ""))
+}
+
+// sendAdminInvitation emails the admin signup link of the invitation to the invited email.
+func (server *Server) sendAdminInvitation(ctx context.Context, inviter string, invitation db.AdminInvitation, token string) error {
+	link := fmt.Sprintf("%s/admin/signup?token=%s", server.config.AppUrl, url.QueryEscape(token))
+
+	return server.mailer.Send(ctx, mailer.Email{
+		To:      invitation.Email,
+		Subject: "You have been invited to become an admin",
+		Body: fmt.Sprintf("%s has invited you to create an admin account, sign up with this email address by opening the link below, it expires on %v.\n\n%s\n",
+			inviter, invitation.ExpiresAt.UTC().Format(time.RFC1123), link),
+	})
+}
+
+func getAdminInvitation(invitation db.AdminInvitation) AdminInvitation {
+	return AdminInvitation{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		InvitedBy: invitation.InvitedBy,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (server *Server) adminGetUserLimits(ctx *gin.Context) {
+	userId, _, valid := server.getLimitsUser(ctx)
+	if !valid {
+		return
+	}
+
+	server.respondUserLimits(ctx, userId, "Retrieved user limits successfully")
+}
+
+func (server *Server) adminSetUserLimits(ctx *gin.Context) {
+	var req setUserLimitsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	userId, admin, valid := server.getLimitsUser(ctx)
+	if !valid {
+		return
+	}
+
+	arg := db.SetUserLimitsParams{
+		AuthID: userId,
+	}
+	if req.RequestsPerMinute != nil {
+		arg.RequestsPerMinute = sql.NullInt32{Int32: *req.RequestsPerMinute, Valid: true}
+	}
+	if req.Burst != nil {
+		arg.Burst = sql.NullInt32{Int32: *req.Burst, Valid: true}
+	}
+	if req.MonthlyTokenQuota != nil {
+		arg.MonthlyTokenQuota = sql.NullInt64{Int64: *req.MonthlyTokenQuota, Valid: true}
+	}
+
+	_, err := server.store.SetUserLimits(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+	server.limiter.Reset(userId)
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  admin.ID,
+		TargetID: userId,
+		Action:   db.AuditUserLimitsUpdated,
+		Metadata: map[string]any{
+			"requests_per_minute": req.RequestsPerMinute,
+			"burst":               req.Burst,
+			"monthly_token_quota": req.MonthlyTokenQuota,
+		},
+	})
+
+	server.respondUserLimits(ctx, userId, "Updated user limits successfully")
+}
+
+// getLimitsUser parses the user id and ensures the request is made by an admin for an existing account.
+func (server *Server) getLimitsUser(ctx *gin.Context) (uuid.UUID, db.GetAuthRow, bool) {
+	userId, err := uuid.Parse(ctx.Param("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(errors.New("invalid user id")))
+		return userId, db.GetAuthRow{}, false
+	}
+
+	admin, valid := server.validateAdminAuth(ctx)
+	if !valid {
+		return userId, admin, false
+	}
+
+	_, err = server.store.GetAuth(ctx, userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			msg := "user not found"
+			ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
+			return userId, admin, false
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return userId, admin, false
+	}
+
+	return userId, admin, true
+}
+
+// respondUserLimits responds with the effective limits and the LLM usage of the user for the current month.
+func (server *Server) respondUserLimits(ctx *gin.Context, userId uuid.UUID, msg string) {
+	limits, err := server.limiter.Limits(ctx, userId)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	usage, err := server.limiter.Usage(ctx, userId)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse(msg, userLimitsResponse{
+		Limits: limits,
+		Usage:  usage,
+	}))
+}
@@ -0,0 +1,426 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/token"
+	"github.com/gentcod/nlp-to-sql/totp"
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	totpIssuer = "nlp-to-sql"
+	// totpSecretPurpose separates the key that encrypts stored TOTP secrets from the token key it is derived from.
+	totpSecretPurpose = "totp-secret"
+
+	mfaChallengeDuration = 5 * time.Minute
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+	recoveryCodeChars  = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var errInvalidMFACode = errors.New("invalid authentication code")
+
+// respondMFAChallenge responds with a short-lived challenge token instead of an access token,
+// the token is exchanged for an access token with a TOTP or recovery code at /admin/login/mfa.
+func (server *Server) respondMFAChallenge(ctx *gin.Context, authID uuid.UUID) {
+	challenge, hash, err := util.NewSecretToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	authToken, err := server.store.IssueAuthTokenTx(ctx, db.IssueAuthTokenTxParams{
+		AuthID:    authID,
+		Purpose:   db.AuthTokenMFAChallenge,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(mfaChallengeDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	resp := mfaChallengeResponse{
+		MFARequired:       true,
+		MFAToken:          challenge,
+		MFATokenExpiresAt: authToken.ExpiresAt,
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Two-factor authentication required", resp))
+}
+
+func (server *Server) loginAdminMFA(ctx *gin.Context) {
+	var req mfaLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	if !server.checkIPLockout(ctx) {
+		return
+	}
+
+	// challenges are single-use, an invalid code requires logging in with the password again
+	challenge, err := server.store.UseAuthToken(ctx, db.UseAuthTokenParams{
+		TokenHash: util.HashSecretToken(req.MFAToken),
+		Purpose:   db.AuthTokenMFAChallenge,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			server.recordLoginFailure(ctx, uuid.Nil)
+			ctx.JSON(http.StatusUnauthorized, apiErrorResponse(db.ErrInvalidAuthToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	auth, err := server.getFullAuth(ctx, challenge.AuthID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	if auth.Restricted || auth.Deleted {
+		msg := "Account has been restricted."
+		ctx.JSON(http.StatusUnauthorized, apiErrorResponse(errors.New(msg)))
+		return
+	}
+
+	// failed codes count towards the lockout of the account like failed passwords
+	if !checkAccountLockout(ctx, auth) {
+		return
+	}
+
+	valid, err := server.verifyMFACode(ctx, auth.ID, req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	if !valid {
+		server.recordLoginFailure(ctx, auth.ID)
+		ctx.JSON(http.StatusUnauthorized, apiErrorResponse(errInvalidMFACode))
+		server.recordLoginFailedEvent(ctx, auth.ID, auth.Email, db.AuditAdminLoginFailed)
+		return
+	}
+
+	server.respondAdminLogin(ctx, auth.ID, auth.Email, auth.PasswordChangedAt)
+}
+
+func (server *Server) getAdminMFA(ctx *gin.Context) {
+	auth, valid := server.validateAdminAuth(ctx)
+	if !valid {
+		return
+	}
+
+	enabled, err := server.mfaEnabled(ctx, auth.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	resp := mfaStatusResponse{
+		Enabled:  enabled,
+		Required: server.adminMFARequired(),
+	}
+
+	if enabled {
+		resp.RecoveryCodesRemaining, err = server.store.CountRecoveryCodes(ctx, auth.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved two-factor authentication status successfully", resp))
+}
+
+func (server *Server) enrolAdminMFA(ctx *gin.Context) {
+	auth, valid := server.validateAdminAuth(ctx)
+	if !valid {
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	encrypted, err := util.EncryptSecret(server.config.TokenSymmetricKey, totpSecretPurpose, secret)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	// enrolling again before verifying replaces the pending secret
+	_, err = server.store.CreateTOTPCredential(ctx, db.CreateTOTPCredentialParams{
+		AuthID: auth.ID,
+		Secret: encrypted,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			msg := "two-factor authentication is already enabled"
+			ctx.JSON(http.StatusConflict, apiErrorResponse(errors.New(msg)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	resp := mfaEnrolResponse{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(totpIssuer, auth.Email, secret),
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Add the secret to an authenticator app and verify a code to enable two-factor authentication", resp))
+}
+
+func (server *Server) verifyAdminMFA(ctx *gin.Context) {
+	var req mfaCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	auth, valid := server.validateAdminAuth(ctx)
+	if !valid {
+		return
+	}
+
+	credential, err := server.store.GetTOTPCredential(ctx, auth.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+	if err == sql.ErrNoRows || credential.ConfirmedAt.Valid {
+		ctx.JSON(http.StatusConflict, apiErrorResponse(db.ErrTOTPNotPending))
+		return
+	}
+
+	secret, err := util.DecryptSecret(server.config.TokenSymmetricKey, totpSecretPurpose, credential.Secret)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	step, ok := totp.Validate(secret, normalizeMFACode(req.Code), time.Now())
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(errInvalidMFACode))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	err = server.store.EnableTOTPTx(ctx, db.EnableTOTPTxParams{
+		AuthID:             auth.ID,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrTOTPNotPending) {
+			ctx.JSON(http.StatusConflict, apiErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  auth.ID,
+		TargetID: auth.ID,
+		Action:   db.AuditAdminMFAEnabled,
+	})
+
+	resp := mfaRecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Two-factor authentication enabled, store the recovery codes somewhere safe as they are only shown once", resp))
+}
+
+func (server *Server) disableAdminMFA(ctx *gin.Context) {
+	var req disableMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	auth, err := server.getFullAuth(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	if auth.Role.RoleType != db.RoleTypeAdmin {
+		msg := "Invalid route."
+		ctx.JSON(http.StatusUnauthorized, apiErrorResponse(errors.New(msg)))
+		return
+	}
+
+	if err := util.CheckPassword(req.Password, auth.HarshedPassword); err != nil {
+		ctx.JSON(http.StatusUnauthorized, apiErrorResponse(err))
+		return
+	}
+
+	valid, err := server.verifyMFACode(ctx, auth.ID, req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	if !valid {
+		ctx.JSON(http.StatusUnauthorized, apiErrorResponse(errInvalidMFACode))
+		return
+	}
+
+	err = server.store.DisableTOTPTx(ctx, auth.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  auth.ID,
+		TargetID: auth.ID,
+		Action:   db.AuditAdminMFADisabled,
+	})
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Two-factor authentication disabled", ""))
+}
+
+// requireAdminMFA creates a gin middleware that rejects admins that have not enabled two-factor authentication
+// when ADMIN_MFA_REQUIRED is set, they can only access the endpoints to enable it.
+func (server *Server) requireAdminMFA() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !server.adminMFARequired() {
+			ctx.Next()
+			return
+		}
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		enabled, err := server.mfaEnabled(ctx, authPayload.UserID)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, apiErrorResponse(err))
+			return
+		}
+
+		if !enabled {
+			msg := "two-factor authentication must be enabled, enrol at /api/v1/admin/mfa/enrol"
+			ctx.AbortWithStatusJSON(http.StatusForbidden, apiErrorResponse(errors.New(msg)))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func (server *Server) adminMFARequired() bool {
+	return server.config.AdminMFARequired == "true"
+}
+
+// mfaEnabled reports whether the account has verified its TOTP enrolment.
+func (server *Server) mfaEnabled(ctx context.Context, authID uuid.UUID) (bool, error) {
+	credential, err := server.store.GetTOTPCredential(ctx, authID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return credential.ConfirmedAt.Valid, nil
+}
+
+// verifyMFACode checks a TOTP code or a recovery code of the account, both can only be used once.
+func (server *Server) verifyMFACode(ctx context.Context, authID uuid.UUID, code string) (bool, error) {
+	credential, err := server.store.GetTOTPCredential(ctx, authID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if !credential.ConfirmedAt.Valid {
+		return false, nil
+	}
+
+	code = normalizeMFACode(code)
+	if len(code) == totp.Digits {
+		secret, err := util.DecryptSecret(server.config.TokenSymmetricKey, totpSecretPurpose, credential.Secret)
+		if err != nil {
+			return false, err
+		}
+
+		step, ok := totp.Validate(secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		used, err := server.store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+			AuthID:       authID,
+			LastUsedStep: step,
+		})
+		return used == 1, err
+	}
+
+	used, err := server.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		AuthID:   authID,
+		CodeHash: util.HashSecretToken(code),
+	})
+	return used == 1, err
+}
+
+// getFullAuth returns the auth record of the account including its password hash and login state.
+func (server *Server) getFullAuth(ctx context.Context, authID uuid.UUID) (db.Auth, error) {
+	auth, err := server.store.GetAuth(ctx, authID)
+	if err != nil {
+		return db.Auth{}, err
+	}
+
+	return server.store.ValidateAuth(ctx, auth.Email)
+}
+
+// normalizeMFACode removes the separators users may type or copy with a code.
+func normalizeMFACode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// newRecoveryCodes returns random recovery codes formatted for display and the hashes of their normalized form.
+func newRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		// len(recoveryCodeChars) divides 256 so that every character is equally likely
+		for j := range b {
+			b[j] = recoveryCodeChars[int(b[j])%len(recoveryCodeChars)]
+		}
+
+		code := string(b)
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		hashes = append(hashes, util.HashSecretToken(code))
+	}
+
+	return codes, hashes, nil
+}
@@ -1,33 +1,52 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"net/http"
-	"time"
 
 	db "github.com/gentcod/nlp-to-sql/internal/database"
-	"github.com/gentcod/nlp-to-sql/token"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 func (server *Server) adminRestrictUser(ctx *gin.Context) {
-	userId := ctx.Param("userId")
+	server.adminSanctionUser(ctx, server.store.RestrictUserTx, "Restricted user successfully")
+}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	_, err := server.store.GetAuth(ctx, authPayload.UserID)
+func (server *Server) adminDeleteUser(ctx *gin.Context) {
+	server.adminSanctionUser(ctx, server.store.DeleteUserByAdminTx, "Deleted user successfully.")
+}
+
+// adminSanctionUser restricts or deletes a user, recording the admin and the optional reason,
+// and closes the sessions of the user.
+func (server *Server) adminSanctionUser(
+	ctx *gin.Context,
+	sanction func(context.Context, db.ModerateUserTxParams) (db.ModerationAction, error),
+	successMsg string,
+) {
+	userId, err := uuid.Parse(ctx.Param("userId"))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			msg := "admin not found"
-			ctx.JSON(http.StatusUnauthorized, apiErrorResponse(errors.New(msg)))
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(errors.New("invalid user id")))
+		return
+	}
+
+	// the reason is optional when restricting or deleting a user
+	var req restrictUserRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+	}
+
+	admin, valid := server.validateAdminAuth(ctx)
+	if !valid {
 		return
 	}
 
-	_, err = server.store.GetUser(ctx, uuid.MustParse(userId))
+	_, err = server.store.GetUser(ctx, userId)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			msg := "user not found"
@@ -38,9 +57,11 @@ func (server *Server) adminRestrictUser(ctx *gin.Context) {
 		return
 	}
 
-	err = server.store.RestrictAuth(ctx, db.RestrictAuthParams{
-		ID:        uuid.MustParse(userId),
-		UpdatedAt: time.Now(),
+	_, err = sanction(ctx, db.ModerateUserTxParams{
+		AuthID:    userId,
+		AdminID:   admin.ID,
+		Reason:    req.Reason,
+		IpAddress: ctx.ClientIP(),
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -52,45 +73,58 @@ func (server *Server) adminRestrictUser(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, apiServerResponse("Restricted user successfully", ""))
+	server.revokeAccountAccess(userId)
+
+	ctx.JSON(http.StatusOK, apiServerResponse(successMsg, ""))
 }
 
-func (server *Server) adminDeleteUser(ctx *gin.Context) {
-	userId := ctx.Param("userId")
+func (server *Server) adminUnrestrictUser(ctx *gin.Context) {
+	server.adminModerateUser(ctx, server.store.UnrestrictUserTx, "Lifted user restriction successfully")
+}
+
+func (server *Server) adminRestoreUser(ctx *gin.Context) {
+	server.adminModerateUser(ctx, server.store.RestoreUserTx, "Restored user successfully")
+}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	_, err := server.store.GetAuth(ctx, authPayload.UserID)
+// adminModerateUser reverses a moderation action on a user, recording the admin and the reason.
+func (server *Server) adminModerateUser(
+	ctx *gin.Context,
+	moderate func(context.Context, db.ModerateUserTxParams) (db.ModerationAction, error),
+	successMsg string,
+) {
+	userId, err := uuid.Parse(ctx.Param("userId"))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			msg := "admin not found"
-			ctx.JSON(http.StatusUnauthorized, apiErrorResponse(errors.New(msg)))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(errors.New("invalid user id")))
 		return
 	}
 
-	user, err := server.store.GetUser(ctx, uuid.MustParse(userId))
-	if err != nil {
-		if err == sql.ErrNoRows {
-			msg := "user account not found"
-			ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+	var req moderateUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
 		return
 	}
 
-	err = server.store.DeleteUserTx(ctx, uuid.MustParse(userId), user.ID)
+	admin, valid := server.validateAdminAuth(ctx)
+	if !valid {
+		return
+	}
+
+	action, err := moderate(ctx, db.ModerateUserTxParams{
+		AuthID:    userId,
+		AdminID:   admin.ID,
+		Reason:    req.Reason,
+		IpAddress: ctx.ClientIP(),
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			msg := "user authentication not found"
-			ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
+		if errors.Is(err, db.ErrNotRestricted) || errors.Is(err, db.ErrNotRestorable) {
+			ctx.JSON(http.StatusConflict, apiErrorResponse(err))
 			return
 		}
 		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, apiServerResponse("Deleted user successfully.", ""))
+	server.accountStatuses.invalidate(userId)
+
+	ctx.JSON(http.StatusOK, apiServerResponse(successMsg, action))
 }
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// moderationHistoryLimit is the number of recent moderation actions returned with a user's details.
+const moderationHistoryLimit = 20
+
+func (server *Server) adminListUsers(ctx *gin.Context) {
+	var req listUsersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	if _, valid := server.validateAdminAuth(ctx); !valid {
+		return
+	}
+
+	filter := db.CountUsersParams{
+		Restricted: nullBool(req.Restricted),
+		Deleted:    nullBool(req.Deleted),
+		Role: db.NullRoleType{
+			RoleType: db.RoleType(req.Role),
+			Valid:    req.Role != "",
+		},
+		CreatedFrom: sql.NullTime{
+			Time:  req.CreatedFrom,
+			Valid: !req.CreatedFrom.IsZero(),
+		},
+		CreatedTo: sql.NullTime{
+			Time:  req.CreatedTo,
+			Valid: !req.CreatedTo.IsZero(),
+		},
+		Search: sql.NullString{
+			String: strings.TrimSpace(req.Search),
+			Valid:  strings.TrimSpace(req.Search) != "",
+		},
+	}
+
+	total, err := server.store.CountUsers(ctx, filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	rows, err := server.store.ListUsers(ctx, db.ListUsersParams{
+		Restricted:  filter.Restricted,
+		Deleted:     filter.Deleted,
+		Role:        filter.Role,
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+		Search:      filter.Search,
+		PageLimit:   req.PageSize,
+		PageOffset:  (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	users := make([]UserDetail, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, UserDetail{
+			ID:         row.ID,
+			Username:   row.Username,
+			FullName:   row.FullName,
+			Email:      row.Email,
+			Role:       string(row.Role.RoleType),
+			Restricted: row.Restricted,
+			Deleted:    row.Deleted,
+			CreatedAt:  row.CreatedAt,
+			UpdatedAt:  row.UpdatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved users successfully", listUsersResponse{
+		Users:    users,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Total:    total,
+	}))
+}
+
+func (server *Server) adminGetUser(ctx *gin.Context) {
+	userId, err := uuid.Parse(ctx.Param("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(errors.New("invalid user id")))
+		return
+	}
+
+	if _, valid := server.validateAdminAuth(ctx); !valid {
+		return
+	}
+
+	user, err := server.store.GetUserDetail(ctx, userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			msg := "user not found"
+			ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	detail := getUserDetail(user)
+	detail.ModerationHistory, err = server.store.ListModerationActions(ctx, db.ListModerationActionsParams{
+		AuthID: user.ID,
+		Limit:  moderationHistoryLimit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved user successfully", detail))
+}
+
+// requireAdmin creates a gin middleware that rejects accounts that are not admins from the admin endpoints.
+func (server *Server) requireAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, valid := server.validateAdminAuth(ctx); !valid {
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// validateAdminAuth ensures the authenticated account exists and is an admin.
+func (server *Server) validateAdminAuth(ctx *gin.Context) (db.GetAuthRow, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	auth, err := server.store.GetAuth(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			msg := "admin not found"
+			ctx.JSON(http.StatusUnauthorized, apiErrorResponse(errors.New(msg)))
+			return auth, false
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return auth, false
+	}
+
+	if auth.Role.RoleType != db.RoleTypeAdmin {
+		msg := "Invalid route."
+		ctx.JSON(http.StatusUnauthorized, apiErrorResponse(errors.New(msg)))
+		return auth, false
+	}
+
+	return auth, true
+}
+
+func getUserDetail(user db.GetUserDetailRow) UserDetail {
+	detail := UserDetail{
+		ID:             user.ID,
+		Username:       user.Username,
+		FullName:       user.FullName,
+		Email:          user.Email,
+		Role:           string(user.Role.RoleType),
+		Restricted:     user.Restricted,
+		Deleted:        user.Deleted,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+		FailedAttempts: user.FailedAttempts,
+	}
+	if !user.PasswordChangedAt.IsZero() {
+		detail.PasswordChangedAt = &user.PasswordChangedAt
+	}
+	if user.LastLoginAt.Valid {
+		detail.LastLoginAt = &user.LastLoginAt.Time
+	}
+	if user.LockedUntil.Valid && user.LockedUntil.Time.After(time.Now()) {
+		detail.Locked = true
+		detail.LockedUntil = &user.LockedUntil.Time
+	}
+
+	return detail
+}
+
+func nullBool(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *b, Valid: true}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	adminID, userID := uuid.New(), uuid.New()
+	store := &fakeAuthStore{auths: map[uuid.UUID]db.GetAuthRow{
+		adminID: {ID: adminID, Role: db.NullRoleType{RoleType: db.RoleTypeAdmin, Valid: true}},
+		userID:  {ID: userID, Role: db.NullRoleType{RoleType: db.RoleTypeUser, Valid: true}},
+	}}
+	server := &Server{store: store}
+
+	request := func(authID uuid.UUID) int {
+		router := gin.New()
+		router.Use(func(ctx *gin.Context) {
+			ctx.Set(authorizationPayloadKey, &token.Payload{UserID: authID})
+		}, server.requireAdmin(), server.requireAdminMFA())
+		router.GET("/admin", func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin", nil))
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusOK, request(adminID))
+
+	// users are rejected even when admins are not required to enable two-factor authentication
+	require.Equal(t, http.StatusUnauthorized, request(userID))
+	require.Equal(t, http.StatusUnauthorized, request(uuid.New()))
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto/subtle"
 	"database/sql"
 	"errors"
 	"net/http"
@@ -15,7 +16,7 @@ import (
 )
 
 func (server *Server) createAdminUser(ctx *gin.Context) {
-	var req createUserRequest
+	var req createAdminRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
 		return
@@ -40,8 +41,26 @@ func (server *Server) createAdminUser(ctx *gin.Context) {
 		},
 	}
 
-	adminTx, err := server.store.CreateAdminTx(ctx, arg)
+	// admins can only sign up with an invitation from another admin, or the bootstrap token while no admin exists
+	var adminTx db.AdminTxResult
+	metadata := map[string]any{}
+	if server.validBootstrapToken(req.Token) {
+		adminTx, err = server.store.CreateBootstrapAdminTx(ctx, arg)
+		metadata["method"] = "bootstrap"
+	} else {
+		var invitation db.AdminInvitation
+		adminTx, invitation, err = server.store.CreateInvitedAdminTx(ctx, db.CreateInvitedAdminTxParams{
+			CreateAdminTxParams: arg,
+			TokenHash:           util.HashSecretToken(req.Token),
+		})
+		metadata["method"] = "invitation"
+		metadata["invitation_id"] = invitation.ID
+	}
 	if err != nil {
+		if errors.Is(err, db.ErrAdminExists) || errors.Is(err, db.ErrInvalidInvitation) {
+			ctx.JSON(http.StatusForbidden, apiErrorResponse(err))
+			return
+		}
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
 			case "unique_violation":
@@ -54,11 +73,24 @@ func (server *Server) createAdminUser(ctx *gin.Context) {
 		return
 	}
 
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  adminTx.Auth.ID,
+		TargetID: adminTx.Auth.ID,
+		Action:   db.AuditAdminSignup,
+		Metadata: metadata,
+	})
+
 	profile := getAminrProfile(adminTx)
 
 	ctx.JSON(http.StatusOK, apiServerResponse("admin account created sucessfully", profile))
 }
 
+// validBootstrapToken reports whether the token is the ADMIN_BOOTSTRAP_TOKEN, which is disabled when not set.
+func (server *Server) validBootstrapToken(token string) bool {
+	bootstrapToken := server.config.AdminBootstrapToken
+	return bootstrapToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(bootstrapToken)) == 1
+}
+
 func (server *Server) updateAdminUser(ctx *gin.Context) {
 	var req updateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -124,6 +156,8 @@ func (server *Server) updateAdminUser(ctx *gin.Context) {
 		return
 	}
 
+	server.recordUpdateAuditEvents(ctx, auth.ID, req, db.AuditAdminUpdated, db.AuditAdminPasswordChanged)
+
 	profile := getAminrProfile(updateTx)
 	ctx.JSON(http.StatusOK, apiServerResponse("admin account updated sucessfully", profile))
 }
@@ -137,16 +171,36 @@ func (server *Server) loginAdminUser(ctx *gin.Context) {
 
 	auth, valid := server.validateAdminUser(ctx, req.Email, req.Password)
 	if !valid {
+		server.recordLoginFailedEvent(ctx, auth.ID, req.Email, db.AuditAdminLoginFailed)
+		return
+	}
+
+	enabled, err := server.mfaEnabled(ctx, auth.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
 		return
 	}
 
-	user, err := server.store.GetAdmin(ctx, auth.ID)
+	if enabled {
+		server.respondMFAChallenge(ctx, auth.ID)
+		return
+	}
+
+	server.respondAdminLogin(ctx, auth.ID, auth.Email, auth.PasswordChangedAt)
+}
+
+// respondAdminLogin responds with an access token for the admin account once it has been authenticated,
+// with its password and second factor when two-factor authentication is enabled, and resets its failed logins.
+func (server *Server) respondAdminLogin(ctx *gin.Context, authID uuid.UUID, email string, passwordChangedAt time.Time) {
+	user, err := server.store.GetAdmin(ctx, authID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
 		return
 	}
 
-	accessToken, accessPayload, err := server.adminTokenGenerator.CreateToken(user.Username, auth.ID, server.config.AccessTokenDuration)
+	server.recordLogin(ctx, authID)
+
+	accessToken, accessPayload, err := server.adminTokenGenerator.CreateToken(user.Username, authID, server.config.AccessTokenDuration)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
 		return
@@ -158,19 +212,30 @@ func (server *Server) loginAdminUser(ctx *gin.Context) {
 		User: UserProfile{
 			Username:          user.Username,
 			FullName:          user.FullName,
-			Email:             auth.Email,
+			Email:             email,
 			CreatedAt:         user.CreatedAt,
-			PasswordChangedAt: auth.PasswordChangedAt,
+			PasswordChangedAt: passwordChangedAt,
 		},
 	}
 
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  authID,
+		TargetID: authID,
+		Action:   db.AuditAdminLogin,
+	})
+
 	ctx.JSON(http.StatusOK, apiServerResponse("Account login sucessfully", resp))
 }
 
 func (server *Server) validateAdminUser(ctx *gin.Context, email string, password string) (db.Auth, bool) {
+	if !server.checkIPLockout(ctx) {
+		return db.Auth{}, false
+	}
+
 	auth, err := server.store.ValidateAuth(ctx, email)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			server.recordLoginFailure(ctx, uuid.Nil)
 			msg := "user not found"
 			ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
 			return auth, false
@@ -197,8 +262,13 @@ func (server *Server) validateAdminUser(ctx *gin.Context, email string, password
 		return auth, false
 	}
 
+	if !checkAccountLockout(ctx, auth) {
+		return auth, false
+	}
+
 	err = util.CheckPassword(password, auth.HarshedPassword)
 	if err != nil {
+		server.recordLoginFailure(ctx, auth.ID)
 		ctx.JSON(http.StatusUnauthorized, apiErrorResponse(err))
 		return auth, false
 	}
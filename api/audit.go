@@ -0,0 +1,123 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// recordAuditEvent stores an audit event for the request.
+// Failures are logged rather than failing requests that have already been processed.
+func (server *Server) recordAuditEvent(ctx *gin.Context, arg db.AuditEventParams) {
+	arg.IpAddress = ctx.ClientIP()
+
+	_, err := server.store.RecordAuditEvent(ctx, arg)
+	if err != nil {
+		log.Printf("Error recording audit event %v: %v", arg.Action, err)
+	}
+}
+
+// recordLoginFailedEvent records a failed login, authID is uuid.Nil when the email is not registered.
+func (server *Server) recordLoginFailedEvent(ctx *gin.Context, authID uuid.UUID, email, action string) {
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		TargetID: authID,
+		Action:   action,
+		Metadata: map[string]any{
+			"email":  email,
+			"status": ctx.Writer.Status(),
+		},
+	})
+}
+
+// recordUpdateAuditEvents records an account update with the updated fields,
+// and a separate password change event when the password was updated.
+func (server *Server) recordUpdateAuditEvents(ctx *gin.Context, authID uuid.UUID, req updateUserRequest, updatedAction, passwordAction string) {
+	var fields []string
+	if req.Email != "" {
+		fields = append(fields, "email")
+	}
+	if req.Username != "" {
+		fields = append(fields, "username")
+	}
+	if req.FullName != "" {
+		fields = append(fields, "full_name")
+	}
+
+	if len(fields) > 0 {
+		server.recordAuditEvent(ctx, db.AuditEventParams{
+			ActorID:  authID,
+			TargetID: authID,
+			Action:   updatedAction,
+			Metadata: map[string]any{
+				"fields": fields,
+			},
+		})
+	}
+
+	if req.Password != "" {
+		server.recordAuditEvent(ctx, db.AuditEventParams{
+			ActorID:  authID,
+			TargetID: authID,
+			Action:   passwordAction,
+		})
+	}
+}
+
+func (server *Server) adminListAuditEvents(ctx *gin.Context) {
+	var req listAuditEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	if _, valid := server.validateAdminAuth(ctx); !valid {
+		return
+	}
+
+	events, err := server.store.ListAuditEvents(ctx, db.ListAuditEventsParams{
+		Cursor: sql.NullInt64{
+			Int64: req.Cursor,
+			Valid: req.Cursor > 0,
+		},
+		ActorID:  nullUUID(req.ActorID),
+		TargetID: nullUUID(req.TargetID),
+		Action: sql.NullString{
+			String: req.Action,
+			Valid:  req.Action != "",
+		},
+		CreatedFrom: sql.NullTime{
+			Time:  req.CreatedFrom,
+			Valid: !req.CreatedFrom.IsZero(),
+		},
+		CreatedTo: sql.NullTime{
+			Time:  req.CreatedTo,
+			Valid: !req.CreatedTo.IsZero(),
+		},
+		PageLimit: req.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	resp := listAuditEventsResponse{
+		Events: events,
+	}
+	if len(events) == int(req.Limit) {
+		resp.NextCursor = events[len(events)-1].ID
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved audit events successfully", resp))
+}
+
+func nullUUID(id string) uuid.NullUUID {
+	parsed, err := uuid.Parse(id)
+	return uuid.NullUUID{
+		UUID:  parsed,
+		Valid: err == nil,
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/mailer"
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultEmailVerificationTokenDuration = 24 * time.Hour
+	defaultPasswordResetTokenDuration     = time.Hour
+)
+
+func (server *Server) requestEmailVerification(ctx *gin.Context) {
+	var req emailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	auth, err := server.store.ValidateAuth(ctx, req.Email)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	// the response is the same whether or not the account exists so that emails cannot be enumerated
+	if err == nil && !auth.Deleted && !auth.EmailVerifiedAt.Valid {
+		if err := server.sendEmailVerification(ctx, auth.ID, auth.Email); err != nil {
+			ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("If the account exists and is not verified, a verification email has been sent", ""))
+}
+
+func (server *Server) confirmEmailVerification(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	token, err := server.store.VerifyEmailTx(ctx, util.HashSecretToken(req.Token))
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidAuthToken) {
+			ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  token.AuthID,
+		TargetID: token.AuthID,
+		Action:   db.AuditUserEmailVerified,
+	})
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Email verified successfully", ""))
+}
+
+func (server *Server) requestPasswordReset(ctx *gin.Context) {
+	var req emailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	auth, err := server.store.ValidateAuth(ctx, req.Email)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	// the response is the same whether or not the account exists so that emails cannot be enumerated
+	if err == nil && !auth.Deleted && !auth.Restricted {
+		if err := server.sendPasswordReset(ctx, auth.ID, auth.Email); err != nil {
+			ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+			return
+		}
+
+		server.recordAuditEvent(ctx, db.AuditEventParams{
+			TargetID: auth.ID,
+			Action:   db.AuditUserPasswordResetRequested,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("If the account exists, a password reset email has been sent", ""))
+}
+
+func (server *Server) confirmPasswordReset(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	harshedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	token, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash:       util.HashSecretToken(req.Token),
+		HarshedPassword: harshedPassword,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidAuthToken) {
+			ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  token.AuthID,
+		TargetID: token.AuthID,
+		Action:   db.AuditUserPasswordReset,
+	})
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Password reset successfully", ""))
+}
+
+// sendEmailVerification issues an email verification token and emails a verification link to the account.
+func (server *Server) sendEmailVerification(ctx context.Context, authID uuid.UUID, email string) error {
+	duration := server.config.EmailVerificationTokenDuration
+	if duration <= 0 {
+		duration = defaultEmailVerificationTokenDuration
+	}
+
+	link, err := server.issueAuthToken(ctx, authID, db.AuthTokenEmailVerification, duration, "/verify-email")
+	if err != nil {
+		return err
+	}
+
+	return server.mailer.Send(ctx, mailer.Email{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Welcome!\n\nVerify your email address by opening the link below, it expires in %v.\n\n%s\n",
+			duration, link),
+	})
+}
+
+// sendPasswordReset issues a password reset token and emails a password reset link to the account.
+func (server *Server) sendPasswordReset(ctx context.Context, authID uuid.UUID, email string) error {
+	duration := server.config.PasswordResetTokenDuration
+	if duration <= 0 {
+		duration = defaultPasswordResetTokenDuration
+	}
+
+	link, err := server.issueAuthToken(ctx, authID, db.AuthTokenPasswordReset, duration, "/reset-password")
+	if err != nil {
+		return err
+	}
+
+	return server.mailer.Send(ctx, mailer.Email{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("A password reset was requested for your account, reset your password by opening the link below, it expires in %v.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+			duration, link),
+	})
+}
+
+// issueAuthToken stores the hash of a new token and returns the link of the app page that uses the token.
+func (server *Server) issueAuthToken(ctx context.Context, authID uuid.UUID, purpose string, duration time.Duration, path string) (string, error) {
+	token, hash, err := util.NewSecretToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = server.store.IssueAuthTokenTx(ctx, db.IssueAuthTokenTxParams{
+		AuthID:    authID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(duration),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%s?token=%s", server.config.AppUrl, path, url.QueryEscape(token)), nil
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gentcod/nlp-to-sql/chat/protocol"
+	"github.com/gentcod/nlp-to-sql/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// connectChat upgrades the request to a chat WebSocket connection of the authenticated user.
+func (server *Server) connectChat(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	server.websocket.HandleConnection(ctx, authPayload.UserID)
+}
+
+// askChat answers a question about a workspace connection, for clients that cannot hold a WebSocket connection.
+func (server *Server) askChat(ctx *gin.Context) {
+	var req askChatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.ask(ctx, authPayload.UserID, req, nil)
+	if err != nil {
+		chatErrorResponse(ctx, err)
+		return
+	}
+
+	message := "Answered question successfully"
+	if req.Mode == protocol.ModeSQLOnly {
+		message = "Generated query successfully"
+	}
+	ctx.JSON(http.StatusOK, apiServerResponse(message, result))
+}
+
+// ask answers a question, or only generates and validates its query in the sql_only mode.
+func (server *Server) ask(ctx context.Context, userID uuid.UUID, req askChatRequest, progress func(stage string)) (any, error) {
+	dbData := protocol.StartPayload{
+		ConnectionID: req.ConnectionID,
+		AnswerMode:   req.AnswerMode,
+	}
+	if req.Mode == protocol.ModeSQLOnly {
+		return server.websocket.AskSQL(ctx, userID, dbData, req.Question, progress)
+	}
+	return server.websocket.Ask(ctx, userID, dbData, req.Question, progress)
+}
+
+// askEvent is a server-sent event of a streamed answer.
+type askEvent struct {
+	name string
+	data any
+}
+
+// streamAskChat answers a question about a workspace connection with server-sent events: a progress event
+// for every stage of the answer, followed by a result event with the answer, or the query in the sql_only mode,
+// or an error event.
+func (server *Server) streamAskChat(ctx *gin.Context) {
+	var req askChatRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	requestCtx := ctx.Request.Context()
+
+	// every stage and the result fit in the buffer, so the answer never waits for a client that went away
+	events := make(chan askEvent, 4)
+	go func() {
+		defer close(events)
+
+		result, err := server.ask(requestCtx, authPayload.UserID, req, func(stage string) {
+			events <- askEvent{name: "progress", data: gin.H{"stage": stage}}
+		})
+		if err != nil {
+			events <- askEvent{name: "error", data: chatError(err)}
+			return
+		}
+
+		events <- askEvent{name: "result", data: result}
+	}()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Stream(func(w io.Writer) bool {
+		event, ok := <-events
+		if !ok {
+			return false
+		}
+		ctx.SSEvent(event.name, event.data)
+		return true
+	})
+}
+
+// chatError returns the chat error of err, errors that are not chat errors are internal errors.
+func chatError(err error) *protocol.Error {
+	var chatErr *protocol.Error
+	if errors.As(err, &chatErr) {
+		return chatErr
+	}
+	return &protocol.Error{Code: protocol.ErrInternal, Message: err.Error()}
+}
+
+// chatErrorResponse responds with the HTTP status of a chat error, along with its code.
+func chatErrorResponse(ctx *gin.Context, err error) {
+	chatErr := chatError(err)
+
+	status := http.StatusInternalServerError
+	switch chatErr.Code {
+	case protocol.ErrInvalidPayload:
+		status = http.StatusBadRequest
+	case protocol.ErrConnectionNotFound:
+		status = http.StatusNotFound
+	case protocol.ErrConnectionDenied:
+		status = http.StatusForbidden
+	case protocol.ErrQueryRejected, protocol.ErrQueryTooExpensive:
+		status = http.StatusUnprocessableEntity
+	case protocol.ErrConnectionFailed, protocol.ErrConversionFailed:
+		status = http.StatusBadGateway
+	case protocol.ErrRateLimited, protocol.ErrQuotaExceeded:
+		status = http.StatusTooManyRequests
+		ctx.Header("Retry-After", strconv.Itoa(chatErr.RetryAfter))
+	}
+
+	resp := apiErrorResponse(chatErr)
+	resp["message"] = chatErr.Message
+	resp["code"] = chatErr.Code
+	ctx.JSON(status, resp)
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gentcod/nlp-to-sql/chat"
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var errConnectionExists = errors.New("a connection with this name already exists in the organization")
+
+func (server *Server) listConnections(ctx *gin.Context) {
+	member, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleViewer)
+	if !valid {
+		return
+	}
+
+	conns, err := server.store.ListConnections(ctx, member.OrganizationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	resp := make([]Connection, len(conns))
+	for i, conn := range conns {
+		resp[i] = getConnection(conn)
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved connections successfully", resp))
+}
+
+func (server *Server) createConnection(ctx *gin.Context) {
+	var req createConnectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	member, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleEditor)
+	if !valid {
+		return
+	}
+
+	dbUrl, err := chat.EncryptConnectionURL(server.config.TokenSymmetricKey, req.DbUrl)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	conn, err := server.store.CreateConnection(ctx, db.CreateConnectionParams{
+		ID:               uuid.New(),
+		OrganizationID:   member.OrganizationID,
+		Name:             req.Name,
+		DbType:           req.DbType,
+		DbName:           req.DbName,
+		DbUrl:            dbUrl,
+		CreatedBy:        uuid.NullUUID{UUID: member.AuthID, Valid: true},
+		MaxEstimatedRows: nullInt64(req.MaxEstimatedRows),
+		MaxEstimatedCost: nullFloat64(req.MaxEstimatedCost),
+		CostAction:       sql.NullString{String: req.CostAction, Valid: req.CostAction != ""},
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, apiErrorResponse(errConnectionExists))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.recordConnectionAuditEvent(ctx, member, conn.ID, db.AuditOrganizationConnectionCreated)
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Connection created successfully", getConnection(conn)))
+}
+
+func (server *Server) updateConnection(ctx *gin.Context) {
+	var req updateConnectionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	member, conn, valid := server.getOrganizationConnection(ctx, db.WorkspaceRoleEditor)
+	if !valid {
+		return
+	}
+
+	var dbUrl string
+	if req.DbUrl != "" {
+		var err error
+		dbUrl, err = chat.EncryptConnectionURL(server.config.TokenSymmetricKey, req.DbUrl)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+			return
+		}
+	}
+
+	conn, err := server.store.UpdateConnection(ctx, db.UpdateConnectionParams{
+		ID:               conn.ID,
+		Name:             sql.NullString{String: req.Name, Valid: req.Name != ""},
+		DbType:           sql.NullString{String: req.DbType, Valid: req.DbType != ""},
+		DbName:           sql.NullString{String: req.DbName, Valid: req.DbName != ""},
+		DbUrl:            sql.NullString{String: dbUrl, Valid: dbUrl != ""},
+		MaxEstimatedRows: nullInt64(req.MaxEstimatedRows),
+		MaxEstimatedCost: nullFloat64(req.MaxEstimatedCost),
+		CostAction:       sql.NullString{String: req.CostAction, Valid: req.CostAction != ""},
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, apiErrorResponse(errConnectionExists))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.recordConnectionAuditEvent(ctx, member, conn.ID, db.AuditOrganizationConnectionUpdated)
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Connection updated successfully", getConnection(conn)))
+}
+
+func (server *Server) deleteConnection(ctx *gin.Context) {
+	member, conn, valid := server.getOrganizationConnection(ctx, db.WorkspaceRoleEditor)
+	if !valid {
+		return
+	}
+
+	err := server.store.DeleteConnection(ctx, conn.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.websocket.DisconnectConnection(conn.ID)
+
+	server.recordConnectionAuditEvent(ctx, member, conn.ID, db.AuditOrganizationConnectionDeleted)
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Connection deleted successfully", ""))
+}
+
+// getOrganizationConnection returns the connection of the connectionId path param when it belongs to
+// the organization of the orgId path param and the authenticated account has at least minRole in it.
+func (server *Server) getOrganizationConnection(ctx *gin.Context, minRole db.WorkspaceRole) (db.OrganizationMember, db.Connection, bool) {
+	connID, err := uuid.Parse(ctx.Param("connectionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return db.OrganizationMember{}, db.Connection{}, false
+	}
+
+	member, valid := server.authorizeOrganization(ctx, minRole)
+	if !valid {
+		return member, db.Connection{}, false
+	}
+
+	conn, err := server.store.GetConnection(ctx, connID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return member, conn, false
+	}
+	if err == sql.ErrNoRows || conn.OrganizationID != member.OrganizationID {
+		ctx.JSON(http.StatusNotFound, apiErrorResponse(chat.ErrConnectionNotFound))
+		return member, conn, false
+	}
+
+	return member, conn, true
+}
+
+func (server *Server) recordConnectionAuditEvent(ctx *gin.Context, member db.OrganizationMember, connID uuid.UUID, action string) {
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID: member.AuthID,
+		Action:  action,
+		Metadata: map[string]any{
+			"organization_id": member.OrganizationID,
+			"connection_id":   connID,
+		},
+	})
+}
+
+// getConnection omits the connection string, which is never returned once stored.
+func getConnection(conn db.Connection) Connection {
+	resp := Connection{
+		ID:             conn.ID,
+		OrganizationID: conn.OrganizationID,
+		Name:           conn.Name,
+		DbType:         conn.DbType,
+		DbName:         conn.DbName,
+		CreatedBy:      conn.CreatedBy,
+		CreatedAt:      conn.CreatedAt,
+		UpdatedAt:      conn.UpdatedAt,
+	}
+	if conn.MaxEstimatedRows.Valid {
+		resp.MaxEstimatedRows = &conn.MaxEstimatedRows.Int64
+	}
+	if conn.MaxEstimatedCost.Valid {
+		resp.MaxEstimatedCost = &conn.MaxEstimatedCost.Float64
+	}
+	if conn.CostAction.Valid {
+		resp.CostAction = &conn.CostAction.String
+	}
+	return resp
+}
+
+func nullInt64(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *v, Valid: true}
+}
+
+func nullFloat64(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxFailedLogins is the number of failed logins to an account within failedLoginWindow after which it is locked.
+	// Failures are counted while each follows the previous one within the window.
+	maxFailedLogins = 5
+	// maxFailedLoginsPerIP is the number of failed logins from an IP address within failedLoginWindow
+	// after which the IP address is locked, it is higher as users can share an IP address.
+	maxFailedLoginsPerIP = 20
+	failedLoginWindow    = 15 * time.Minute
+
+	// lockouts start at minLockout and double with every further failed login, up to maxLockout.
+	minLockout = time.Minute
+	maxLockout = time.Hour
+)
+
+// lockoutDuration returns how long to lock out logins after the failed attempts.
+func lockoutDuration(attempts, threshold int32) time.Duration {
+	if attempts < threshold {
+		return 0
+	}
+
+	exp := attempts - threshold
+	if exp >= 6 {
+		return maxLockout
+	}
+
+	return min(minLockout<<exp, maxLockout)
+}
+
+// checkIPLockout responds with 429 and returns false when logins from the client IP address are locked out.
+func (server *Server) checkIPLockout(ctx *gin.Context) bool {
+	attempt, err := server.store.GetLoginAttempt(ctx, ctx.ClientIP())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return true
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return false
+	}
+
+	return checkLockedUntil(ctx, attempt.LockedUntil)
+}
+
+// checkAccountLockout responds with 429 and returns false when logins to the account are locked out.
+func checkAccountLockout(ctx *gin.Context, auth db.Auth) bool {
+	return checkLockedUntil(ctx, auth.LockedUntil)
+}
+
+func checkLockedUntil(ctx *gin.Context, lockedUntil sql.NullTime) bool {
+	if !lockedUntil.Valid || !lockedUntil.Time.After(time.Now()) {
+		return true
+	}
+
+	retryAfter := time.Until(lockedUntil.Time)
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	err := fmt.Errorf("too many failed login attempts, try again in %v", retryAfter.Round(time.Second))
+	ctx.JSON(http.StatusTooManyRequests, apiErrorResponse(err))
+	return false
+}
+
+// recordLoginFailure counts a failed login against the client IP address and the account,
+// locking them out once they reach their threshold. authID is uuid.Nil when the email is not registered.
+// Failures are logged rather than changing the response of the failed login.
+func (server *Server) recordLoginFailure(ctx *gin.Context, authID uuid.UUID) {
+	now := time.Now()
+
+	attempt, err := server.store.RecordLoginAttemptFailure(ctx, db.RecordLoginAttemptFailureParams{
+		IpAddress:   ctx.ClientIP(),
+		WindowStart: now.Add(-failedLoginWindow),
+	})
+	if err != nil {
+		log.Printf("Error recording failed login attempt: %v", err)
+	} else if lockout := lockoutDuration(attempt.FailedAttempts, maxFailedLoginsPerIP); lockout > 0 {
+		err = server.store.LockLoginAttempt(ctx, db.LockLoginAttemptParams{
+			IpAddress:   attempt.IpAddress,
+			LockedUntil: sql.NullTime{Time: now.Add(lockout), Valid: true},
+		})
+		if err != nil {
+			log.Printf("Error locking login attempts: %v", err)
+		}
+	}
+
+	if authID == uuid.Nil {
+		return
+	}
+
+	attempts, err := server.store.RecordAuthLoginFailure(ctx, db.RecordAuthLoginFailureParams{
+		ID:          authID,
+		WindowStart: now.Add(-failedLoginWindow),
+	})
+	if err != nil {
+		log.Printf("Error recording failed login: %v", err)
+		return
+	}
+
+	if lockout := lockoutDuration(attempts, maxFailedLogins); lockout > 0 {
+		err = server.store.LockAuth(ctx, db.LockAuthParams{
+			ID:          authID,
+			LockedUntil: sql.NullTime{Time: now.Add(lockout), Valid: true},
+		})
+		if err != nil {
+			log.Printf("Error locking account: %v", err)
+		}
+	}
+}
+
+// recordLogin resets the failed logins of the account and records the time of the login.
+func (server *Server) recordLogin(ctx *gin.Context, authID uuid.UUID) {
+	if err := server.store.RecordAuthLogin(ctx, authID); err != nil {
+		log.Printf("Error recording login: %v", err)
+	}
+}
+
+// resetLoginFailures resets the failed logins of the account without recording a login.
+func (server *Server) resetLoginFailures(ctx *gin.Context, authID uuid.UUID) {
+	if err := server.store.UnlockAuth(ctx, authID); err != nil {
+		log.Printf("Error resetting failed logins: %v", err)
+	}
+}
@@ -3,9 +3,12 @@ package api
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/gentcod/nlp-to-sql/limiter"
 	"github.com/gentcod/nlp-to-sql/token"
 	"github.com/gin-gonic/gin"
 )
@@ -52,3 +55,24 @@ func authMiddleware(tokenGenerator token.Generator) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// rateLimitMiddleware creates a gin middleware that limits the request rate of the authenticated user.
+func rateLimitMiddleware(rateLimiter *limiter.Limiter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		allowed, retryAfter, err := rateLimiter.Allow(ctx, authPayload.UserID)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, apiErrorResponse(err))
+			return
+		}
+
+		if !allowed {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, apiErrorResponse(limiter.ErrRateLimited))
+			return
+		}
+
+		ctx.Next()
+	}
+}
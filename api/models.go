@@ -2,6 +2,10 @@ package api
 
 import (
 	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/limiter"
+	"github.com/google/uuid"
 )
 
 type createUserRequest struct {
@@ -11,6 +15,42 @@ type createUserRequest struct {
 	Password string `json:"password" binding:"required,min=8"`
 }
 
+type createAdminRequest struct {
+	createUserRequest
+	// Token is an invitation token, or the bootstrap token when creating the first admin.
+	Token string `json:"token" binding:"required"`
+}
+
+type createAdminInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type AdminInvitation struct {
+	ID        uuid.UUID     `json:"id"`
+	Email     string        `json:"email"`
+	InvitedBy uuid.NullUUID `json:"invited_by"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// createConnectionRuleRequest allows connections to a target, an omitted port or db_type matches any port or driver.
+type createConnectionRuleRequest struct {
+	Target      string `json:"target" binding:"required,max=253"`
+	Port        int32  `json:"port" binding:"omitempty,min=1,max=65535"`
+	DbType      string `json:"db_type" binding:"omitempty,oneof=postgres mysql"`
+	Description string `json:"description" binding:"max=200"`
+}
+
+type ConnectionRule struct {
+	ID          uuid.UUID     `json:"id"`
+	Target      string        `json:"target"`
+	Port        *int32        `json:"port"`
+	DbType      *string       `json:"db_type"`
+	Description string        `json:"description"`
+	CreatedBy   uuid.NullUUID `json:"created_by"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
 type loginUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
@@ -25,6 +65,54 @@ type loginUserResponse struct {
 	User                  UserProfile `json:"user"`
 }
 
+type mfaChallengeResponse struct {
+	MFARequired       bool      `json:"mfa_required"`
+	MFAToken          string    `json:"mfa_token"`
+	MFATokenExpiresAt time.Time `json:"mfa_token_expires_at"`
+}
+
+type mfaLoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,max=20"`
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code" binding:"required,max=20"`
+}
+
+type disableMFARequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required,max=20"`
+}
+
+type mfaStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+type mfaEnrolResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type mfaRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type emailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
 type updateUserRequest struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
@@ -40,6 +128,162 @@ type UserProfile struct {
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 }
 
+type restrictUserRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type moderateUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
 type adminModUserRequest struct {
 	userId string `query:"userId" binding:"required"`
 }
+
+type listUsersRequest struct {
+	Restricted  *bool     `form:"restricted"`
+	Deleted     *bool     `form:"deleted"`
+	Role        string    `form:"role" binding:"omitempty,oneof=user admin"`
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Search      string    `form:"q"`
+	Page        int32     `form:"page,default=1" binding:"min=1"`
+	PageSize    int32     `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+type listUsersResponse struct {
+	Users    []UserDetail `json:"users"`
+	Page     int32        `json:"page"`
+	PageSize int32        `json:"page_size"`
+	Total    int64        `json:"total"`
+}
+
+type UserDetail struct {
+	ID                uuid.UUID             `json:"id"`
+	Username          string                `json:"username"`
+	FullName          string                `json:"full_name"`
+	Email             string                `json:"email"`
+	Role              string                `json:"role"`
+	Restricted        bool                  `json:"restricted"`
+	Deleted           bool                  `json:"deleted"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+	PasswordChangedAt *time.Time            `json:"password_changed_at,omitempty"`
+	LastLoginAt       *time.Time            `json:"last_login_at,omitempty"`
+	FailedAttempts    int32                 `json:"failed_attempts"`
+	Locked            bool                  `json:"locked"`
+	LockedUntil       *time.Time            `json:"locked_until,omitempty"`
+	ModerationHistory []db.ModerationAction `json:"moderation_history,omitempty"`
+}
+
+type listAuditEventsRequest struct {
+	ActorID     string    `form:"actor_id" binding:"omitempty,uuid"`
+	TargetID    string    `form:"target_id" binding:"omitempty,uuid"`
+	Action      string    `form:"action"`
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor      int64     `form:"cursor" binding:"min=0"`
+	Limit       int32     `form:"limit,default=50" binding:"min=1,max=200"`
+}
+
+type listAuditEventsResponse struct {
+	Events []db.AuditEvent `json:"events"`
+	// NextCursor is passed as the cursor to get the next page, it is omitted on the last page.
+	NextCursor int64 `json:"next_cursor,omitempty"`
+}
+
+type listQueryLogsRequest struct {
+	ConnectionID string    `form:"connection_id"`
+	Valid        *bool     `form:"valid"`
+	Failed       *bool     `form:"failed"`
+	CreatedFrom  time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo    time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor       int64     `form:"cursor" binding:"min=0"`
+	Limit        int32     `form:"limit,default=50" binding:"min=1,max=200"`
+}
+
+type adminListQueryLogsRequest struct {
+	listQueryLogsRequest
+	UserID string `form:"user_id" binding:"omitempty,uuid"`
+}
+
+type listQueryLogsResponse struct {
+	Queries []db.QueryLog `json:"queries"`
+	// NextCursor is passed as the cursor to get the next page, it is omitted on the last page.
+	NextCursor int64 `json:"next_cursor,omitempty"`
+}
+
+// setUserLimitsRequest sets the limits of a user, omitted limits fall back to the server defaults.
+type setUserLimitsRequest struct {
+	RequestsPerMinute *int32 `json:"requests_per_minute" binding:"omitempty,min=0"`
+	Burst             *int32 `json:"burst" binding:"omitempty,min=0"`
+	MonthlyTokenQuota *int64 `json:"monthly_token_quota" binding:"omitempty,min=0"`
+}
+
+type userLimitsResponse struct {
+	Limits limiter.Limits `json:"limits"`
+	Usage  db.LlmUsage    `json:"usage"`
+}
+
+type organizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type addOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+type updateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// createConnectionRequest adds a connection, omitted cost limits and cost action fall back to the defaults of the server.
+type createConnectionRequest struct {
+	Name             string   `json:"name" binding:"required,max=100"`
+	DbType           string   `json:"db_type" binding:"required,oneof=postgres mysql"`
+	DbName           string   `json:"db_name" binding:"required"`
+	DbUrl            string   `json:"db_url" binding:"required"`
+	MaxEstimatedRows *int64   `json:"max_estimated_rows" binding:"omitempty,min=0"`
+	MaxEstimatedCost *float64 `json:"max_estimated_cost" binding:"omitempty,min=0"`
+	CostAction       string   `json:"cost_action" binding:"omitempty,oneof=confirm refuse"`
+}
+
+type updateConnectionRequest struct {
+	Name             string   `json:"name" binding:"max=100"`
+	DbType           string   `json:"db_type" binding:"omitempty,oneof=postgres mysql"`
+	DbName           string   `json:"db_name"`
+	DbUrl            string   `json:"db_url"`
+	MaxEstimatedRows *int64   `json:"max_estimated_rows" binding:"omitempty,min=0"`
+	MaxEstimatedCost *float64 `json:"max_estimated_cost" binding:"omitempty,min=0"`
+	CostAction       string   `json:"cost_action" binding:"omitempty,oneof=confirm refuse"`
+}
+
+type Connection struct {
+	ID               uuid.UUID     `json:"id"`
+	OrganizationID   uuid.UUID     `json:"organization_id"`
+	Name             string        `json:"name"`
+	DbType           string        `json:"db_type"`
+	DbName           string        `json:"db_name"`
+	MaxEstimatedRows *int64        `json:"max_estimated_rows"`
+	MaxEstimatedCost *float64      `json:"max_estimated_cost"`
+	CostAction       *string       `json:"cost_action"`
+	CreatedBy        uuid.NullUUID `json:"created_by"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+// savedQuestionRequest creates or updates a saved question, omitted fields are not updated.
+type savedQuestionRequest struct {
+	Title        string `json:"title" binding:"max=200"`
+	Question     string `json:"question" binding:"max=2000"`
+	ConnectionID string `json:"connection_id" binding:"omitempty,uuid"`
+}
+
+// askChatRequest asks a question about a workspace connection, it is bound from the query of streamed requests.
+type askChatRequest struct {
+	ConnectionID string `json:"connection_id" form:"connection_id" binding:"required,uuid"`
+	Question     string `json:"question" form:"question" binding:"required,max=2000"`
+	AnswerMode   string `json:"answer_mode" form:"answer_mode" binding:"omitempty,oneof=llm deterministic"`
+	Mode         string `json:"mode" form:"mode" binding:"omitempty,oneof=answer sql_only"`
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var errMemberNotFound = errors.New("member not found")
+
+func (server *Server) listOrganizationMembers(ctx *gin.Context) {
+	member, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleViewer)
+	if !valid {
+		return
+	}
+
+	members, err := server.store.ListOrganizationMembers(ctx, member.OrganizationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved members successfully", members))
+}
+
+func (server *Server) addOrganizationMember(ctx *gin.Context) {
+	var req addOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	owner, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleOwner)
+	if !valid {
+		return
+	}
+
+	auth, err := server.store.ValidateAuth(ctx, req.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			msg := "no account exists with this email"
+			ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	if auth.Deleted {
+		msg := "no account exists with this email"
+		ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
+		return
+	}
+
+	member, err := server.store.AddOrganizationMember(ctx, db.AddOrganizationMemberParams{
+		OrganizationID: owner.OrganizationID,
+		AuthID:         auth.ID,
+		Role:           db.WorkspaceRole(req.Role),
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			msg := "account is already a member of the organization"
+			ctx.JSON(http.StatusConflict, apiErrorResponse(errors.New(msg)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	server.recordMemberAuditEvent(ctx, owner, member.AuthID, db.AuditOrganizationMemberAdded, member.Role)
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Member added successfully", member))
+}
+
+func (server *Server) updateOrganizationMember(ctx *gin.Context) {
+	var req updateOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("memberId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	owner, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleOwner)
+	if !valid {
+		return
+	}
+
+	member, err := server.store.UpdateOrganizationMemberRoleTx(ctx, db.UpdateOrganizationMemberRoleParams{
+		OrganizationID: owner.OrganizationID,
+		AuthID:         memberID,
+		Role:           db.WorkspaceRole(req.Role),
+	})
+	if err != nil {
+		server.respondMemberError(ctx, err)
+		return
+	}
+
+	server.recordMemberAuditEvent(ctx, owner, member.AuthID, db.AuditOrganizationMemberUpdated, member.Role)
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Member updated successfully", member))
+}
+
+// removeOrganizationMember removes a member, owners can remove any member and other members can only leave.
+func (server *Server) removeOrganizationMember(ctx *gin.Context) {
+	memberID, err := uuid.Parse(ctx.Param("memberId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	actor, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleViewer)
+	if !valid {
+		return
+	}
+
+	if actor.AuthID != memberID && actor.Role != db.WorkspaceRoleOwner {
+		msg := "only owners can remove other members"
+		ctx.JSON(http.StatusForbidden, apiErrorResponse(errors.New(msg)))
+		return
+	}
+
+	err = server.store.RemoveOrganizationMemberTx(ctx, db.RemoveOrganizationMemberParams{
+		OrganizationID: actor.OrganizationID,
+		AuthID:         memberID,
+	})
+	if err != nil {
+		server.respondMemberError(ctx, err)
+		return
+	}
+
+	// chats of the member with the connections of the organization are closed
+	server.websocket.DisconnectMember(actor.OrganizationID, memberID)
+
+	server.recordMemberAuditEvent(ctx, actor, memberID, db.AuditOrganizationMemberRemoved, "")
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Member removed successfully", ""))
+}
+
+func (server *Server) respondMemberError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, apiErrorResponse(errMemberNotFound))
+	case errors.Is(err, db.ErrLastOwner):
+		ctx.JSON(http.StatusConflict, apiErrorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+	}
+}
+
+func (server *Server) recordMemberAuditEvent(ctx *gin.Context, actor db.OrganizationMember, memberID uuid.UUID, action string, role db.WorkspaceRole) {
+	metadata := map[string]any{
+		"organization_id": actor.OrganizationID,
+	}
+	if role != "" {
+		metadata["role"] = role
+	}
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  actor.AuthID,
+		TargetID: memberID,
+		Action:   action,
+		Metadata: metadata,
+	})
+}
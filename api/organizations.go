@@ -0,0 +1,148 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var errOrganizationNotFound = errors.New("organization not found")
+
+func (server *Server) createOrganization(ctx *gin.Context) {
+	var req organizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	org, err := server.store.CreateOrganizationTx(ctx, db.CreateOrganizationParams{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		CreatedBy: uuid.NullUUID{UUID: authPayload.UserID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Organization created successfully", org))
+}
+
+func (server *Server) listOrganizations(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	orgs, err := server.store.ListUserOrganizations(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved organizations successfully", orgs))
+}
+
+func (server *Server) getOrganization(ctx *gin.Context) {
+	member, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleViewer)
+	if !valid {
+		return
+	}
+
+	org, err := server.store.GetOrganization(ctx, member.OrganizationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved organization successfully", org))
+}
+
+func (server *Server) updateOrganization(ctx *gin.Context) {
+	var req organizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	member, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleOwner)
+	if !valid {
+		return
+	}
+
+	org, err := server.store.UpdateOrganization(ctx, db.UpdateOrganizationParams{
+		ID:   member.OrganizationID,
+		Name: req.Name,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Organization updated successfully", org))
+}
+
+func (server *Server) deleteOrganization(ctx *gin.Context) {
+	member, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleOwner)
+	if !valid {
+		return
+	}
+
+	err := server.store.DeleteOrganization(ctx, member.OrganizationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Organization deleted successfully", ""))
+}
+
+// authorizeOrganization returns the membership of the authenticated account in the organization of the orgId path param,
+// responding with 404 when it is not a member and 403 when its role is lower than minRole.
+func (server *Server) authorizeOrganization(ctx *gin.Context, minRole db.WorkspaceRole) (db.OrganizationMember, bool) {
+	orgID, err := uuid.Parse(ctx.Param("orgId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return db.OrganizationMember{}, false
+	}
+
+	// non-members get the same response whether or not the organization exists
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := server.store.GetOrganizationMember(ctx, db.GetOrganizationMemberParams{
+		OrganizationID: orgID,
+		AuthID:         authPayload.UserID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, apiErrorResponse(errOrganizationNotFound))
+			return member, false
+		}
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return member, false
+	}
+
+	if workspaceRoleRank(member.Role) < workspaceRoleRank(minRole) {
+		err := fmt.Errorf("this action requires the %v role in the organization", minRole)
+		ctx.JSON(http.StatusForbidden, apiErrorResponse(err))
+		return member, false
+	}
+
+	return member, true
+}
+
+// workspaceRoleRank orders roles by their permissions, every role has the permissions of the roles ranked below it.
+func workspaceRoleRank(role db.WorkspaceRole) int {
+	switch role {
+	case db.WorkspaceRoleOwner:
+		return 3
+	case db.WorkspaceRoleEditor:
+		return 2
+	case db.WorkspaceRoleViewer:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// listQueryLogs lists the queries run by the authenticated user.
+func (server *Server) listQueryLogs(ctx *gin.Context) {
+	var req listQueryLogsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	server.respondQueryLogs(ctx, req, uuid.NullUUID{
+		UUID:  authPayload.UserID,
+		Valid: true,
+	})
+}
+
+func (server *Server) adminListQueryLogs(ctx *gin.Context) {
+	var req adminListQueryLogsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	if _, valid := server.validateAdminAuth(ctx); !valid {
+		return
+	}
+
+	server.respondQueryLogs(ctx, req.listQueryLogsRequest, nullUUID(req.UserID))
+}
+
+func (server *Server) respondQueryLogs(ctx *gin.Context, req listQueryLogsRequest, userID uuid.NullUUID) {
+	queries, err := server.store.ListQueryLogs(ctx, db.ListQueryLogsParams{
+		Cursor: sql.NullInt64{
+			Int64: req.Cursor,
+			Valid: req.Cursor > 0,
+		},
+		UserID: userID,
+		ConnectionID: sql.NullString{
+			String: req.ConnectionID,
+			Valid:  req.ConnectionID != "",
+		},
+		Valid:  nullBool(req.Valid),
+		Failed: nullBool(req.Failed),
+		CreatedFrom: sql.NullTime{
+			Time:  req.CreatedFrom,
+			Valid: !req.CreatedFrom.IsZero(),
+		},
+		CreatedTo: sql.NullTime{
+			Time:  req.CreatedTo,
+			Valid: !req.CreatedTo.IsZero(),
+		},
+		PageLimit: req.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	resp := listQueryLogsResponse{
+		Queries: queries,
+	}
+	if len(queries) == int(req.Limit) {
+		resp.NextCursor = queries[len(queries)-1].ID
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved queries successfully", resp))
+}
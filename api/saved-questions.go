@@ -0,0 +1,171 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gentcod/nlp-to-sql/chat"
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var errSavedQuestionNotFound = errors.New("saved question not found")
+
+func (server *Server) listSavedQuestions(ctx *gin.Context) {
+	member, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleViewer)
+	if !valid {
+		return
+	}
+
+	questions, err := server.store.ListSavedQuestions(ctx, member.OrganizationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved saved questions successfully", questions))
+}
+
+func (server *Server) getSavedQuestion(ctx *gin.Context) {
+	_, question, valid := server.getOrganizationSavedQuestion(ctx, db.WorkspaceRoleViewer)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Retrieved saved question successfully", question))
+}
+
+func (server *Server) createSavedQuestion(ctx *gin.Context) {
+	var req savedQuestionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	if req.Title == "" || req.Question == "" {
+		msg := "title and question are required"
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(errors.New(msg)))
+		return
+	}
+
+	member, valid := server.authorizeOrganization(ctx, db.WorkspaceRoleEditor)
+	if !valid {
+		return
+	}
+
+	connID, valid := server.validSavedQuestionConnection(ctx, member, req.ConnectionID)
+	if !valid {
+		return
+	}
+
+	question, err := server.store.CreateSavedQuestion(ctx, db.CreateSavedQuestionParams{
+		ID:             uuid.New(),
+		OrganizationID: member.OrganizationID,
+		ConnectionID:   connID,
+		Title:          req.Title,
+		Question:       req.Question,
+		CreatedBy:      uuid.NullUUID{UUID: member.AuthID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Saved question created successfully", question))
+}
+
+func (server *Server) updateSavedQuestion(ctx *gin.Context) {
+	var req savedQuestionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return
+	}
+
+	member, question, valid := server.getOrganizationSavedQuestion(ctx, db.WorkspaceRoleEditor)
+	if !valid {
+		return
+	}
+
+	connID, valid := server.validSavedQuestionConnection(ctx, member, req.ConnectionID)
+	if !valid {
+		return
+	}
+
+	question, err := server.store.UpdateSavedQuestion(ctx, db.UpdateSavedQuestionParams{
+		ID:           question.ID,
+		Title:        sql.NullString{String: req.Title, Valid: req.Title != ""},
+		Question:     sql.NullString{String: req.Question, Valid: req.Question != ""},
+		ConnectionID: connID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Saved question updated successfully", question))
+}
+
+func (server *Server) deleteSavedQuestion(ctx *gin.Context) {
+	_, question, valid := server.getOrganizationSavedQuestion(ctx, db.WorkspaceRoleEditor)
+	if !valid {
+		return
+	}
+
+	err := server.store.DeleteSavedQuestion(ctx, question.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiServerResponse("Saved question deleted successfully", ""))
+}
+
+// getOrganizationSavedQuestion returns the saved question of the questionId path param when it belongs to
+// the organization of the orgId path param and the authenticated account has at least minRole in it.
+func (server *Server) getOrganizationSavedQuestion(ctx *gin.Context, minRole db.WorkspaceRole) (db.OrganizationMember, db.SavedQuestion, bool) {
+	questionID, err := uuid.Parse(ctx.Param("questionId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(err))
+		return db.OrganizationMember{}, db.SavedQuestion{}, false
+	}
+
+	member, valid := server.authorizeOrganization(ctx, minRole)
+	if !valid {
+		return member, db.SavedQuestion{}, false
+	}
+
+	question, err := server.store.GetSavedQuestion(ctx, questionID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return member, question, false
+	}
+	if err == sql.ErrNoRows || question.OrganizationID != member.OrganizationID {
+		ctx.JSON(http.StatusNotFound, apiErrorResponse(errSavedQuestionNotFound))
+		return member, question, false
+	}
+
+	return member, question, true
+}
+
+// validSavedQuestionConnection checks that the connection of a saved question belongs to the organization,
+// a saved question does not need a connection.
+func (server *Server) validSavedQuestionConnection(ctx *gin.Context, member db.OrganizationMember, connectionID string) (uuid.NullUUID, bool) {
+	if connectionID == "" {
+		return uuid.NullUUID{}, true
+	}
+
+	connID := nullUUID(connectionID)
+	conn, err := server.store.GetConnection(ctx, connID.UUID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
+		return connID, false
+	}
+	if err == sql.ErrNoRows || conn.OrganizationID != member.OrganizationID {
+		ctx.JSON(http.StatusBadRequest, apiErrorResponse(chat.ErrConnectionNotFound))
+		return connID, false
+	}
+
+	return connID, true
+}
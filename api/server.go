@@ -2,14 +2,21 @@ package api
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/gentcod/nlp-to-sql/chat"
 	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/limiter"
+	"github.com/gentcod/nlp-to-sql/mailer"
 	"github.com/gentcod/nlp-to-sql/token"
 	"github.com/gentcod/nlp-to-sql/util"
 	"github.com/gin-gonic/gin"
 )
 
+// minBootstrapTokenLength makes the bootstrap token, which creates the first admin, hard to guess.
+const minBootstrapTokenLength = 32
+
 // Server serves HTTP requests for our banking service
 type Server struct {
 	config              util.Config
@@ -17,19 +24,31 @@ type Server struct {
 	tokenGenerator      token.Generator
 	adminTokenGenerator token.Generator
 	websocket           *chat.WebSocketServer
+	limiter             *limiter.Limiter
+	mailer              mailer.Mailer
+	accountStatuses     *accountStatusCache
 	router              *gin.Engine
 }
 
 // NewServer creates a new HTTP server amd setup routing
-func NewServer(config util.Config, store db.Store, websocket *chat.WebSocketServer) (*Server, error) {
+func NewServer(config util.Config, store db.Store, websocket *chat.WebSocketServer, limiter *limiter.Limiter, mailer mailer.Mailer) (*Server, error) {
 	tokenGenerator, err := token.NewPasetoGenerator(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot initialize token generator: %v", err)
 	}
 
-	adminTokenGenerator, err := token.NewPasetoGenerator(config.TokenSymmetricKey)
+	// admin tokens are signed with their own key, so that user tokens are never accepted by admin routes
+	if config.AdminTokenSymmetricKey == config.TokenSymmetricKey {
+		return nil, fmt.Errorf("admin token symmetric key must be set and differ from the token symmetric key")
+	}
+
+	adminTokenGenerator, err := token.NewPasetoGenerator(config.AdminTokenSymmetricKey)
 	if err != nil {
-		return nil, fmt.Errorf("cannot initialize token generator: %v", err)
+		return nil, fmt.Errorf("cannot initialize admin token generator: %v", err)
+	}
+
+	if config.AdminBootstrapToken != "" && len(config.AdminBootstrapToken) < minBootstrapTokenLength {
+		return nil, fmt.Errorf("admin bootstrap token must be at least %d characters", minBootstrapTokenLength)
 	}
 
 	server := &Server{
@@ -38,40 +57,114 @@ func NewServer(config util.Config, store db.Store, websocket *chat.WebSocketServ
 		tokenGenerator:      tokenGenerator,
 		adminTokenGenerator: adminTokenGenerator,
 		websocket:           websocket,
+		limiter:             limiter,
+		mailer:              mailer,
+		accountStatuses:     newAccountStatusCache(store, accountStatusTTL),
 	}
 
-	server.setupRouter()
+	err = server.setupRouter()
+	if err != nil {
+		return nil, err
+	}
 
 	return server, nil
 }
 
-func (server *Server) setupRouter() {
+func (server *Server) setupRouter() error {
 	router := gin.Default()
 
+	// client IP addresses key login lockouts, they are only read from X-Forwarded-For when set by a trusted proxy
+	err := router.SetTrustedProxies(trustedProxies(server.config.TrustedProxies))
+	if err != nil {
+		return fmt.Errorf("invalid trusted proxies: %v", err)
+	}
+
 	v1Routes := router.Group("/api/v1")
 
 	v1Routes.POST("/user/signup", server.createUser)
 	v1Routes.POST("/user/login", server.loginUser)
+	v1Routes.POST("/user/verify-email/request", server.requestEmailVerification)
+	v1Routes.POST("/user/verify-email/confirm", server.confirmEmailVerification)
+	v1Routes.POST("/user/password-reset/request", server.requestPasswordReset)
+	v1Routes.POST("/user/password-reset/confirm", server.confirmPasswordReset)
 
 	v1Routes.POST("/admin/signup", server.createAdminUser)
 	v1Routes.POST("/admin/login", server.loginAdminUser)
+	v1Routes.POST("/admin/login/mfa", server.loginAdminMFA)
 
 	// for testing purposes
 	// v1Routes.GET("/chat", server.websocket.HandleConnection)
 
-	authRoutes := v1Routes.Group("/").Use((authMiddleware(server.tokenGenerator)))
+	authRoutes := v1Routes.Group("/").Use(authMiddleware(server.tokenGenerator), accountStatusMiddleware(server.accountStatuses), rateLimitMiddleware(server.limiter))
 	authRoutes.PATCH("/user/update", server.updateUser)
 	authRoutes.PATCH("/user/delete", server.deleteUser)
-
-	adminAuthRoutes := v1Routes.Group("/").Use((authMiddleware(server.adminTokenGenerator)))
+	authRoutes.GET("/user/queries", server.listQueryLogs)
+
+	authRoutes.POST("/organizations", server.createOrganization)
+	authRoutes.GET("/organizations", server.listOrganizations)
+	authRoutes.GET("/organizations/:orgId", server.getOrganization)
+	authRoutes.PATCH("/organizations/:orgId", server.updateOrganization)
+	authRoutes.DELETE("/organizations/:orgId", server.deleteOrganization)
+	authRoutes.GET("/organizations/:orgId/members", server.listOrganizationMembers)
+	authRoutes.POST("/organizations/:orgId/members", server.addOrganizationMember)
+	authRoutes.PATCH("/organizations/:orgId/members/:memberId", server.updateOrganizationMember)
+	authRoutes.DELETE("/organizations/:orgId/members/:memberId", server.removeOrganizationMember)
+	authRoutes.GET("/organizations/:orgId/connections", server.listConnections)
+	authRoutes.POST("/organizations/:orgId/connections", server.createConnection)
+	authRoutes.PATCH("/organizations/:orgId/connections/:connectionId", server.updateConnection)
+	authRoutes.DELETE("/organizations/:orgId/connections/:connectionId", server.deleteConnection)
+	authRoutes.GET("/organizations/:orgId/questions", server.listSavedQuestions)
+	authRoutes.POST("/organizations/:orgId/questions", server.createSavedQuestion)
+	authRoutes.GET("/organizations/:orgId/questions/:questionId", server.getSavedQuestion)
+	authRoutes.PATCH("/organizations/:orgId/questions/:questionId", server.updateSavedQuestion)
+	authRoutes.DELETE("/organizations/:orgId/questions/:questionId", server.deleteSavedQuestion)
+
+	// admins can manage two-factor authentication before enabling it when it is required
+	adminMFARoutes := v1Routes.Group("/").Use(authMiddleware(server.adminTokenGenerator), accountStatusMiddleware(server.accountStatuses), server.requireAdmin(), rateLimitMiddleware(server.limiter))
+	adminMFARoutes.GET("/admin/mfa", server.getAdminMFA)
+	adminMFARoutes.POST("/admin/mfa/enrol", server.enrolAdminMFA)
+	adminMFARoutes.POST("/admin/mfa/verify", server.verifyAdminMFA)
+	adminMFARoutes.POST("/admin/mfa/disable", server.disableAdminMFA)
+
+	adminAuthRoutes := v1Routes.Group("/").Use(authMiddleware(server.adminTokenGenerator), accountStatusMiddleware(server.accountStatuses), server.requireAdmin(), rateLimitMiddleware(server.limiter), server.requireAdminMFA())
 	adminAuthRoutes.PATCH("/admin/update", server.updateAdminUser)
 	adminAuthRoutes.PATCH("/admin/user/restrict/:userId", server.adminRestrictUser)
 	adminAuthRoutes.PATCH("/admin/user/delete/:userId", server.adminDeleteUser)
+	adminAuthRoutes.PATCH("/admin/user/unrestrict/:userId", server.adminUnrestrictUser)
+	adminAuthRoutes.PATCH("/admin/user/restore/:userId", server.adminRestoreUser)
+	adminAuthRoutes.GET("/admin/users", server.adminListUsers)
+	adminAuthRoutes.GET("/admin/users/:userId", server.adminGetUser)
+	adminAuthRoutes.GET("/admin/audit", server.adminListAuditEvents)
+	adminAuthRoutes.GET("/admin/queries", server.adminListQueryLogs)
+	adminAuthRoutes.GET("/admin/user/limits/:userId", server.adminGetUserLimits)
+	adminAuthRoutes.PATCH("/admin/user/limits/:userId", server.adminSetUserLimits)
+	adminAuthRoutes.POST("/admin/invitations", server.adminCreateInvitation)
+	adminAuthRoutes.GET("/admin/invitations", server.adminListInvitations)
+	adminAuthRoutes.DELETE("/admin/invitations/:invitationId", server.adminRevokeInvitation)
+	adminAuthRoutes.GET("/admin/connection-rules", server.adminListConnectionRules)
+	adminAuthRoutes.POST("/admin/connection-rules", server.adminCreateConnectionRule)
+	adminAuthRoutes.DELETE("/admin/connection-rules/:ruleId", server.adminDeleteConnectionRule)
+	adminAuthRoutes.GET("/admin/connection-pools", server.adminListConnectionPools)
 
 	// websocket server
-	authRoutes.GET("/chat", server.websocket.HandleConnection)
+	authRoutes.GET("/chat", server.connectChat)
+	authRoutes.POST("/chat/ask", server.askChat)
+	authRoutes.GET("/chat/ask/stream", server.streamAskChat)
 
 	server.router = router
+	return nil
+}
+
+// trustedProxies parses a comma separated list of proxy addresses or CIDR ranges,
+// it returns nil to trust no proxy when the list is empty.
+func trustedProxies(list string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(list, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
 }
 
 // Start runs HTTP server on a specific address
@@ -79,6 +172,11 @@ func (server *Server) Start(address string) error {
 	return server.router.Run(address)
 }
 
+// Handler returns the HTTP handler of the server, e.g. to serve it with an http.Server that can be shut down.
+func (server *Server) Handler() http.Handler {
+	return server.router
+}
+
 // apiErrorResponse returns a custom error response.
 func apiServerResponse(msg string, data interface{}) gin.H {
 	return gin.H{
@@ -3,6 +3,7 @@ package api
 import (
 	"database/sql"
 	"errors"
+	"log"
 	"net/http"
 	"time"
 
@@ -55,6 +56,17 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  usertx.Auth.ID,
+		TargetID: usertx.Auth.ID,
+		Action:   db.AuditUserSignup,
+	})
+
+	// the account is created even if the email cannot be sent, the verification email can be requested again
+	if err := server.sendEmailVerification(ctx, usertx.Auth.ID, usertx.Auth.Email); err != nil {
+		log.Printf("Error sending verification email: %v", err)
+	}
+
 	profile := getUserProfile(usertx)
 
 	ctx.JSON(http.StatusOK, apiServerResponse("user account created sucessfully", profile))
@@ -125,6 +137,15 @@ func (server *Server) updateUser(ctx *gin.Context) {
 		return
 	}
 
+	server.recordUpdateAuditEvents(ctx, auth.ID, req, db.AuditUserUpdated, db.AuditUserPasswordChanged)
+
+	// a changed email is no longer verified, the account is updated even if the email cannot be sent
+	if updateTx.Auth.Email != "" && updateTx.Auth.Email != auth.Email {
+		if err := server.sendEmailVerification(ctx, updateTx.Auth.ID, updateTx.Auth.Email); err != nil {
+			log.Printf("Error sending verification email: %v", err)
+		}
+	}
+
 	profile := getUserProfile(updateTx)
 	ctx.JSON(http.StatusOK, apiServerResponse("user account updated sucessfully", profile))
 }
@@ -142,7 +163,7 @@ func (server *Server) deleteUser(ctx *gin.Context) {
 		return
 	}
 
-	user, err := server.store.GetUser(ctx, auth.ID)
+	_, err = server.store.GetUser(ctx, auth.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			msg := "user account not found"
@@ -153,7 +174,7 @@ func (server *Server) deleteUser(ctx *gin.Context) {
 		return
 	}
 
-	err = server.store.DeleteUserTx(ctx, auth.ID, user.ID)
+	err = server.store.DeleteUserTx(ctx, auth.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			msg := "user authentication not found"
@@ -164,6 +185,14 @@ func (server *Server) deleteUser(ctx *gin.Context) {
 		return
 	}
 
+	server.revokeAccountAccess(auth.ID)
+
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  auth.ID,
+		TargetID: auth.ID,
+		Action:   db.AuditUserDeleted,
+	})
+
 	ctx.JSON(http.StatusOK, apiServerResponse("Deleted account successfully", ""))
 }
 
@@ -176,9 +205,26 @@ func (server *Server) loginUser(ctx *gin.Context) {
 
 	auth, valid := server.validateUser(ctx, req.Email, req.Password)
 	if !valid {
+		server.recordLoginFailedEvent(ctx, auth.ID, req.Email, db.AuditUserLoginFailed)
 		return
 	}
 
+	// the password is correct, so earlier failures are reset, but unverified accounts cannot log in yet
+	if !auth.EmailVerifiedAt.Valid {
+		server.resetLoginFailures(ctx, auth.ID)
+		server.recordAuditEvent(ctx, db.AuditEventParams{
+			ActorID:  auth.ID,
+			TargetID: auth.ID,
+			Action:   db.AuditUserLoginUnverified,
+		})
+
+		msg := "Email address has not been verified."
+		ctx.JSON(http.StatusForbidden, apiErrorResponse(errors.New(msg)))
+		return
+	}
+
+	server.recordLogin(ctx, auth.ID)
+
 	user, err := server.store.GetUser(ctx, auth.ID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, apiErrorResponse(err))
@@ -203,13 +249,24 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		},
 	}
 
+	server.recordAuditEvent(ctx, db.AuditEventParams{
+		ActorID:  auth.ID,
+		TargetID: auth.ID,
+		Action:   db.AuditUserLogin,
+	})
+
 	ctx.JSON(http.StatusOK, apiServerResponse("Account login sucessfully", resp))
 }
 
 func (server *Server) validateUser(ctx *gin.Context, email string, password string) (db.Auth, bool) {
+	if !server.checkIPLockout(ctx) {
+		return db.Auth{}, false
+	}
+
 	auth, err := server.store.ValidateAuth(ctx, email)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			server.recordLoginFailure(ctx, uuid.Nil)
 			msg := "user not found"
 			ctx.JSON(http.StatusNotFound, apiErrorResponse(errors.New(msg)))
 			return auth, false
@@ -236,8 +293,13 @@ func (server *Server) validateUser(ctx *gin.Context, email string, password stri
 		return auth, false
 	}
 
+	if !checkAccountLockout(ctx, auth) {
+		return auth, false
+	}
+
 	err = util.CheckPassword(password, auth.HarshedPassword)
 	if err != nil {
+		server.recordLoginFailure(ctx, auth.ID)
 		ctx.JSON(http.StatusUnauthorized, apiErrorResponse(err))
 		return auth, false
 	}
@@ -0,0 +1,79 @@
+package chat
+
+import (
+	"context"
+
+	"github.com/gentcod/nlp-to-sql/chat/protocol"
+	conv "github.com/gentcod/nlp-to-sql/converter"
+	"github.com/google/uuid"
+)
+
+// Stages of answering a question reported by Ask.
+const (
+	// StageConnected is reached once the database is connected to and its schema is mapped.
+	StageConnected      = "connected"
+	StageQueryGenerated = conv.StageQueryGenerated
+	StageDataFetched    = conv.StageDataFetched
+)
+
+// Ask answers a question about a database outside of a WebSocket connection, e.g. for HTTP clients.
+// The database is described like in a start message and is only connected to for the question.
+// Progress, when not nil, is called with the stages of the answer as they are reached.
+// Errors are *protocol.Error, the rate limit is not checked as HTTP requests are rate limited by the API.
+// Queries exceeding the cost limits of the connection are refused, as they cannot be confirmed.
+func (srv *WebSocketServer) Ask(ctx context.Context, userID uuid.UUID, dbData protocol.StartPayload, question string, progress func(stage string)) (protocol.ChatResult, error) {
+	s, err := srv.askSession(ctx, userID, dbData, question, progress)
+	if err != nil {
+		return protocol.ChatResult{}, err
+	}
+	defer srv.pools.Release(s.connID)
+
+	// HTTP clients cannot confirm expensive queries
+	result, err := srv.answer(userID, s, answerParams{question: question, refuse: true, progress: progress})
+	if err != nil {
+		return protocol.ChatResult{}, err
+	}
+
+	return result, nil
+}
+
+// AskSQL generates and validates the query of a question like Ask, without running it.
+// It is the sql_only mode of chat messages, the progress stops at StageQueryGenerated.
+func (srv *WebSocketServer) AskSQL(ctx context.Context, userID uuid.UUID, dbData protocol.StartPayload, question string, progress func(stage string)) (protocol.SQLResult, error) {
+	s, err := srv.askSession(ctx, userID, dbData, question, progress)
+	if err != nil {
+		return protocol.SQLResult{}, err
+	}
+	defer srv.pools.Release(s.connID)
+
+	result, err := srv.draft(userID, s, question, progress)
+	if err != nil {
+		return protocol.SQLResult{}, err
+	}
+
+	return result, nil
+}
+
+// askSession opens the session of a question asked outside of a WebSocket connection,
+// its pool has to be released once the question is answered.
+func (srv *WebSocketServer) askSession(ctx context.Context, userID uuid.UUID, dbData protocol.StartPayload, question string, progress func(stage string)) (*session, *protocol.Error) {
+	if question == "" {
+		return nil, &protocol.Error{Code: protocol.ErrInvalidPayload, Message: "question cannot be empty"}
+	}
+
+	// the quota is checked first, there is no point connecting to the database when it is exceeded
+	if err := srv.checkQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	s, err := srv.openSession(ctx, userID, dbData)
+	if err != nil {
+		return nil, err
+	}
+
+	if progress != nil {
+		progress(StageConnected)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,69 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gentcod/nlp-to-sql/chat/protocol"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver resolves one connection of an organization to the fake database.
+type fakeResolver struct {
+	id             uuid.UUID
+	organizationID uuid.UUID
+}
+
+func (resolver fakeResolver) ResolveConnection(ctx context.Context, userID, connectionID uuid.UUID) (ConnectionInfo, error) {
+	if connectionID != resolver.id {
+		return ConnectionInfo{}, ErrConnectionNotFound
+	}
+	return ConnectionInfo{
+		ID:             resolver.id,
+		OrganizationID: resolver.organizationID,
+		DBType:         testDBType,
+		DBName:         testDBName,
+		DBUrl:          testDBUrl,
+	}, nil
+}
+
+func TestAsk(t *testing.T) {
+	srv, _ := newTestServer(t)
+	resolver := fakeResolver{id: uuid.New()}
+	srv.resolver = resolver
+
+	var stages []string
+	result, err := srv.Ask(context.Background(), uuid.New(), protocol.StartPayload{ConnectionID: resolver.id.String()}, "0s", func(stage string) {
+		stages = append(stages, stage)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0s", result.Answer)
+	require.Equal(t, []string{StageConnected, StageQueryGenerated, StageDataFetched}, stages)
+	require.Equal(t, 1, poolClients(srv))
+
+	_, err = srv.Ask(context.Background(), uuid.New(), protocol.StartPayload{ConnectionID: uuid.NewString()}, "0s", nil)
+	var chatErr *protocol.Error
+	require.ErrorAs(t, err, &chatErr)
+	require.Equal(t, protocol.ErrConnectionNotFound, chatErr.Code)
+
+	_, err = srv.Ask(context.Background(), uuid.New(), protocol.StartPayload{ConnectionID: resolver.id.String()}, "", nil)
+	require.ErrorAs(t, err, &chatErr)
+	require.Equal(t, protocol.ErrInvalidPayload, chatErr.Code)
+}
+
+func TestAskSQL(t *testing.T) {
+	srv, _ := newTestServer(t)
+	resolver := fakeResolver{id: uuid.New()}
+	srv.resolver = resolver
+
+	var stages []string
+	result, err := srv.AskSQL(context.Background(), uuid.New(), protocol.StartPayload{ConnectionID: resolver.id.String()}, "SELECT id FROM orders", func(stage string) {
+		stages = append(stages, stage)
+	})
+	require.NoError(t, err)
+	require.True(t, result.Valid)
+	require.Equal(t, []string{"orders"}, result.Tables)
+	require.Equal(t, []string{StageConnected, StageQueryGenerated}, stages)
+	require.Equal(t, 1, poolClients(srv))
+}
@@ -1,168 +1,449 @@
 package chat
 
 import (
-	"database/sql"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"time"
+	"strings"
+	"sync"
+	"sync/atomic"
 
-	conv "github.com/gentcod/nlp-to-sql/converter"
-	mp "github.com/gentcod/nlp-to-sql/mapper"
+	"github.com/gentcod/nlp-to-sql/chat/protocol"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
 // Client represents a connected WebSocket client
 type Client struct {
-	conn      *websocket.Conn
-	converter conv.Converter
-	dbConn    *sql.DB
-	dbType    string
-	dbName    string
-	dbSchema  map[string]map[string]string
-	send      chan Response
-	receive   chan Message
-	close     chan struct{}
-	isAlive   bool
-}
-
-func (c *Client) handleDBConn(msg Message) {
-	var dbData struct {
-		DbType string `json:"db_type"`
-		DbName string `json:"db_name"`
-		DbUrl  string `json:"db_url"`
-	}
-
-	if len(msg.Payload) == 0 {
-		c.send <- Response{
-			Type:      "start_response",
-			Status:    "error",
-			Message:   `{"message": "xinvalid payload length"}`,
-			Timestamp: time.Now(),
+	*pipeline
+
+	conn    *websocket.Conn
+	userID  uuid.UUID
+	send    chan protocol.Response
+	receive chan protocol.Message
+
+	// close is closed when the connection is closed, every pump then returns.
+	close    chan struct{}
+	stopOnce sync.Once
+	// drain is closed when the server shuts down, the client then stops handling messages,
+	// and drained is closed once the responses of the messages it handled are sent.
+	drain     chan struct{}
+	drainOnce sync.Once
+	drained   chan struct{}
+	// handoff is held by the read pump while it hands a message to the processing pump, which takes it once it
+	// drains, so that the messages received after it rejected the received messages are rejected by the read pump.
+	handoff chan struct{}
+	// chats tracks the chat messages being answered.
+	chats sync.WaitGroup
+
+	// version is the negotiated protocol version, it is read by the write pump to encode responses.
+	version atomic.Int32
+	// workspace is the workspace connection of the session, it is read to disconnect the clients of a connection.
+	workspace atomic.Pointer[workspaceConnection]
+
+	// confirmations are the queries waiting for a confirmation, they are accessed by the chat messages being handled.
+	confirmMutex  sync.Mutex
+	confirmations map[string]*confirmation
+
+	// The fields below are only accessed by the processing pump.
+
+	// session is the database the client chats with, it is nil until a start succeeded.
+	session *session
+	// greeted is set once the client sent its first message, a hello is only accepted as the first message.
+	greeted bool
+	// unordered delivers chat responses as soon as they are ready rather than in the order of the messages.
+	unordered bool
+
+	// inFlight limits the chat messages handled at once, the processing pump stops reading messages when it is full.
+	inFlight chan struct{}
+	// pending queues the responses of ordered delivery in the order of their messages.
+	pending chan chan protocol.Response
+}
+
+// request is a message being handled, its response is sent to out.
+type request struct {
+	msg protocol.Message
+	out chan<- protocol.Response
+}
+
+// dispatch handles a message of the client. Chat, confirm_query and execute_sql messages are handled concurrently,
+// up to the in-flight limit, other messages are handled in order by the processing pump.
+func (c *Client) dispatch(msg protocol.Message) {
+	first := !c.greeted
+	c.greeted = true
+
+	req := request{msg: msg, out: c.send}
+
+	if msg.Type == protocol.TypeChat || msg.Type == protocol.TypeConfirmQuery || msg.Type == protocol.TypeExecuteSQL {
+		if c.unordered && msg.ID == "" {
+			c.fail(req, responseType(msg.Type), protocol.ErrInvalidMessage, fmt.Sprintf("%v messages require an id with unordered delivery", msg.Type))
+			return
+		}
+
+		// wait for a chat message to finish when the limit is reached
+		select {
+		case c.inFlight <- struct{}{}:
+		case <-c.close:
+			return
 		}
+
+		if !c.unordered && !c.enqueue(&req) {
+			<-c.inFlight
+			return
+		}
+
+		s := c.session
+		if s != nil && !c.pools.Retain(s.connID) {
+			s = nil
+		}
+
+		c.chats.Add(1)
+		go func() {
+			defer func() {
+				if s != nil {
+					c.pools.Release(s.connID)
+				}
+				<-c.inFlight
+				c.chats.Done()
+			}()
+
+			switch msg.Type {
+			case protocol.TypeConfirmQuery:
+				c.handleConfirmQuery(req)
+			case protocol.TypeExecuteSQL:
+				c.handleExecuteSQL(req, s)
+			default:
+				c.handleChat(req, s)
+			}
+		}()
 		return
 	}
 
-	if err := json.Unmarshal(msg.Payload, &dbData); err != nil {
-		fmt.Printf("Error unmarshalling payload: %v\n", err)
+	if !c.unordered && !c.enqueue(&req) {
+		return
+	}
 
-		c.send <- Response{
-			Type:      "start_response",
-			Status:    "error",
-			Message:   (fmt.Sprintf(`possible required missing fields, ensure the correct payload is sent containing: db_type, db_name and db_url, %v`, err)),
-			Timestamp: time.Now(),
+	switch msg.Type {
+	case protocol.TypeHello:
+		if !first {
+			c.fail(req, protocol.TypeWelcome, protocol.ErrInvalidMessage, "hello must be the first message")
+			return
 		}
+		c.handleHello(req)
+	case protocol.TypeStart:
+		c.handleDBConn(req)
+	default:
+		c.fail(req, protocol.TypeError, protocol.ErrUnknownType, fmt.Sprintf("unknown message type: %v", msg.Type))
+	}
+}
+
+// enqueue queues the response of an ordered request for delivery, it returns false when the client is closed.
+func (c *Client) enqueue(req *request) bool {
+	out := make(chan protocol.Response, 1)
+	req.out = out
+
+	select {
+	case c.pending <- out:
+		return true
+	case <-c.close:
+		return false
+	}
+}
+
+// releaseSession releases the pool of the session, the processing pump calls it when the client disconnects.
+func (c *Client) releaseSession() {
+	if c.session != nil {
+		c.pools.Release(c.session.connID)
+		c.session = nil
+		c.workspace.Store(nil)
+	}
+}
+
+// handleHello negotiates the protocol version, clients that do not send a hello use version 1.
+func (c *Client) handleHello(req request) {
+	var hello protocol.HelloPayload
+	if err := json.Unmarshal(req.msg.Payload, &hello); err != nil {
+		c.fail(req, protocol.TypeWelcome, protocol.ErrInvalidPayload, fmt.Sprintf("invalid hello payload: %v", err))
 		return
 	}
 
-	if dbData.DbType == "" || dbData.DbName == "" || dbData.DbUrl == "" {
-		err := errors.New("database connection field(s) cannot be empty")
-		c.send <- Response{
-			Type:      "start_response",
-			Status:    "error",
-			Message:   fmt.Sprintf(`database connection error. %v`, err),
-			Timestamp: time.Now(),
-		}
+	if hello.Delivery != "" && hello.Delivery != protocol.DeliveryOrdered && hello.Delivery != protocol.DeliveryUnordered {
+		c.fail(req, protocol.TypeWelcome, protocol.ErrInvalidPayload,
+			fmt.Sprintf("invalid delivery: %v, expected one of: %v, %v", hello.Delivery, protocol.DeliveryOrdered, protocol.DeliveryUnordered))
+		return
+	}
+
+	version, ok := protocol.Negotiate(hello.Versions)
+	if !ok {
+		c.fail(req, protocol.TypeWelcome, protocol.ErrUnsupportedVersion,
+			fmt.Sprintf("none of the protocol versions %v are supported, supported versions are %v", hello.Versions, protocol.Versions))
 		return
 	}
 
-	conn, err := sql.Open(dbData.DbType, dbData.DbUrl)
+	delivery := protocol.DeliveryOrdered
+	if hello.Delivery == protocol.DeliveryUnordered {
+		delivery = protocol.DeliveryUnordered
+	}
+
+	c.version.Store(int32(version))
+	c.respond(req, protocol.TypeWelcome, protocol.WelcomePayload{
+		Version:     version,
+		Versions:    protocol.Versions,
+		Delivery:    delivery,
+		MaxInFlight: cap(c.inFlight),
+	})
+
+	// the welcome is delivered in order, later responses are not
+	c.unordered = delivery == protocol.DeliveryUnordered
+}
+
+func (c *Client) handleDBConn(req request) {
+	var dbData protocol.StartPayload
+
+	if len(req.msg.Payload) == 0 {
+		c.fail(req, protocol.TypeStartResponse, protocol.ErrInvalidPayload, "invalid payload length")
+		return
+	}
+
+	if err := json.Unmarshal(req.msg.Payload, &dbData); err != nil {
+		c.fail(req, protocol.TypeStartResponse, protocol.ErrInvalidPayload,
+			fmt.Sprintf(`possible required missing fields, ensure the correct payload is sent containing: db_type, db_name and db_url, %v`, err))
+		return
+	}
+
+	s, err := c.openSession(context.Background(), c.userID, dbData)
 	if err != nil {
-		c.send <- Response{
-			Type:      "start_response",
-			Status:    "error",
-			Message:   fmt.Sprintf(`fialed to establish database connection. %v`, err),
-			Timestamp: time.Now(),
+		c.reply(req, protocol.NewErrorResponse(req.msg.ID, protocol.TypeStartResponse, err))
+		return
+	}
+
+	// release the database of a previous start, the client chats with one database at a time
+	c.releaseSession()
+	c.session = s
+	c.workspace.Store(s.workspace)
+
+	c.respond(req, protocol.TypeStartResponse, protocol.StartResult{DBName: dbData.DBName})
+}
+
+// handleChat answers a question about the database of the session s, which is nil when no start succeeded.
+func (c *Client) handleChat(req request, s *session) {
+	if s == nil {
+		c.fail(req, protocol.TypeChatResponse, protocol.ErrNotStarted,
+			"database connection error. database chat has not been initialized")
+		return
+	}
+
+	var chatReq protocol.ChatPayload
+
+	if err := json.Unmarshal(req.msg.Payload, &chatReq); err != nil {
+		c.fail(req, protocol.TypeChatResponse, protocol.ErrInvalidPayload,
+			fmt.Sprintf(`possible required missing fields, ensure the correct payload is sent. %v`, err))
+		return
+	}
+
+	if chatReq.Question == "" {
+		c.fail(req, protocol.TypeChatResponse, protocol.ErrInvalidPayload, "question cannot be empty")
+		return
+	}
+
+	if chatReq.Mode != "" && chatReq.Mode != protocol.ModeAnswer && chatReq.Mode != protocol.ModeSQLOnly {
+		c.fail(req, protocol.TypeChatResponse, protocol.ErrInvalidPayload,
+			fmt.Sprintf(`invalid mode: %v, expected one of: %v, %v`, chatReq.Mode, protocol.ModeAnswer, protocol.ModeSQLOnly))
+		return
+	}
+
+	if !c.checkLimits(req, protocol.TypeChatResponse) {
+		return
+	}
+
+	if chatReq.Mode == protocol.ModeSQLOnly {
+		result, err := c.draft(c.userID, s, chatReq.Question, nil)
+		if err != nil {
+			c.reply(req, protocol.NewErrorResponse(req.msg.ID, protocol.TypeChatResponse, err))
+			return
 		}
+		c.respond(req, protocol.TypeChatResponse, result)
 		return
 	}
 
-	mapper := mp.InitMapper(dbData.DbType)
-	c.dbSchema, err = mapper.MapSchema(conn, dbData.DbName)
+	// version 1 responses cannot carry a confirmation, so expensive queries are refused
+	result, err := c.answer(c.userID, s, answerParams{
+		question: chatReq.Question,
+		refuse:   c.version.Load() == protocol.Version1,
+	})
 	if err != nil {
-		c.send <- Response{
-			Type:      "start_response",
-			Status:    "error",
-			Message:   fmt.Sprintf(`fialed to get database context. %v`, err),
-			Timestamp: time.Now(),
+		if err.Code == protocol.ErrConfirmationRequired {
+			c.addConfirmation(s, chatReq.Question, false, err.Confirmation)
 		}
+		c.reply(req, protocol.NewErrorResponse(req.msg.ID, protocol.TypeChatResponse, err))
 		return
 	}
 
-	c.dbConn = conn
-	c.dbType = dbData.DbType
-	c.dbName = dbData.DbName
+	c.respond(req, protocol.TypeChatResponse, result)
+}
 
-	response := Response{
-		Type:      "start_response",
-		Status:    "success",
-		Message:   fmt.Sprintf(`successfully connected to: %v`, c.dbName),
-		Timestamp: time.Now(),
+// handleExecuteSQL runs a query provided by the user against the database of the session s, which is nil
+// when no start succeeded. The query is validated, estimated and capped like generated queries.
+func (c *Client) handleExecuteSQL(req request, s *session) {
+	if s == nil {
+		c.fail(req, protocol.TypeExecuteSQLResponse, protocol.ErrNotStarted,
+			"database connection error. database chat has not been initialized")
+		return
+	}
+
+	var execReq protocol.ExecuteSQLPayload
+
+	if err := json.Unmarshal(req.msg.Payload, &execReq); err != nil {
+		c.fail(req, protocol.TypeExecuteSQLResponse, protocol.ErrInvalidPayload,
+			fmt.Sprintf(`possible required missing fields, ensure the correct payload is sent. %v`, err))
+		return
+	}
+
+	if strings.TrimSpace(execReq.Query) == "" {
+		c.fail(req, protocol.TypeExecuteSQLResponse, protocol.ErrInvalidPayload, "query cannot be empty")
+		return
+	}
+
+	if !c.checkLimits(req, protocol.TypeExecuteSQLResponse) {
+		return
 	}
-	c.send <- response
-}
 
-func (c *Client) handleChat(msg Message) {
-	if c.dbConn == nil || c.dbType == "" || c.dbName == "" {
-		err := errors.New("database chat has not been initialized")
-		c.send <- Response{
-			Type:      "chat_response",
-			Status:    "error",
-			Message:   fmt.Sprintf(`database connection error. %v`, err),
-			Timestamp: time.Now(),
+	result, err := c.execute(c.userID, s, answerParams{
+		question: execReq.Question,
+		query:    execReq.Query,
+		refuse:   c.version.Load() == protocol.Version1,
+	})
+	if err != nil {
+		if err.Code == protocol.ErrConfirmationRequired {
+			c.addConfirmation(s, execReq.Question, true, err.Confirmation)
 		}
+		c.reply(req, protocol.NewErrorResponse(req.msg.ID, protocol.TypeExecuteSQLResponse, err))
 		return
 	}
 
-	var chatReq struct {
-		Question string `json:"question"`
+	c.respond(req, protocol.TypeExecuteSQLResponse, result)
+}
+
+// handleConfirmQuery runs a query that required a confirmation, against the database it was estimated for.
+func (c *Client) handleConfirmQuery(req request) {
+	var confirmReq protocol.ConfirmQueryPayload
+
+	if err := json.Unmarshal(req.msg.Payload, &confirmReq); err != nil || confirmReq.Token == "" {
+		c.fail(req, protocol.TypeConfirmQueryResponse, protocol.ErrInvalidPayload, "the token of the confirmation is required")
+		return
+	}
+
+	conf, ok := c.takeConfirmation(confirmReq.Token)
+	if !ok {
+		c.fail(req, protocol.TypeConfirmQueryResponse, protocol.ErrConfirmationNotFound,
+			"the confirmation does not exist or has expired, ask the question again")
+		return
+	}
+	defer c.pools.Release(conf.session.connID)
+
+	if !c.checkLimits(req, protocol.TypeConfirmQueryResponse) {
+		return
 	}
 
-	if err := json.Unmarshal(msg.Payload, &chatReq); err != nil {
-		fmt.Printf("Error unmarshalling payload: %v\n", err)
+	arg := answerParams{
+		question:  conf.question,
+		query:     conf.query,
+		confirmed: true,
+	}
 
-		c.send <- Response{
-			Type:      "start_response",
-			Status:    "error",
-			Message:   (fmt.Sprintf(`possible required missing fields, ensure the correct payload is sent. %V`, err)),
-			Timestamp: time.Now(),
+	if conf.execute {
+		result, err := c.execute(c.userID, conf.session, arg)
+		if err != nil {
+			c.reply(req, protocol.NewErrorResponse(req.msg.ID, protocol.TypeConfirmQueryResponse, err))
+			return
 		}
+		c.respond(req, protocol.TypeConfirmQueryResponse, result)
 		return
 	}
 
-	resp, err := c.converter.Convert(
-		c.dbConn,
-		"llama",
-		chatReq.Question,
-		c.dbSchema,
-	)
+	result, err := c.answer(c.userID, conf.session, arg)
+	if err != nil {
+		c.reply(req, protocol.NewErrorResponse(req.msg.ID, protocol.TypeConfirmQueryResponse, err))
+		return
+	}
+
+	c.respond(req, protocol.TypeConfirmQueryResponse, result)
+}
+
+// checkLimits sends a rate_limited or quota_exceeded error response and returns false when the user
+// has exceeded their rate limit or monthly LLM token quota.
+func (c *Client) checkLimits(req request, responseType string) bool {
+	err := c.checkRate(context.Background(), c.userID)
+	if err == nil {
+		err = c.checkQuota(context.Background(), c.userID)
+	}
+	if err == nil {
+		return true
+	}
+
+	c.reply(req, protocol.NewErrorResponse(req.msg.ID, responseType, err))
+	return false
+}
 
+// respond sends a successful response to the request.
+func (c *Client) respond(req request, responseType string, payload any) {
+	resp, err := protocol.NewResponse(req.msg.ID, responseType, payload)
 	if err != nil {
-		c.send <- Response{
-			Type:      "chat_response",
-			Status:    "error",
-			Message:   fmt.Sprintf(`converter error: %v`, err),
-			Timestamp: time.Now(),
-		}
+		c.fail(req, responseType, protocol.ErrInternal, err.Error())
 		return
 	}
+	c.reply(req, resp)
+}
 
-	response := Response{
-		Type:      "chat_response",
-		Status:    "success",
-		Message:   resp,
-		Timestamp: time.Now(),
+// fail sends a failed response to the request.
+func (c *Client) fail(req request, responseType, code, message string) {
+	c.reply(req, protocol.NewErrorResponse(req.msg.ID, responseType, &protocol.Error{
+		Code:    code,
+		Message: message,
+	}))
+}
+
+// reply sends the response of a request, it is dropped when the client disconnected.
+func (c *Client) reply(req request, resp protocol.Response) {
+	select {
+	case req.out <- resp:
+	case <-c.close:
 	}
-	c.send <- response
 }
 
-func (c *Client) handleUnknownMessage(msg Message) {
-	response := Response{
-		Type:      "unknown",
-		Status:    "error",
-		Message:   fmt.Sprintf(`unknown message type: %v`, msg.Type),
-		Timestamp: time.Now(),
+// deliveryPump sends the responses of ordered requests in the order of their messages,
+// it closes drained once the processing pump closed pending and every response is sent.
+func (c *Client) deliveryPump() {
+	for {
+		select {
+		case out, ok := <-c.pending:
+			if !ok {
+				close(c.drained)
+				return
+			}
+
+			select {
+			case resp := <-out:
+				select {
+				case c.send <- resp:
+				case <-c.close:
+					return
+				}
+			case <-c.close:
+				return
+			}
+
+		case <-c.close:
+			return
+		}
 	}
-	c.send <- response
+}
+
+// connectionID identifies a database connection without exposing its connection string.
+func connectionID(dbType, dbName, dbUrl string) string {
+	sum := sha256.Sum256([]byte(dbType + "\x00" + dbName + "\x00" + dbUrl))
+	return hex.EncodeToString(sum[:16])
 }
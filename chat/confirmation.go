@@ -0,0 +1,101 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/gentcod/nlp-to-sql/chat/protocol"
+	"github.com/google/uuid"
+)
+
+const (
+	// confirmationTTL is how long a query waits for a confirmation.
+	confirmationTTL = 5 * time.Minute
+	// maxConfirmations is the number of queries a client can have waiting for a confirmation,
+	// the oldest one is dropped when another query requires a confirmation.
+	maxConfirmations = 8
+)
+
+// confirmation is a query waiting for a confirmation, it retains the pool of its session until it is
+// confirmed or dropped so that it runs against the database it was estimated for.
+// Execute is set for queries of execute_sql messages, whose confirmation responds with their result.
+type confirmation struct {
+	session   *session
+	question  string
+	query     string
+	execute   bool
+	expiresAt time.Time
+}
+
+// addConfirmation keeps the query of a confirmation required by a chat or execute_sql message handled with the session s,
+// and sets the token and expiry of the confirmation.
+func (c *Client) addConfirmation(s *session, question string, execute bool, conf *protocol.Confirmation) {
+	// the session is retained by the chat message being answered
+	if !c.pools.Retain(s.connID) {
+		return
+	}
+
+	conf.Token = uuid.NewString()
+	conf.ExpiresAt = time.Now().Add(confirmationTTL)
+
+	c.confirmMutex.Lock()
+	defer c.confirmMutex.Unlock()
+
+	if c.confirmations == nil {
+		c.confirmations = make(map[string]*confirmation)
+	}
+	c.pruneConfirmationsLocked(time.Now())
+
+	if len(c.confirmations) >= maxConfirmations {
+		var oldest string
+		for token, pending := range c.confirmations {
+			if oldest == "" || pending.expiresAt.Before(c.confirmations[oldest].expiresAt) {
+				oldest = token
+			}
+		}
+		c.pools.Release(c.confirmations[oldest].session.connID)
+		delete(c.confirmations, oldest)
+	}
+
+	c.confirmations[conf.Token] = &confirmation{
+		session:   s,
+		question:  question,
+		query:     conf.Query,
+		execute:   execute,
+		expiresAt: conf.ExpiresAt,
+	}
+}
+
+// takeConfirmation removes the confirmation of a token, it returns false when it does not exist or has expired.
+// The pool of the session of the confirmation has to be released once its query is run.
+func (c *Client) takeConfirmation(token string) (*confirmation, bool) {
+	c.confirmMutex.Lock()
+	defer c.confirmMutex.Unlock()
+
+	c.pruneConfirmationsLocked(time.Now())
+
+	conf, ok := c.confirmations[token]
+	if ok {
+		delete(c.confirmations, token)
+	}
+	return conf, ok
+}
+
+// releaseConfirmations drops every confirmation, the processing pump calls it when the client disconnects.
+func (c *Client) releaseConfirmations() {
+	c.confirmMutex.Lock()
+	defer c.confirmMutex.Unlock()
+
+	for token, conf := range c.confirmations {
+		c.pools.Release(conf.session.connID)
+		delete(c.confirmations, token)
+	}
+}
+
+func (c *Client) pruneConfirmationsLocked(now time.Time) {
+	for token, conf := range c.confirmations {
+		if now.After(conf.expiresAt) {
+			c.pools.Release(conf.session.connID)
+			delete(c.confirmations, token)
+		}
+	}
+}
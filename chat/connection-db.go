@@ -0,0 +1,78 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/google/uuid"
+)
+
+// connectionURLPurpose separates the key that encrypts stored connection strings from the token key it is derived from.
+const connectionURLPurpose = "connection-url"
+
+// DBConnectionResolver is a ConnectionResolver backed by the application database.
+type DBConnectionResolver struct {
+	store db.Store
+	key   string
+}
+
+// NewDBConnectionResolver initializes a ConnectionResolver of the workspace connections stored in the application database,
+// their connection strings are decrypted with key.
+func NewDBConnectionResolver(store db.Store, key string) ConnectionResolver {
+	return &DBConnectionResolver{
+		store: store,
+		key:   key,
+	}
+}
+
+func (resolver *DBConnectionResolver) ResolveConnection(ctx context.Context, userID, connectionID uuid.UUID) (ConnectionInfo, error) {
+	conn, err := resolver.store.GetConnection(ctx, connectionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ConnectionInfo{}, ErrConnectionNotFound
+		}
+		return ConnectionInfo{}, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	_, err = resolver.store.GetOrganizationMember(ctx, db.GetOrganizationMemberParams{
+		OrganizationID: conn.OrganizationID,
+		AuthID:         userID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ConnectionInfo{}, ErrConnectionNotFound
+		}
+		return ConnectionInfo{}, fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	dbUrl, err := util.DecryptSecret(resolver.key, connectionURLPurpose, conn.DbUrl)
+	if err != nil {
+		return ConnectionInfo{}, err
+	}
+
+	info := ConnectionInfo{
+		ID:             conn.ID,
+		OrganizationID: conn.OrganizationID,
+		DBType:         conn.DbType,
+		DBName:         conn.DbName,
+		DBUrl:          dbUrl,
+		CostAction:     conn.CostAction.String,
+	}
+	if conn.MaxEstimatedRows.Valid {
+		info.MaxEstimatedRows = &conn.MaxEstimatedRows.Int64
+	}
+	if conn.MaxEstimatedCost.Valid {
+		info.MaxEstimatedCost = &conn.MaxEstimatedCost.Float64
+	}
+
+	return info, nil
+}
+
+// EncryptConnectionURL encrypts the connection string of a workspace connection for storage,
+// it can then be resolved by a DBConnectionResolver initialized with the same key.
+func EncryptConnectionURL(key, dbUrl string) (string, error) {
+	return util.EncryptSecret(key, connectionURLPurpose, dbUrl)
+}
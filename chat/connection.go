@@ -0,0 +1,32 @@
+package chat
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrConnectionNotFound is returned when a connection does not exist or belongs to a workspace the user is not a member of,
+// the two are not distinguished so that connections of other workspaces cannot be discovered.
+var ErrConnectionNotFound = errors.New("connection not found")
+
+// ConnectionInfo contains the details required to connect to a workspace database.
+// MaxEstimatedRows, MaxEstimatedCost and CostAction override the cost guard of the server when they are set.
+type ConnectionInfo struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	DBType         string
+	DBName         string
+	DBUrl          string
+
+	MaxEstimatedRows *int64
+	MaxEstimatedCost *float64
+	CostAction       string
+}
+
+// ConnectionResolver resolves the workspace connections a user can chat with.
+type ConnectionResolver interface {
+	// ResolveConnection returns the connection when the user is a member of its workspace.
+	ResolveConnection(ctx context.Context, userID, connectionID uuid.UUID) (ConnectionInfo, error)
+}
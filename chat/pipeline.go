@@ -0,0 +1,365 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/gentcod/nlp-to-sql/chat/protocol"
+	conv "github.com/gentcod/nlp-to-sql/converter"
+	"github.com/gentcod/nlp-to-sql/egress"
+	"github.com/gentcod/nlp-to-sql/limiter"
+	mp "github.com/gentcod/nlp-to-sql/mapper"
+	"github.com/google/uuid"
+)
+
+// Actions of the cost guard when the estimated cost of a query exceeds the limits of a connection.
+const (
+	// CostActionConfirm asks the user to confirm the query, it is the default.
+	CostActionConfirm = "confirm"
+	// CostActionRefuse refuses to run the query.
+	CostActionRefuse = "refuse"
+)
+
+// pipeline answers questions about databases, it is shared by the WebSocket clients and Ask.
+type pipeline struct {
+	converter conv.Converter
+	limiter   *limiter.Limiter
+	resolver  ConnectionResolver
+	pools     *PoolManager
+	// costGuard applies to connections that do not override it.
+	costGuard costGuard
+	// caps apply to every query run, generated or provided by users.
+	caps conv.QueryCaps
+}
+
+// costGuard decides what happens to queries whose estimated cost exceeds the limits of a connection.
+type costGuard struct {
+	limits conv.CostLimits
+	action string
+}
+
+// answerParams is a question answered by the pipeline. Query is run instead of generating a query when it is set,
+// and the cost guard is skipped for confirmed queries. Expensive queries are refused rather than awaiting
+// a confirmation when refuse is set, for clients that cannot confirm them. Progress is passed to the converter, it can be nil.
+type answerParams struct {
+	question  string
+	query     string
+	confirmed bool
+	refuse    bool
+	progress  func(stage string)
+}
+
+// session is the database a client chats with. Chat messages keep the session they were received in,
+// and retain its pool until they are handled, so that a new start does not affect them.
+type session struct {
+	conn       *sql.DB
+	connID     string
+	dbType     string
+	dbName     string
+	schema     map[string]map[string]string
+	answerMode string
+	costGuard  costGuard
+	// workspace is the workspace connection of the session, it is nil for other connections.
+	workspace *workspaceConnection
+}
+
+// workspaceConnection identifies a workspace connection and its organization.
+type workspaceConnection struct {
+	id             uuid.UUID
+	organizationID uuid.UUID
+}
+
+// openSession connects to the database described by a start payload and maps its schema.
+// The pool of the session is acquired, it has to be released once the session is no longer used.
+func (p *pipeline) openSession(ctx context.Context, userID uuid.UUID, dbData protocol.StartPayload) (*session, *protocol.Error) {
+	guard := p.costGuard
+	var workspace *workspaceConnection
+	if dbData.ConnectionID != "" {
+		info, err := p.resolveConnection(ctx, userID, dbData.ConnectionID)
+		if err != nil {
+			code := protocol.ErrInternal
+			if errors.Is(err, ErrConnectionNotFound) {
+				code = protocol.ErrConnectionNotFound
+			}
+			return nil, &protocol.Error{Code: code, Message: fmt.Sprintf(`database connection error. %v`, err)}
+		}
+
+		dbData.DBType, dbData.DBName, dbData.DBUrl = info.DBType, info.DBName, info.DBUrl
+		workspace = &workspaceConnection{id: info.ID, organizationID: info.OrganizationID}
+
+		if info.MaxEstimatedRows != nil {
+			guard.limits.MaxRows = *info.MaxEstimatedRows
+		}
+		if info.MaxEstimatedCost != nil {
+			guard.limits.MaxCost = *info.MaxEstimatedCost
+		}
+		if info.CostAction != "" {
+			guard.action = info.CostAction
+		}
+	}
+
+	if dbData.DBType == "" || dbData.DBName == "" || dbData.DBUrl == "" {
+		return nil, &protocol.Error{
+			Code:    protocol.ErrInvalidPayload,
+			Message: "database connection error. database connection field(s) cannot be empty",
+		}
+	}
+
+	if dbData.AnswerMode == "" {
+		dbData.AnswerMode = conv.AnswerModeLLM
+	}
+
+	if dbData.AnswerMode != conv.AnswerModeLLM && dbData.AnswerMode != conv.AnswerModeDeterministic {
+		return nil, &protocol.Error{
+			Code:    protocol.ErrInvalidPayload,
+			Message: fmt.Sprintf(`invalid answer_mode: %v, expected one of: %v, %v`, dbData.AnswerMode, conv.AnswerModeLLM, conv.AnswerModeDeterministic),
+		}
+	}
+
+	connID := connectionID(dbData.DBType, dbData.DBName, dbData.DBUrl)
+	conn, err := p.pools.Acquire(ctx, connID, dbData.DBType, dbData.DBUrl)
+	if err != nil {
+		code := protocol.ErrConnectionFailed
+		if errors.Is(err, egress.ErrDenied) || errors.Is(err, egress.ErrUnsupportedDriver) {
+			code = protocol.ErrConnectionDenied
+		}
+		return nil, &protocol.Error{Code: code, Message: fmt.Sprintf(`fialed to establish database connection. %v`, err)}
+	}
+
+	mapper := mp.InitMapper(dbData.DBType)
+	schema, err := mapper.MapSchema(conn, dbData.DBName)
+	if err != nil {
+		p.pools.Release(connID)
+		return nil, &protocol.Error{Code: protocol.ErrConnectionFailed, Message: fmt.Sprintf(`fialed to get database context. %v`, err)}
+	}
+
+	return &session{
+		conn:       conn,
+		connID:     connID,
+		dbType:     dbData.DBType,
+		dbName:     dbData.DBName,
+		schema:     schema,
+		answerMode: dbData.AnswerMode,
+		costGuard:  guard,
+		workspace:  workspace,
+	}, nil
+}
+
+// resolveConnection resolves a workspace connection of the user, ErrConnectionNotFound is returned
+// unless the user is a member of the workspace of the connection.
+func (p *pipeline) resolveConnection(ctx context.Context, userID uuid.UUID, connectionID string) (ConnectionInfo, error) {
+	id, err := uuid.Parse(connectionID)
+	if err != nil {
+		return ConnectionInfo{}, ErrConnectionNotFound
+	}
+
+	info, err := p.resolver.ResolveConnection(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, ErrConnectionNotFound) {
+			return ConnectionInfo{}, err
+		}
+		log.Printf("Error resolving connection %v: %v", id, err)
+		return ConnectionInfo{}, errors.New("failed to resolve connection")
+	}
+
+	return info, nil
+}
+
+// checkRate returns a rate_limited error when the user has exceeded their rate limit.
+func (p *pipeline) checkRate(ctx context.Context, userID uuid.UUID) *protocol.Error {
+	allowed, retryAfter, err := p.limiter.Allow(ctx, userID)
+	if err == nil && !allowed {
+		err = limiter.ErrRateLimited
+	}
+	return limitError(err, retryAfter)
+}
+
+// checkQuota returns a quota_exceeded error when the user has used their monthly LLM token quota.
+func (p *pipeline) checkQuota(ctx context.Context, userID uuid.UUID) *protocol.Error {
+	err := p.limiter.CheckQuota(ctx, userID)
+	// quotas are reset at the start of the next month
+	return limitError(err, time.Until(limiter.Period(time.Now()).AddDate(0, 1, 0)))
+}
+
+// limitError converts an error of the limiter, retryAfter is how long to wait before retrying when a limit is exceeded.
+func limitError(err error, retryAfter time.Duration) *protocol.Error {
+	if err == nil {
+		return nil
+	}
+
+	respErr := &protocol.Error{
+		Code:    protocol.ErrInternal,
+		Message: fmt.Sprintf(`limiter error: %v`, err),
+	}
+	if errors.Is(err, limiter.ErrRateLimited) || errors.Is(err, limiter.ErrQuotaExceeded) {
+		respErr.Code = protocol.ErrRateLimited
+		if errors.Is(err, limiter.ErrQuotaExceeded) {
+			respErr.Code = protocol.ErrQuotaExceeded
+		}
+		respErr.Message = err.Error()
+		respErr.RetryAfter = int(math.Ceil(retryAfter.Seconds()))
+	}
+
+	return respErr
+}
+
+// convert converts a question against the database of the session, or runs the query of arg,
+// and records the LLM tokens used. Queries exceeding the limits of the cost guard of the session fail
+// with ErrConfirmationRequired and a Confirmation without a token, or with ErrQueryTooExpensive when the guard refuses them.
+func (p *pipeline) convert(userID uuid.UUID, s *session, arg answerParams) (conv.Result, *protocol.Error) {
+	params := conv.ConvertParams{
+		UserID:     userID,
+		Conn:       s.conn,
+		ConnID:     s.connID,
+		DBType:     s.dbType,
+		LLMType:    "llama",
+		Question:   arg.question,
+		Schema:     s.schema,
+		AnswerMode: s.answerMode,
+		Progress:   arg.progress,
+		Query:      arg.query,
+		Caps:       p.caps,
+	}
+	if !arg.confirmed {
+		params.CostLimits = s.costGuard.limits
+	}
+
+	resp, err := p.converter.Convert(params)
+
+	if err := p.limiter.RecordUsage(context.Background(), userID, resp.Usage); err != nil {
+		log.Printf("Error recording LLM usage: %v", err)
+	}
+
+	if err != nil {
+		var costErr *conv.CostError
+		if errors.As(err, &costErr) {
+			action := s.costGuard.action
+			if arg.refuse {
+				action = CostActionRefuse
+			}
+			return conv.Result{}, costError(costErr, action)
+		}
+
+		code := protocol.ErrConversionFailed
+		if errors.Is(err, conv.ErrQueryPolicy) {
+			code = protocol.ErrQueryRejected
+		}
+		return conv.Result{}, &protocol.Error{Code: code, Message: fmt.Sprintf(`converter error: %v`, err)}
+	}
+
+	if arg.question != "" && !resp.Grounded {
+		log.Printf("Ungrounded chat response, values not found in queried data: %v", resp.Ungrounded)
+	}
+
+	return resp, nil
+}
+
+// answer answers a question against the database of the session, see convert.
+func (p *pipeline) answer(userID uuid.UUID, s *session, arg answerParams) (protocol.ChatResult, *protocol.Error) {
+	resp, err := p.convert(userID, s, arg)
+	if err != nil {
+		return protocol.ChatResult{}, err
+	}
+
+	return protocol.ChatResult{
+		Answer:    resp.Response,
+		Grounded:  resp.Grounded,
+		Truncated: resp.Truncated,
+		Cache: protocol.CacheStatus{
+			Query:  resp.QueryCached,
+			Result: resp.ResultCached,
+		},
+	}, nil
+}
+
+// execute runs a query provided by the user against the database of the session, see convert.
+// Its result is summarised when the question of arg is set.
+func (p *pipeline) execute(userID uuid.UUID, s *session, arg answerParams) (protocol.ExecuteSQLResult, *protocol.Error) {
+	resp, err := p.convert(userID, s, arg)
+	if err != nil {
+		return protocol.ExecuteSQLResult{}, err
+	}
+
+	result := protocol.ExecuteSQLResult{
+		Query:     resp.Query,
+		Rows:      resp.Data,
+		Truncated: resp.Truncated,
+		Cache: protocol.CacheStatus{
+			Result: resp.ResultCached,
+		},
+	}
+	if arg.question != "" {
+		result.Answer = resp.Response
+		result.Grounded = &resp.Grounded
+	}
+	if result.Rows == nil {
+		result.Rows = []map[string]any{}
+	}
+
+	return result, nil
+}
+
+// draft generates the query of a question against the database of the session without running it,
+// and records the LLM tokens used. Queries failing validation are returned with their diagnostics.
+func (p *pipeline) draft(userID uuid.UUID, s *session, question string, progress func(stage string)) (protocol.SQLResult, *protocol.Error) {
+	draft, err := p.converter.DraftQuery(conv.ConvertParams{
+		UserID:   userID,
+		Conn:     s.conn,
+		ConnID:   s.connID,
+		DBType:   s.dbType,
+		LLMType:  "llama",
+		Question: question,
+		Schema:   s.schema,
+		Progress: progress,
+	})
+
+	if err := p.limiter.RecordUsage(context.Background(), userID, draft.Usage); err != nil {
+		log.Printf("Error recording LLM usage: %v", err)
+	}
+
+	if err != nil {
+		return protocol.SQLResult{}, &protocol.Error{Code: protocol.ErrConversionFailed, Message: fmt.Sprintf(`converter error: %v`, err)}
+	}
+
+	return sqlResult(draft), nil
+}
+
+// sqlResult converts a draft to the payload of a response, lists are never null.
+func sqlResult(draft conv.Draft) protocol.SQLResult {
+	result := protocol.SQLResult{
+		Query:       draft.Query,
+		Valid:       draft.Analysis.Valid,
+		Diagnostics: draft.Analysis.Diagnostics,
+		Tables:      draft.Analysis.Tables,
+		Columns:     draft.Analysis.Columns,
+		Cached:      draft.QueryCached,
+	}
+	if result.Diagnostics == nil {
+		result.Diagnostics = []string{}
+	}
+	return result
+}
+
+// costError converts a CostError according to the action of the cost guard.
+func costError(err *conv.CostError, action string) *protocol.Error {
+	if action == CostActionRefuse {
+		return &protocol.Error{Code: protocol.ErrQueryTooExpensive, Message: err.Error()}
+	}
+
+	return &protocol.Error{
+		Code:    protocol.ErrConfirmationRequired,
+		Message: err.Error() + ", confirm it to run it anyway",
+		Confirmation: &protocol.Confirmation{
+			Query:         err.Query,
+			EstimatedRows: err.Estimate.Rows,
+			EstimatedCost: err.Estimate.Cost,
+			MaxRows:       err.Limits.MaxRows,
+			MaxCost:       err.Limits.MaxCost,
+		},
+	}
+}
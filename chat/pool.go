@@ -0,0 +1,278 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gentcod/nlp-to-sql/egress"
+)
+
+const (
+	// healthCheckTimeout bounds how long a health check waits for a database to respond.
+	healthCheckTimeout = 5 * time.Second
+
+	// customer databases are shared with their other clients, so pools are capped by default.
+	defaultPoolMaxOpenConns        = 10
+	defaultPoolConnMaxIdleTime     = 5 * time.Minute
+	defaultPoolHealthCheckInterval = time.Minute
+)
+
+// PoolOpts configures the database pools of a PoolManager, zero values keep the defaults of database/sql.
+// Pools are health checked every HealthCheckInterval, health checks are disabled when it is zero.
+type PoolOpts struct {
+	MaxOpenConns        int
+	MaxIdleConns        int
+	ConnMaxIdleTime     time.Duration
+	ConnMaxLifetime     time.Duration
+	HealthCheckInterval time.Duration
+}
+
+// PoolStats reports the state of a database pool.
+type PoolStats struct {
+	ID        string    `json:"id"`
+	DBType    string    `json:"db_type"`
+	Clients   int       `json:"clients"`
+	Healthy   bool      `json:"healthy"`
+	LastError string    `json:"last_error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	CreatedAt time.Time `json:"created_at"`
+
+	OpenConnections int           `json:"open_connections"`
+	InUse           int           `json:"in_use"`
+	Idle            int           `json:"idle"`
+	WaitCount       int64         `json:"wait_count"`
+	WaitDuration    time.Duration `json:"wait_duration"`
+}
+
+type pool struct {
+	db        *sql.DB
+	dbType    string
+	refs      int
+	healthy   bool
+	lastError string
+	checkedAt time.Time
+	createdAt time.Time
+}
+
+// PoolManager shares a database pool between the clients connected to the same database.
+// Pools are reference counted and closed when their last client releases them.
+type PoolManager struct {
+	// open opens a database, it is the Open of the egress policy unless replaced in tests.
+	open  func(ctx context.Context, dbType, dsn string) (*sql.DB, error)
+	opts  PoolOpts
+	mutex sync.Mutex
+	pools map[string]*pool
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewPoolManager initializes a PoolManager opening databases allowed by the policy,
+// and starts health checking its pools.
+func NewPoolManager(policy *egress.Policy, opts PoolOpts) *PoolManager {
+	manager := &PoolManager{
+		open:  policy.Open,
+		opts:  opts,
+		pools: make(map[string]*pool),
+		done:  make(chan struct{}),
+	}
+
+	if opts.HealthCheckInterval > 0 {
+		go manager.runHealthChecks()
+	}
+
+	return manager
+}
+
+// Acquire returns the pool of the database identified by id, opening it when it is not open.
+// Every successful Acquire has to be followed by a Release of the id.
+func (manager *PoolManager) Acquire(ctx context.Context, id, dbType, dsn string) (*sql.DB, error) {
+	manager.mutex.Lock()
+	if p, ok := manager.pools[id]; ok {
+		p.refs++
+		manager.mutex.Unlock()
+
+		// a shared pool may have lost its database since it was opened
+		pingCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := manager.check(pingCtx, id, p)
+		cancel()
+		if err != nil {
+			manager.Release(id)
+			return nil, err
+		}
+		return p.db, nil
+	}
+	manager.mutex.Unlock()
+
+	// databases are opened without holding the lock, connecting can take a while
+	conn, err := manager.open(ctx, dbType, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetMaxOpenConns(manager.opts.MaxOpenConns)
+	if manager.opts.MaxIdleConns > 0 {
+		conn.SetMaxIdleConns(manager.opts.MaxIdleConns)
+	}
+	conn.SetConnMaxIdleTime(manager.opts.ConnMaxIdleTime)
+	conn.SetConnMaxLifetime(manager.opts.ConnMaxLifetime)
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	// another client may have opened the database in the meantime
+	if p, ok := manager.pools[id]; ok {
+		conn.Close()
+		p.refs++
+		return p.db, nil
+	}
+
+	now := time.Now()
+	manager.pools[id] = &pool{
+		db:        conn,
+		dbType:    dbType,
+		refs:      1,
+		healthy:   true,
+		checkedAt: now,
+		createdAt: now,
+	}
+
+	return conn, nil
+}
+
+// Retain acquires the open pool identified by id again, e.g. for the duration of a request,
+// it returns false when the pool is not open.
+func (manager *PoolManager) Retain(id string) bool {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	p, ok := manager.pools[id]
+	if ok {
+		p.refs++
+	}
+	return ok
+}
+
+// Release releases a pool acquired with id, closing it when no other client uses it.
+func (manager *PoolManager) Release(id string) {
+	manager.mutex.Lock()
+	p, ok := manager.pools[id]
+	if !ok {
+		manager.mutex.Unlock()
+		return
+	}
+
+	p.refs--
+	if p.refs > 0 {
+		manager.mutex.Unlock()
+		return
+	}
+	delete(manager.pools, id)
+	manager.mutex.Unlock()
+
+	if err := p.db.Close(); err != nil {
+		log.Printf("Error closing database pool %v: %v", id, err)
+	}
+}
+
+// Stats reports the state of the open pools.
+func (manager *PoolManager) Stats() []PoolStats {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	stats := make([]PoolStats, 0, len(manager.pools))
+	for id, p := range manager.pools {
+		dbStats := p.db.Stats()
+		stats = append(stats, PoolStats{
+			ID:              id,
+			DBType:          p.dbType,
+			Clients:         p.refs,
+			Healthy:         p.healthy,
+			LastError:       p.lastError,
+			CheckedAt:       p.checkedAt,
+			CreatedAt:       p.createdAt,
+			OpenConnections: dbStats.OpenConnections,
+			InUse:           dbStats.InUse,
+			Idle:            dbStats.Idle,
+			WaitCount:       dbStats.WaitCount,
+			WaitDuration:    dbStats.WaitDuration,
+		})
+	}
+
+	return stats
+}
+
+// Close stops the health checks and closes every pool, whether or not it is still acquired.
+func (manager *PoolManager) Close() {
+	manager.once.Do(func() {
+		close(manager.done)
+	})
+
+	manager.mutex.Lock()
+	pools := manager.pools
+	manager.pools = make(map[string]*pool)
+	manager.mutex.Unlock()
+
+	for id, p := range pools {
+		if err := p.db.Close(); err != nil {
+			log.Printf("Error closing database pool %v: %v", id, err)
+		}
+	}
+}
+
+func (manager *PoolManager) runHealthChecks() {
+	ticker := time.NewTicker(manager.opts.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			manager.checkPools()
+		case <-manager.done:
+			return
+		}
+	}
+}
+
+// checkPools pings every open pool, logging the pools that become unhealthy.
+func (manager *PoolManager) checkPools() {
+	manager.mutex.Lock()
+	pools := make(map[string]*pool, len(manager.pools))
+	for id, p := range manager.pools {
+		pools[id] = p
+	}
+	manager.mutex.Unlock()
+
+	for id, p := range pools {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		err := manager.check(ctx, id, p)
+		cancel()
+		if err != nil {
+			log.Printf("Database pool %v is unhealthy: %v", id, err)
+		}
+	}
+}
+
+// check pings the database of a pool and records its health,
+// pools released while they are checked are ignored.
+func (manager *PoolManager) check(ctx context.Context, id string, p *pool) error {
+	err := p.db.PingContext(ctx)
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	if manager.pools[id] != p {
+		return nil
+	}
+
+	p.healthy = err == nil
+	p.lastError = ""
+	if err != nil {
+		p.lastError = err.Error()
+	}
+	p.checkedAt = time.Now()
+
+	return err
+}
@@ -0,0 +1,199 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errDatabaseDown = errors.New("database is down")
+
+// pingConnector connects to a database whose pings fail while it is down.
+type pingConnector struct {
+	down *atomic.Bool
+}
+
+func (connector pingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return pingConn{connector}, nil
+}
+
+func (pingConnector) Driver() driver.Driver { return nil }
+
+type pingConn struct {
+	connector pingConnector
+}
+
+func (pingConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (pingConn) Close() error                              { return nil }
+func (pingConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (conn pingConn) Ping(ctx context.Context) error {
+	if conn.connector.down.Load() {
+		return errDatabaseDown
+	}
+	return nil
+}
+
+// fakeOpener opens pingConnector databases and records them.
+type fakeOpener struct {
+	down   atomic.Bool
+	mutex  sync.Mutex
+	opened []*sql.DB
+	// wait, when set, is waited for before a database is opened.
+	wait func()
+}
+
+func (opener *fakeOpener) open(ctx context.Context, dbType, dsn string) (*sql.DB, error) {
+	if opener.wait != nil {
+		opener.wait()
+	}
+
+	conn := sql.OpenDB(pingConnector{down: &opener.down})
+
+	opener.mutex.Lock()
+	opener.opened = append(opener.opened, conn)
+	opener.mutex.Unlock()
+
+	return conn, nil
+}
+
+func newTestPoolManager(opts PoolOpts) (*PoolManager, *fakeOpener) {
+	opener := &fakeOpener{}
+	manager := NewPoolManager(nil, opts)
+	manager.open = opener.open
+	return manager, opener
+}
+
+func poolRefs(manager *PoolManager, id string) int {
+	for _, stats := range manager.Stats() {
+		if stats.ID == id {
+			return stats.Clients
+		}
+	}
+	return 0
+}
+
+func closed(conn *sql.DB) bool {
+	return conn.Ping() != nil && conn.Ping().Error() == "sql: database is closed"
+}
+
+func TestPoolRefCounting(t *testing.T) {
+	manager, opener := newTestPoolManager(PoolOpts{})
+	defer manager.Close()
+
+	conn, err := manager.Acquire(context.Background(), "a", testDBType, testDBUrl)
+	require.NoError(t, err)
+
+	// clients of the same database share its pool
+	shared, err := manager.Acquire(context.Background(), "a", testDBType, testDBUrl)
+	require.NoError(t, err)
+	require.Same(t, conn, shared)
+	require.True(t, manager.Retain("a"))
+	require.Equal(t, 3, poolRefs(manager, "a"))
+	require.Len(t, opener.opened, 1)
+
+	// other databases have their own pool
+	other, err := manager.Acquire(context.Background(), "b", testDBType, testDBUrl)
+	require.NoError(t, err)
+	require.NotSame(t, conn, other)
+
+	// pools are closed once their last reference is released
+	manager.Release("a")
+	manager.Release("a")
+	require.Equal(t, 1, poolRefs(manager, "a"))
+	require.False(t, closed(conn))
+
+	manager.Release("a")
+	require.Zero(t, poolRefs(manager, "a"))
+	require.True(t, closed(conn))
+	require.False(t, manager.Retain("a"))
+	require.False(t, closed(other))
+
+	// releasing a closed pool does nothing
+	manager.Release("a")
+	require.Equal(t, 1, poolRefs(manager, "b"))
+}
+
+func TestPoolConcurrentAcquire(t *testing.T) {
+	manager, opener := newTestPoolManager(PoolOpts{})
+	defer manager.Close()
+
+	// every client opens the database before any of them stores its pool
+	const clients = 10
+	var opening sync.WaitGroup
+	opening.Add(clients)
+	opener.wait = func() {
+		opening.Done()
+		opening.Wait()
+	}
+
+	conns := make([]*sql.DB, clients)
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conn, err := manager.Acquire(context.Background(), "a", testDBType, testDBUrl)
+			require.NoError(t, err)
+			conns[i] = conn
+		}(i)
+	}
+	wg.Wait()
+
+	// one pool is kept and the databases opened by the other clients are closed
+	for _, conn := range conns {
+		require.Same(t, conns[0], conn)
+	}
+	require.Equal(t, clients, poolRefs(manager, "a"))
+	require.Len(t, opener.opened, clients)
+	for _, conn := range opener.opened {
+		require.Equal(t, conn != conns[0], closed(conn))
+	}
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	manager, opener := newTestPoolManager(PoolOpts{})
+	defer manager.Close()
+
+	_, err := manager.Acquire(context.Background(), "a", testDBType, testDBUrl)
+	require.NoError(t, err)
+
+	opener.down.Store(true)
+	manager.checkPools()
+	stats := manager.Stats()
+	require.Len(t, stats, 1)
+	require.False(t, stats[0].Healthy)
+	require.Equal(t, errDatabaseDown.Error(), stats[0].LastError)
+
+	// shared pools are pinged, clients do not acquire a pool that lost its database
+	_, err = manager.Acquire(context.Background(), "a", testDBType, testDBUrl)
+	require.ErrorIs(t, err, errDatabaseDown)
+	require.Equal(t, 1, poolRefs(manager, "a"))
+
+	opener.down.Store(false)
+	manager.checkPools()
+	stats = manager.Stats()
+	require.True(t, stats[0].Healthy)
+	require.Empty(t, stats[0].LastError)
+}
+
+func TestPoolHealthCheckInterval(t *testing.T) {
+	manager, opener := newTestPoolManager(PoolOpts{HealthCheckInterval: 10 * time.Millisecond})
+	defer manager.Close()
+
+	_, err := manager.Acquire(context.Background(), "a", testDBType, testDBUrl)
+	require.NoError(t, err)
+
+	opener.down.Store(true)
+	require.Eventually(t, func() bool {
+		stats := manager.Stats()
+		return len(stats) == 1 && !stats[0].Healthy
+	}, 5*time.Second, 10*time.Millisecond)
+}
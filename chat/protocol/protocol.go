@@ -0,0 +1,278 @@
+// Package protocol defines the messages of the chat WebSocket, clients can import it to encode and decode them.
+//
+// A client starts by sending a hello message with the protocol versions it supports, the server
+// replies with a welcome message containing the negotiated version. Every response echoes the id
+// of the message it responds to, and failed responses carry an Error with a machine-readable code.
+// Clients that do not send a hello message use version 1, the legacy format of chat.Response.
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// Version1 is the legacy format, responses carry a free-text message rather than a payload.
+	Version1 = 1
+	// Version2 echoes message IDs and carries typed payloads and error codes.
+	Version2 = 2
+)
+
+// Versions are the protocol versions supported by the server.
+var Versions = []int{Version1, Version2}
+
+// Message types sent by clients.
+const (
+	TypeHello = "hello"
+	TypeStart = "start"
+	TypeChat  = "chat"
+	// TypeConfirmQuery runs a query that required a confirmation because of its estimated cost.
+	TypeConfirmQuery = "confirm_query"
+	// TypeExecuteSQL runs a query written or edited by the user, with the same policy as generated queries.
+	TypeExecuteSQL = "execute_sql"
+)
+
+// Response types sent by the server.
+const (
+	TypeWelcome       = "welcome"
+	TypeStartResponse = "start_response"
+	TypeChatResponse  = "chat_response"
+	// TypeConfirmQueryResponse has the payload of the response of the confirmed message.
+	TypeConfirmQueryResponse = "confirm_query_response"
+	TypeExecuteSQLResponse   = "execute_sql_response"
+	// TypeError responds to messages that cannot be handled, such as invalid JSON or unknown types.
+	TypeError = "error"
+)
+
+// Response statuses.
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Error codes.
+const (
+	ErrInvalidMessage     = "invalid_message"
+	ErrInvalidPayload     = "invalid_payload"
+	ErrUnknownType        = "unknown_type"
+	ErrUnsupportedVersion = "unsupported_version"
+	ErrNotStarted         = "not_started"
+	ErrConnectionNotFound = "connection_not_found"
+	ErrConnectionDenied   = "connection_denied"
+	ErrConnectionFailed   = "connection_failed"
+	ErrRateLimited        = "rate_limited"
+	ErrQuotaExceeded      = "quota_exceeded"
+	ErrQueryRejected      = "query_rejected"
+	ErrConversionFailed   = "conversion_failed"
+	// ErrConfirmationRequired is returned with a Confirmation when the estimated cost of a query exceeds
+	// the limits of the connection, the query is run once it is confirmed with a confirm_query message.
+	ErrConfirmationRequired = "confirmation_required"
+	ErrConfirmationNotFound = "confirmation_not_found"
+	// ErrQueryTooExpensive is returned when the estimated cost of a query exceeds the limits of a connection
+	// that refuses expensive queries.
+	ErrQueryTooExpensive = "query_too_expensive"
+	ErrShuttingDown      = "shutting_down"
+	ErrInternal          = "internal"
+)
+
+// Message is a message sent by a client. ID is chosen by the client to correlate responses, it is optional.
+type Message struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Response is a message sent by the server, Payload is set on success and Error on failure.
+type Response struct {
+	ID        string          `json:"id,omitempty"`
+	Type      string          `json:"type"`
+	Status    string          `json:"status"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Error     *Error          `json:"error,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Error describes why a message failed. RetryAfter is the number of seconds to wait before retrying
+// when the rate limit or LLM token quota is exceeded, and Confirmation is set with ErrConfirmationRequired.
+type Error struct {
+	Code         string        `json:"code"`
+	Message      string        `json:"message"`
+	RetryAfter   int           `json:"retry_after,omitempty"`
+	Confirmation *Confirmation `json:"confirmation,omitempty"`
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%v: %v", err.Code, err.Message)
+}
+
+// Delivery modes of chat responses.
+const (
+	// DeliveryOrdered sends responses in the order of their messages, it is the default.
+	DeliveryOrdered = "ordered"
+	// DeliveryUnordered sends chat responses as soon as they are ready, chat messages then require an ID.
+	DeliveryUnordered = "unordered"
+)
+
+// HelloPayload is the payload of a hello message, Delivery is optional.
+type HelloPayload struct {
+	Versions []int  `json:"versions"`
+	Delivery string `json:"delivery,omitempty"`
+}
+
+// WelcomePayload is the payload of a welcome response, Versions are the versions supported by the server.
+// MaxInFlight is the number of chat messages handled at once, further messages are not read until one of them is answered.
+type WelcomePayload struct {
+	Version     int    `json:"version"`
+	Versions    []int  `json:"versions"`
+	Delivery    string `json:"delivery"`
+	MaxInFlight int    `json:"max_in_flight"`
+}
+
+// StartPayload is the payload of a start message. It contains either the ConnectionID of a workspace connection,
+// or the DBType, DBName and DBUrl of a database to connect to directly. AnswerMode is optional.
+type StartPayload struct {
+	ConnectionID string `json:"connection_id,omitempty"`
+	DBType       string `json:"db_type,omitempty"`
+	DBName       string `json:"db_name,omitempty"`
+	DBUrl        string `json:"db_url,omitempty"`
+	AnswerMode   string `json:"answer_mode,omitempty"`
+}
+
+// StartResult is the payload of a successful start response.
+type StartResult struct {
+	DBName string `json:"db_name"`
+}
+
+// Modes of chat messages.
+const (
+	// ModeAnswer runs the generated query and answers the question with its result, it is the default.
+	ModeAnswer = "answer"
+	// ModeSQLOnly only generates and validates the query, the chat response then has an SQLResult payload.
+	ModeSQLOnly = "sql_only"
+)
+
+// ChatPayload is the payload of a chat message, Mode is optional.
+type ChatPayload struct {
+	Question string `json:"question"`
+	Mode     string `json:"mode,omitempty"`
+}
+
+// ChatResult is the payload of a successful chat response. Truncated reports whether the answer only
+// summarises the first rows of the result of the query, which has more rows than the server reads.
+type ChatResult struct {
+	Answer    string      `json:"answer"`
+	Grounded  bool        `json:"grounded"`
+	Truncated bool        `json:"truncated"`
+	Cache     CacheStatus `json:"cache"`
+}
+
+// SQLResult is the payload of a successful chat response in the sql_only mode. Valid reports whether the query
+// would be run, Diagnostics explain why it would be rejected otherwise. Tables and Columns are what the query touches,
+// columns are qualified with their table ("table.column") when it can be resolved from the query.
+type SQLResult struct {
+	Query       string   `json:"query"`
+	Valid       bool     `json:"valid"`
+	Diagnostics []string `json:"diagnostics"`
+	Tables      []string `json:"tables"`
+	Columns     []string `json:"columns"`
+	Cached      bool     `json:"cached"`
+}
+
+// ExecuteSQLPayload is the payload of an execute_sql message. The result of the Query is summarised
+// to answer the Question when it is set, e.g. the question the query was generated for.
+type ExecuteSQLPayload struct {
+	Query    string `json:"query"`
+	Question string `json:"question,omitempty"`
+}
+
+// ExecuteSQLResult is the payload of a successful execute_sql response. Rows are the result of the query,
+// Truncated reports whether the result has more rows than the server returns. Answer and Grounded are set
+// when the result was summarised.
+type ExecuteSQLResult struct {
+	Query     string           `json:"query"`
+	Rows      []map[string]any `json:"rows"`
+	Truncated bool             `json:"truncated"`
+	Answer    string           `json:"answer,omitempty"`
+	Grounded  *bool            `json:"grounded,omitempty"`
+	Cache     CacheStatus      `json:"cache"`
+}
+
+// Confirmation describes a query whose estimated cost exceeds the limits of the connection.
+// The Token confirms the query in a confirm_query message until ExpiresAt, it can only be used once.
+// Limits that are zero are disabled.
+type Confirmation struct {
+	Token         string    `json:"token"`
+	Query         string    `json:"query"`
+	EstimatedRows float64   `json:"estimated_rows"`
+	EstimatedCost float64   `json:"estimated_cost"`
+	MaxRows       int64     `json:"max_rows,omitempty"`
+	MaxCost       float64   `json:"max_cost,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// ConfirmQueryPayload is the payload of a confirm_query message.
+type ConfirmQueryPayload struct {
+	Token string `json:"token"`
+}
+
+// CacheStatus reports whether a chat response was served from the cache.
+type CacheStatus struct {
+	Query  bool `json:"query"`
+	Result bool `json:"result"`
+}
+
+// Negotiate returns the highest version supported by both the client and the server.
+func Negotiate(versions []int) (int, bool) {
+	negotiated := 0
+	for _, version := range versions {
+		if version > negotiated && supported(version) {
+			negotiated = version
+		}
+	}
+	return negotiated, negotiated != 0
+}
+
+func supported(version int) bool {
+	for _, v := range Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// NewResponse returns a successful response to the message with id.
+func NewResponse(id, responseType string, payload any) (Response, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	return Response{
+		ID:        id,
+		Type:      responseType,
+		Status:    StatusSuccess,
+		Payload:   data,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// NewErrorResponse returns a failed response to the message with id.
+func NewErrorResponse(id, responseType string, err *Error) Response {
+	return Response{
+		ID:        id,
+		Type:      responseType,
+		Status:    StatusError,
+		Error:     err,
+		Timestamp: time.Now(),
+	}
+}
+
+// DecodePayload decodes the payload of a successful response, e.g. into a ChatResult for a chat response.
+func (resp Response) DecodePayload(v any) error {
+	if resp.Error != nil {
+		return resp.Error
+	}
+	return json.Unmarshal(resp.Payload, v)
+}
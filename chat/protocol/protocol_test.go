@@ -0,0 +1,53 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	version, ok := Negotiate([]int{Version1, Version2, 99})
+	require.True(t, ok)
+	require.Equal(t, Version2, version)
+
+	version, ok = Negotiate([]int{Version1})
+	require.True(t, ok)
+	require.Equal(t, Version1, version)
+
+	_, ok = Negotiate([]int{0, 99})
+	require.False(t, ok)
+	_, ok = Negotiate(nil)
+	require.False(t, ok)
+}
+
+func TestResponse(t *testing.T) {
+	resp, err := NewResponse("42", TypeChatResponse, ChatResult{Answer: "We have 114 accounts.", Grounded: true})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+
+	var decoded Response
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, "42", decoded.ID)
+	require.Equal(t, StatusSuccess, decoded.Status)
+	require.Nil(t, decoded.Error)
+
+	var result ChatResult
+	require.NoError(t, decoded.DecodePayload(&result))
+	require.Equal(t, "We have 114 accounts.", result.Answer)
+	require.True(t, result.Grounded)
+
+	failed := NewErrorResponse("43", TypeChatResponse, &Error{Code: ErrRateLimited, Message: "rate limit exceeded", RetryAfter: 3})
+	data, err = json.Marshal(failed)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "payload")
+
+	var decodedFailed Response
+	require.NoError(t, json.Unmarshal(data, &decodedFailed))
+	require.Error(t, decodedFailed.DecodePayload(&result))
+	require.Equal(t, ErrRateLimited, decodedFailed.Error.Code)
+	require.Equal(t, 3, decodedFailed.Error.RetryAfter)
+}
@@ -1,46 +1,172 @@
 package chat
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/gentcod/nlp-to-sql/chat/protocol"
 	conv "github.com/gentcod/nlp-to-sql/converter"
+	"github.com/gentcod/nlp-to-sql/egress"
+	"github.com/gentcod/nlp-to-sql/limiter"
 	"github.com/gentcod/nlp-to-sql/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
 	"github.com/gorilla/websocket"
 )
 
-// Message defines the format for incoming message from client.
-type Message struct {
-	Type      string          `json:"type"`
-	Payload   json.RawMessage `json:"payload"`
-	Timestamp time.Time       `json:"timestamp"`
-}
+const (
+	// defaultMaxInFlight is the default number of chat messages a client can have handled at once.
+	defaultMaxInFlight = 4
+	// defaultQueryMaxRows is the default number of rows of the result of a query that are read.
+	defaultQueryMaxRows = 1000
+	// defaultQueryTimeout is how long a query can run by default.
+	defaultQueryTimeout = 30 * time.Second
+
+	// writeWait is how long writing a message to a client can take.
+	writeWait = 10 * time.Second
+	// pongWait is how long a client can take to respond to a ping, pingPeriod has to be shorter.
+	pongWait   = 60 * time.Second
+	pingPeriod = 50 * time.Second
+)
 
-// Response defines the server response format.
+// Response is the legacy format of responses of protocol version 1, used by clients that do not negotiate a version.
+// Code is an HTTP-style status code of errors such as 429 when the rate limit or LLM token quota is exceeded,
+// RetryAfter is then the number of seconds to wait before retrying.
+// Data is the result set of execute_sql responses, whose message is the answer or else the query.
 type Response struct {
-	Type      string    `json:"type"`
-	Status    string    `json:"status"`
-	Message   string    `json:"message"`
-	Timestamp time.Time `json:"timestamp"`
+	Type       string           `json:"type"`
+	Status     string           `json:"status"`
+	Code       int              `json:"code,omitempty"`
+	RetryAfter int              `json:"retry_after,omitempty"`
+	Message    string           `json:"message"`
+	Data       []map[string]any `json:"data,omitempty"`
+	Grounded   *bool            `json:"grounded,omitempty"`
+	Cache      *CacheStatus     `json:"cache,omitempty"`
+	Timestamp  time.Time        `json:"timestamp"`
+}
+
+// CacheStatus reports whether a chat response was served from the cache.
+type CacheStatus = protocol.CacheStatus
+
+// legacyResponse converts a response to the format of protocol version 1.
+func legacyResponse(resp protocol.Response) Response {
+	legacy := Response{
+		Type:      resp.Type,
+		Status:    resp.Status,
+		Timestamp: resp.Timestamp,
+	}
+	if resp.Type == protocol.TypeError {
+		legacy.Type = "unknown"
+	}
+
+	if resp.Error != nil {
+		legacy.Message = resp.Error.Message
+		legacy.RetryAfter = resp.Error.RetryAfter
+		switch resp.Error.Code {
+		case protocol.ErrRateLimited, protocol.ErrQuotaExceeded:
+			legacy.Code = http.StatusTooManyRequests
+		case protocol.ErrInternal:
+			legacy.Code = http.StatusInternalServerError
+		}
+		return legacy
+	}
+
+	switch resp.Type {
+	case protocol.TypeStartResponse:
+		var result protocol.StartResult
+		if err := json.Unmarshal(resp.Payload, &result); err == nil {
+			legacy.Message = fmt.Sprintf(`successfully connected to: %v`, result.DBName)
+		}
+	case protocol.TypeChatResponse:
+		// the query of sql_only chat responses is the message
+		var sqlResult protocol.SQLResult
+		if err := json.Unmarshal(resp.Payload, &sqlResult); err == nil && sqlResult.Query != "" {
+			legacy.Message = sqlResult.Query
+			break
+		}
+
+		legacyChatResult(&legacy, resp.Payload)
+	case protocol.TypeExecuteSQLResponse, protocol.TypeConfirmQueryResponse:
+		// confirmations respond with the result of an execute_sql or a chat message, only the former has a query
+		var result protocol.ExecuteSQLResult
+		if err := json.Unmarshal(resp.Payload, &result); err != nil || result.Query == "" {
+			legacyChatResult(&legacy, resp.Payload)
+			break
+		}
+
+		legacy.Message = result.Answer
+		if legacy.Message == "" {
+			legacy.Message = result.Query
+		}
+		legacy.Data = result.Rows
+		legacy.Grounded = result.Grounded
+		legacy.Cache = &result.Cache
+	default:
+		legacy.Message = string(resp.Payload)
+	}
+
+	return legacy
+}
+
+// legacyChatResult sets the answer of a chat result as the message of a legacy response.
+func legacyChatResult(legacy *Response, payload json.RawMessage) {
+	var result protocol.ChatResult
+	if err := json.Unmarshal(payload, &result); err == nil {
+		legacy.Message = result.Answer
+		legacy.Grounded = &result.Grounded
+		legacy.Cache = &result.Cache
+	}
 }
 
 // WebSocket server specifications.
 type WebSocketServer struct {
-	converter conv.Converter
-	upgrader  websocket.Upgrader
-	clients   map[*Client]bool
-	mutex     sync.RWMutex
+	*pipeline
+
+	// maxInFlight is the number of chat messages a client can have handled at once.
+	maxInFlight int
+	upgrader    websocket.Upgrader
+	clients     map[*Client]bool
+	mutex       sync.RWMutex
+	// pumps tracks the pumps of the clients, Shutdown waits for them to return.
+	pumps        sync.WaitGroup
+	shuttingDown bool
 }
 
 // NewWebSocketServer creates a new WebSocket server.
-func NewWebSocketServer(config util.Config, converter conv.Converter) (*WebSocketServer, error) {
+// The resolver resolves the workspace connections that clients start chats with,
+// and the policy restricts the databases they can connect to.
+func NewWebSocketServer(config util.Config, converter conv.Converter, limiter *limiter.Limiter, resolver ConnectionResolver, policy *egress.Policy) (*WebSocketServer, error) {
+	poolOpts, err := loadPoolOpts(config)
+	if err != nil {
+		return nil, err
+	}
+
+	guard, err := loadCostGuard(config)
+	if err != nil {
+		return nil, err
+	}
+
+	caps, err := loadQueryCaps(config)
+	if err != nil {
+		return nil, err
+	}
+
+	maxInFlight := defaultMaxInFlight
+	if config.ChatMaxInFlight != "" {
+		maxInFlight, err = strconv.Atoi(config.ChatMaxInFlight)
+		if err != nil || maxInFlight < 1 {
+			return nil, fmt.Errorf("invalid chat max in flight: %v", config.ChatMaxInFlight)
+		}
+	}
+
 	return &WebSocketServer{
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
@@ -49,13 +175,123 @@ func NewWebSocketServer(config util.Config, converter conv.Converter) (*WebSocke
 				return true
 			},
 		},
-		clients:   make(map[*Client]bool),
-		converter: converter,
+		clients: make(map[*Client]bool),
+		pipeline: &pipeline{
+			converter: converter,
+			limiter:   limiter,
+			resolver:  resolver,
+			pools:     NewPoolManager(policy, poolOpts),
+			costGuard: guard,
+			caps:      caps,
+		},
+		maxInFlight: maxInFlight,
 	}, nil
 }
 
-// handleConnection manages a new WebSocket connection
-func (srv *WebSocketServer) HandleConnection(c *gin.Context) {
+// loadPoolOpts returns the options of the database pools configured by the POOL_ variables,
+// options that are not set fall back to their defaults.
+func loadPoolOpts(config util.Config) (PoolOpts, error) {
+	opts := PoolOpts{
+		MaxOpenConns:        defaultPoolMaxOpenConns,
+		ConnMaxIdleTime:     config.PoolConnMaxIdleTime,
+		ConnMaxLifetime:     config.PoolConnMaxLifetime,
+		HealthCheckInterval: config.PoolHealthCheckInterval,
+	}
+
+	if config.PoolMaxOpenConns != "" {
+		maxOpenConns, err := strconv.Atoi(config.PoolMaxOpenConns)
+		if err != nil || maxOpenConns < 0 {
+			return opts, fmt.Errorf("invalid pool max open conns: %v", config.PoolMaxOpenConns)
+		}
+		opts.MaxOpenConns = maxOpenConns
+	}
+
+	if config.PoolMaxIdleConns != "" {
+		maxIdleConns, err := strconv.Atoi(config.PoolMaxIdleConns)
+		if err != nil || maxIdleConns < 0 {
+			return opts, fmt.Errorf("invalid pool max idle conns: %v", config.PoolMaxIdleConns)
+		}
+		opts.MaxIdleConns = maxIdleConns
+	}
+
+	if opts.ConnMaxIdleTime == 0 {
+		opts.ConnMaxIdleTime = defaultPoolConnMaxIdleTime
+	}
+	if opts.HealthCheckInterval == 0 {
+		opts.HealthCheckInterval = defaultPoolHealthCheckInterval
+	}
+
+	return opts, nil
+}
+
+// loadCostGuard returns the cost guard of connections configured by the QUERY_ variables,
+// queries are not estimated unless a limit is set.
+func loadCostGuard(config util.Config) (costGuard, error) {
+	guard := costGuard{action: CostActionConfirm}
+
+	if config.QueryMaxEstimatedRows != "" {
+		maxRows, err := strconv.ParseInt(config.QueryMaxEstimatedRows, 10, 64)
+		if err != nil || maxRows < 0 {
+			return guard, fmt.Errorf("invalid query max estimated rows: %v", config.QueryMaxEstimatedRows)
+		}
+		guard.limits.MaxRows = maxRows
+	}
+
+	if config.QueryMaxEstimatedCost != "" {
+		maxCost, err := strconv.ParseFloat(config.QueryMaxEstimatedCost, 64)
+		if err != nil || maxCost < 0 {
+			return guard, fmt.Errorf("invalid query max estimated cost: %v", config.QueryMaxEstimatedCost)
+		}
+		guard.limits.MaxCost = maxCost
+	}
+
+	switch config.QueryCostAction {
+	case "":
+	case CostActionConfirm, CostActionRefuse:
+		guard.action = config.QueryCostAction
+	default:
+		return guard, fmt.Errorf("invalid query cost action: %v, expected one of: %v, %v", config.QueryCostAction, CostActionConfirm, CostActionRefuse)
+	}
+
+	return guard, nil
+}
+
+// loadQueryCaps returns the caps of queries configured by the QUERY_ variables.
+func loadQueryCaps(config util.Config) (conv.QueryCaps, error) {
+	caps := conv.QueryCaps{
+		MaxRows: defaultQueryMaxRows,
+		Timeout: defaultQueryTimeout,
+	}
+
+	if config.QueryMaxRows != "" {
+		maxRows, err := strconv.Atoi(config.QueryMaxRows)
+		if err != nil || maxRows < 1 {
+			return caps, fmt.Errorf("invalid query max rows: %v", config.QueryMaxRows)
+		}
+		caps.MaxRows = maxRows
+	}
+
+	if config.QueryTimeout > 0 {
+		caps.Timeout = config.QueryTimeout
+	}
+
+	return caps, nil
+}
+
+// PoolStats reports the state of the database pools shared by the clients.
+func (srv *WebSocketServer) PoolStats() []PoolStats {
+	return srv.pools.Stats()
+}
+
+// HandleConnection manages a new WebSocket connection of an authenticated user.
+func (srv *WebSocketServer) HandleConnection(c *gin.Context, userID uuid.UUID) {
+	if srv.isShuttingDown() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "server is shutting down",
+		})
+		return
+	}
+
 	conn, err := srv.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -67,67 +303,173 @@ func (srv *WebSocketServer) HandleConnection(c *gin.Context) {
 
 	// Create a new client
 	client := &Client{
-		conn:      conn,
-		converter: srv.converter,
-		send:      make(chan Response, 256),
-		receive:   make(chan Message, 256),
-		close:     make(chan struct{}),
-		isAlive:   true,
+		pipeline: srv.pipeline,
+		conn:     conn,
+		userID:   userID,
+		send:     make(chan protocol.Response, 256),
+		receive:  make(chan protocol.Message, 256),
+		close:    make(chan struct{}),
+		drain:    make(chan struct{}),
+		drained:  make(chan struct{}),
+		handoff:  make(chan struct{}, 1),
+		inFlight: make(chan struct{}, srv.maxInFlight),
+		pending:  make(chan chan protocol.Response, srv.maxInFlight+1),
 	}
+	client.version.Store(protocol.Version1)
 
-	// Register client
+	// Register client, unless the server started shutting down during the upgrade
 	srv.mutex.Lock()
+	if srv.shuttingDown {
+		srv.mutex.Unlock()
+		msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+		conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+		conn.Close()
+		return
+	}
 	srv.clients[client] = true
+	srv.pumps.Add(4)
 	srv.mutex.Unlock()
 
 	// Start client communication loops
-	go client.readPump(srv)
-	go client.writePump()
-	go client.processingPump()
+	go func() {
+		defer srv.pumps.Done()
+		client.readPump(srv)
+	}()
+	go func() {
+		defer srv.pumps.Done()
+		client.writePump()
+	}()
+	go func() {
+		defer srv.pumps.Done()
+		client.processingPump()
+	}()
+	go func() {
+		defer srv.pumps.Done()
+		client.deliveryPump()
+	}()
 }
 
-// readPump handles incoming WebSocket messages.
-func (c *Client) readPump(srv *WebSocketServer) {
-	defer func() {
-		c.conn.Close()
-		if c.dbConn != nil {
-			c.dbConn.Close()
+// Shutdown stops accepting connections and gracefully closes the connected clients: they stop handling messages,
+// the messages being handled are answered and the clients are sent a close frame. When ctx is done first,
+// the remaining connections are closed and the error of ctx is returned. The database pools are closed in either case.
+func (srv *WebSocketServer) Shutdown(ctx context.Context) error {
+	srv.mutex.Lock()
+	srv.shuttingDown = true
+	clients := make([]*Client, 0, len(srv.clients))
+	for client := range srv.clients {
+		clients = append(clients, client)
+	}
+	srv.mutex.Unlock()
+
+	for _, client := range clients {
+		client.startDraining()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		srv.pumps.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		for _, client := range clients {
+			client.conn.Close()
 		}
-		c.isAlive = false
+	}
 
-		c.conn = nil
-		c.dbConn = nil
-		c.dbSchema = nil
-		close(c.close)
-		close(c.send)
-		close(c.receive)
+	srv.pools.Close()
+	return err
+}
+
+func (srv *WebSocketServer) isShuttingDown() bool {
+	srv.mutex.RLock()
+	defer srv.mutex.RUnlock()
+	return srv.shuttingDown
+}
+
+// DisconnectUser closes the open WebSocket connections of a user, e.g. when their account is restricted or deleted.
+// It returns the number of closed connections.
+func (srv *WebSocketServer) DisconnectUser(userID uuid.UUID) int {
+	return srv.disconnect("account access revoked", func(client *Client) bool {
+		return client.userID == userID
+	})
+}
+
+// DisconnectMember closes the open WebSocket connections of a user chatting with a connection of the organization,
+// e.g. when they are removed from it. It returns the number of closed connections.
+func (srv *WebSocketServer) DisconnectMember(organizationID, userID uuid.UUID) int {
+	return srv.disconnect("workspace access revoked", func(client *Client) bool {
+		workspace := client.workspace.Load()
+		return client.userID == userID && workspace != nil && workspace.organizationID == organizationID
+	})
+}
 
+// DisconnectConnection closes the open WebSocket connections chatting with a workspace connection,
+// e.g. when it is deleted. It returns the number of closed connections.
+func (srv *WebSocketServer) DisconnectConnection(connectionID uuid.UUID) int {
+	return srv.disconnect("connection deleted", func(client *Client) bool {
+		workspace := client.workspace.Load()
+		return workspace != nil && workspace.id == connectionID
+	})
+}
+
+// disconnect closes the open WebSocket connections of the clients matching match, with the reason.
+func (srv *WebSocketServer) disconnect(reason string, match func(client *Client) bool) int {
+	srv.mutex.RLock()
+	defer srv.mutex.RUnlock()
+
+	closed := 0
+	for client := range srv.clients {
+		if !match(client) {
+			continue
+		}
+
+		// closing the connection makes the read pump of the client return and clean up
+		msg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason)
+		client.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		client.conn.Close()
+		closed++
+	}
+
+	return closed
+}
+
+// readPump handles incoming WebSocket messages, it owns the receive channel.
+func (c *Client) readPump(srv *WebSocketServer) {
+	defer func() {
+		// unregister the client first, so that DisconnectUser never sees it while it is torn down
 		srv.mutex.Lock()
 		delete(srv.clients, c)
 		srv.mutex.Unlock()
+
+		c.conn.Close()
+		c.stop()
+		close(c.receive)
 	}()
 
 	// Set read deadline to detect disconnections
-	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
 		return nil
 	})
 
 	for {
-		var msg Message
-		msgType, reader, err := c.conn.NextReader()
+		var msg protocol.Message
+		_, reader, err := c.conn.NextReader()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err,
 				websocket.CloseGoingAway,
 				websocket.CloseAbnormalClosure,
 				websocket.CloseNormalClosure,
 			) {
-				c.isAlive = false
-				break
+				log.Printf("WebSocket read error: %v", err)
 			}
-			log.Printf("WebSocket read error: %v", err)
-			break
+			return
 		}
 
 		msgData, err := io.ReadAll(reader)
@@ -138,12 +480,10 @@ func (c *Client) readPump(srv *WebSocketServer) {
 
 		err = json.Unmarshal(msgData, &msg)
 		if err != nil {
-			errResponse := Response{
-				Type:      string(rune(msgType)),
-				Status:    "error",
-				Message:   fmt.Sprintf(`invalid message format: %v`, msg.Type),
-				Timestamp: time.Now(),
-			}
+			errResponse := protocol.NewErrorResponse("", protocol.TypeError, &protocol.Error{
+				Code:    protocol.ErrInvalidMessage,
+				Message: fmt.Sprintf(`invalid message format: %v`, err),
+			})
 
 			select {
 			case c.send <- errResponse:
@@ -154,71 +494,138 @@ func (c *Client) readPump(srv *WebSocketServer) {
 			continue
 		}
 
-		msg.Timestamp = time.Now()
-		c.receive <- msg
+		select {
+		case c.handoff <- struct{}{}:
+		case <-c.close:
+			return
+		}
+
+		// messages are no longer handled once the client drains
+		select {
+		case <-c.drain:
+			<-c.handoff
+			c.reject(msg)
+			continue
+		default:
+		}
+
+		// the processing pump keeps reading messages until it takes handoff, even once it drains
+		select {
+		case c.receive <- msg:
+			<-c.handoff
+		case <-c.close:
+			return
+		}
 	}
 }
 
-// writePump handles outgoing WebSocket messages.
+// writePump handles outgoing WebSocket messages, it is the only writer of messages to the connection.
 func (c *Client) writePump() {
-	ticker := time.NewTicker(50 * time.Second)
+	ticker := time.NewTicker(pingPeriod)
 	defer func() {
 		ticker.Stop()
-		if c.conn != nil {
-			c.conn.Close()
-		}
-		if c.dbConn != nil {
-			c.dbConn.Close()
-		}
+		c.conn.Close()
 	}()
 
 	for {
 		select {
-		case message, ok := <-c.send:
-			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-
-			err := c.conn.WriteJSON(message)
-			if err != nil {
+		case message := <-c.send:
+			if err := c.write(message); err != nil {
 				log.Printf("Write error: %v", err)
 				return
 			}
 
 		case <-ticker.C:
-			if err := c.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second)); err != nil {
+			if err := c.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(writeWait)); err != nil {
 				log.Println("Ping error:", err)
 				return
 			}
 
+		case <-c.drained:
+			// every response has been sent to the send channel, write them before closing the connection
+			for {
+				select {
+				case message := <-c.send:
+					if err := c.write(message); err != nil {
+						log.Printf("Write error: %v", err)
+						return
+					}
+					continue
+				default:
+				}
+				break
+			}
+
+			msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+			c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+			return
+
 		case <-c.close:
 			return
 		}
 	}
 }
 
-// processingPump handles message processing logic.
+// write writes a response in the format of the negotiated protocol version.
+func (c *Client) write(message protocol.Response) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if c.version.Load() == protocol.Version1 {
+		return c.conn.WriteJSON(legacyResponse(message))
+	}
+	return c.conn.WriteJSON(message)
+}
+
+// processingPump handles message processing logic, it owns the session and the pending channel.
 func (c *Client) processingPump() {
 	defer func() {
-		if c.conn != nil {
-			c.conn.Close()
-		}
-		if c.dbConn != nil {
-			c.dbConn.Close()
-		}
+		// chat messages being answered use the session
+		c.chats.Wait()
+		c.releaseSession()
+		c.releaseConfirmations()
+		close(c.pending)
 	}()
 
 	for {
 		select {
-		case msg := <-c.receive:
-			switch msg.Type {
-			case "start":
-				c.handleDBConn(msg)
-			case "chat":
-				c.handleChat(msg)
-			default:
-				c.handleUnknownMessage(msg)
+		case msg, ok := <-c.receive:
+			if !ok {
+				return
+			}
+			c.dispatch(msg)
+
+		case <-c.drain:
+			c.rejectReceived()
+			return
+
+		case <-c.close:
+			return
+		}
+	}
+}
+
+// rejectReceived rejects the messages that were received before the client started draining. Once it takes handoff,
+// the read pump is not handing over a message and rejects the messages it reads itself.
+func (c *Client) rejectReceived() {
+	for {
+		select {
+		case msg, ok := <-c.receive:
+			if !ok {
+				return
+			}
+			c.reject(msg)
+
+		case c.handoff <- struct{}{}:
+			for {
+				select {
+				case msg, ok := <-c.receive:
+					if !ok {
+						return
+					}
+					c.reject(msg)
+				default:
+					<-c.handoff
+					return
+				}
 			}
 
 		case <-c.close:
@@ -226,3 +633,48 @@ func (c *Client) processingPump() {
 		}
 	}
 }
+
+// stop closes the close channel, making every pump return.
+func (c *Client) stop() {
+	c.stopOnce.Do(func() {
+		close(c.close)
+	})
+}
+
+// startDraining stops the client from handling further messages, the connection is closed once the responses
+// of the messages being handled are written.
+func (c *Client) startDraining() {
+	c.drainOnce.Do(func() {
+		close(c.drain)
+	})
+}
+
+// reject responds to a message that is not handled because the server is shutting down.
+func (c *Client) reject(msg protocol.Message) {
+	resp := protocol.NewErrorResponse(msg.ID, responseType(msg.Type), &protocol.Error{
+		Code:    protocol.ErrShuttingDown,
+		Message: "the server is shutting down, reconnect to continue",
+	})
+
+	select {
+	case c.send <- resp:
+	case <-c.close:
+	}
+}
+
+// responseType returns the type of the response to a message type.
+func responseType(messageType string) string {
+	switch messageType {
+	case protocol.TypeHello:
+		return protocol.TypeWelcome
+	case protocol.TypeStart:
+		return protocol.TypeStartResponse
+	case protocol.TypeChat:
+		return protocol.TypeChatResponse
+	case protocol.TypeConfirmQuery:
+		return protocol.TypeConfirmQueryResponse
+	case protocol.TypeExecuteSQL:
+		return protocol.TypeExecuteSQLResponse
+	}
+	return protocol.TypeError
+}
@@ -0,0 +1,600 @@
+package chat
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gentcod/nlp-to-sql/chat/protocol"
+	conv "github.com/gentcod/nlp-to-sql/converter"
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/limiter"
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// expensiveQuery is the query of the expensiveQuestion, it exceeds any cost limits.
+const (
+	expensiveQuestion = "expensive"
+	expensiveQuery    = "SELECT * FROM entries"
+)
+
+// fakeConverter answers a question with the question, after waiting for the duration it contains.
+// The expensive question and query fail with a CostError when cost limits are enabled and are answered with their query otherwise.
+// Other provided queries are validated and return one row, they are answered with the question when it is set.
+type fakeConverter struct{}
+
+func (fakeConverter) Convert(arg conv.ConvertParams) (conv.Result, error) {
+	if arg.Query != "" && arg.Query != expensiveQuery {
+		if !util.ValidQuery(arg.Query, arg.DBType) {
+			return conv.Result{Query: arg.Query}, conv.ErrQueryPolicy
+		}
+		return conv.Result{Query: arg.Query, Response: arg.Question, Grounded: true, Data: []map[string]any{{"count": 1}}}, nil
+	}
+
+	if arg.Question == expensiveQuestion || arg.Query == expensiveQuery {
+		if arg.CostLimits.Enabled() {
+			return conv.Result{}, &conv.CostError{
+				Query:    expensiveQuery,
+				Estimate: conv.Estimate{Rows: 1e6, Cost: 5e4},
+				Limits:   arg.CostLimits,
+			}
+		}
+		return conv.Result{Query: arg.Query, Response: arg.Query, Grounded: true}, nil
+	}
+
+	delay, err := time.ParseDuration(arg.Question)
+	if err != nil {
+		return conv.Result{}, err
+	}
+	time.Sleep(delay)
+	if arg.Progress != nil {
+		arg.Progress(conv.StageQueryGenerated)
+		arg.Progress(conv.StageDataFetched)
+	}
+	return conv.Result{Response: arg.Question, Grounded: true}, nil
+}
+
+func (fakeConverter) GenerateQuery(dbType, llmType, que string, schema map[string]map[string]string) (string, error) {
+	return "SELECT 1", nil
+}
+
+// DraftQuery drafts the question as the query.
+func (fakeConverter) DraftQuery(arg conv.ConvertParams) (conv.Draft, error) {
+	if arg.Progress != nil {
+		arg.Progress(conv.StageQueryGenerated)
+	}
+	return conv.Draft{Query: arg.Question, Analysis: util.AnalyzeQuery(arg.Question, arg.DBType)}, nil
+}
+
+// fakeStore has no user limits and ignores llm usage.
+type fakeStore struct {
+	db.Store
+}
+
+func (fakeStore) GetUserLimits(ctx context.Context, authID uuid.UUID) (db.UserLimit, error) {
+	return db.UserLimit{}, sql.ErrNoRows
+}
+
+func (fakeStore) AddLLMUsage(ctx context.Context, arg db.AddLLMUsageParams) (db.LlmUsage, error) {
+	return db.LlmUsage{}, nil
+}
+
+// fakeConnector connects to a database without tables.
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return fakeRows{}, nil
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string              { return []string{"table_name"} }
+func (fakeRows) Close() error                   { return nil }
+func (fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+const (
+	testDBType = "postgres"
+	testDBName = "chat"
+	testDBUrl  = "postgres://test"
+)
+
+// newTestServer returns a WebSocket server serving chats with the fake database, the test holds a reference
+// to its pool so that it is not closed when the clients release it.
+func newTestServer(t *testing.T) (*WebSocketServer, *httptest.Server) {
+	srv, err := NewWebSocketServer(util.Config{}, fakeConverter{}, limiter.NewLimiter(fakeStore{}, limiter.Limits{}), nil, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	srv.pools.pools[connectionID(testDBType, testDBName, testDBUrl)] = &pool{
+		db:        sql.OpenDB(fakeConnector{}),
+		dbType:    testDBType,
+		refs:      1,
+		healthy:   true,
+		checkedAt: now,
+		createdAt: now,
+	}
+
+	gin.SetMode(gin.TestMode)
+	// connections are made by a new user, unless the user query parameter names one
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = r
+		userID, err := uuid.Parse(r.URL.Query().Get("user"))
+		if err != nil {
+			userID = uuid.New()
+		}
+		srv.HandleConnection(c, userID)
+	}))
+	t.Cleanup(func() {
+		httpServer.Close()
+		srv.pools.Close()
+	})
+
+	return srv, httpServer
+}
+
+func dial(t *testing.T, httpServer *httptest.Server) *websocket.Conn {
+	return dialAs(t, httpServer, uuid.Nil)
+}
+
+// dialAs connects as the user, or as a new user when userID is uuid.Nil.
+func dialAs(t *testing.T, httpServer *httptest.Server, userID uuid.UUID) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	if userID != uuid.Nil {
+		url += "?user=" + userID.String()
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, id, messageType string, payload any) {
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteJSON(protocol.Message{ID: id, Type: messageType, Payload: data}))
+}
+
+func read(t *testing.T, conn *websocket.Conn) protocol.Response {
+	var resp protocol.Response
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, conn.ReadJSON(&resp))
+	return resp
+}
+
+// start negotiates version 2 with the delivery mode and starts a chat with the fake database.
+func start(t *testing.T, conn *websocket.Conn, delivery string) {
+	send(t, conn, "hello", protocol.TypeHello, protocol.HelloPayload{Versions: []int{protocol.Version2}, Delivery: delivery})
+	require.Equal(t, protocol.StatusSuccess, read(t, conn).Status)
+
+	send(t, conn, "start", protocol.TypeStart, protocol.StartPayload{DBType: testDBType, DBName: testDBName, DBUrl: testDBUrl})
+	resp := read(t, conn)
+	require.Equal(t, protocol.StatusSuccess, resp.Status, resp.Error)
+}
+
+func poolClients(srv *WebSocketServer) int {
+	for _, stats := range srv.PoolStats() {
+		if stats.ID == connectionID(testDBType, testDBName, testDBUrl) {
+			return stats.Clients
+		}
+	}
+	return 0
+}
+
+func TestDelivery(t *testing.T) {
+	for delivery, order := range map[string][]string{
+		protocol.DeliveryOrdered:   {"slow", "fast"},
+		protocol.DeliveryUnordered: {"fast", "slow"},
+	} {
+		t.Run(delivery, func(t *testing.T) {
+			_, httpServer := newTestServer(t)
+			conn := dial(t, httpServer)
+			start(t, conn, delivery)
+
+			send(t, conn, "slow", protocol.TypeChat, protocol.ChatPayload{Question: "300ms"})
+			send(t, conn, "fast", protocol.TypeChat, protocol.ChatPayload{Question: "0s"})
+
+			for _, id := range order {
+				resp := read(t, conn)
+				require.Equal(t, id, resp.ID)
+				require.Equal(t, protocol.StatusSuccess, resp.Status, resp.Error)
+			}
+		})
+	}
+}
+
+func TestBackpressure(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+	srv.maxInFlight = 1
+	conn := dial(t, httpServer)
+
+	send(t, conn, "hello", protocol.TypeHello, protocol.HelloPayload{Versions: []int{protocol.Version2}, Delivery: protocol.DeliveryUnordered})
+	var welcome protocol.WelcomePayload
+	require.NoError(t, read(t, conn).DecodePayload(&welcome))
+	require.Equal(t, 1, welcome.MaxInFlight)
+
+	send(t, conn, "start", protocol.TypeStart, protocol.StartPayload{DBType: testDBType, DBName: testDBName, DBUrl: testDBUrl})
+	require.Equal(t, protocol.StatusSuccess, read(t, conn).Status)
+
+	// the fast chat is not read until the slow chat is answered, even with unordered delivery
+	sent := time.Now()
+	send(t, conn, "slow", protocol.TypeChat, protocol.ChatPayload{Question: "300ms"})
+	send(t, conn, "fast", protocol.TypeChat, protocol.ChatPayload{Question: "0s"})
+
+	for _, id := range []string{"slow", "fast"} {
+		resp := read(t, conn)
+		require.Equal(t, id, resp.ID)
+		require.Equal(t, protocol.StatusSuccess, resp.Status, resp.Error)
+	}
+	require.GreaterOrEqual(t, time.Since(sent), 300*time.Millisecond)
+}
+
+func TestShutdown(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+	conn := dial(t, httpServer)
+	start(t, conn, "")
+	require.Equal(t, 2, poolClients(srv))
+
+	send(t, conn, "chat", protocol.TypeChat, protocol.ChatPayload{Question: "300ms"})
+	time.Sleep(50 * time.Millisecond)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdown <- srv.Shutdown(ctx)
+	}()
+
+	// messages received once the server shuts down are rejected, the chat in flight is answered
+	time.Sleep(50 * time.Millisecond)
+	send(t, conn, "late", protocol.TypeChat, protocol.ChatPayload{Question: "0s"})
+
+	responses := map[string]protocol.Response{}
+	for i := 0; i < 2; i++ {
+		resp := read(t, conn)
+		responses[resp.ID] = resp
+	}
+	require.Equal(t, protocol.StatusSuccess, responses["chat"].Status, responses["chat"].Error)
+	require.Equal(t, protocol.ErrShuttingDown, responses["late"].Error.Code)
+
+	_, _, err := conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+	require.NoError(t, <-shutdown)
+
+	// new connections are refused
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+	conn := dial(t, httpServer)
+	start(t, conn, "")
+
+	send(t, conn, "chat", protocol.TypeChat, protocol.ChatPayload{Question: "500ms"})
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, srv.Shutdown(ctx), context.DeadlineExceeded)
+
+	// the connection is closed without waiting for the chat
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err := conn.ReadMessage()
+	require.Error(t, err)
+}
+
+func TestDisconnectDuringChat(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+
+	for i := 0; i < 5; i++ {
+		conn := dial(t, httpServer)
+		start(t, conn, protocol.DeliveryUnordered)
+
+		send(t, conn, "a", protocol.TypeChat, protocol.ChatPayload{Question: "100ms"})
+		send(t, conn, "b", protocol.TypeChat, protocol.ChatPayload{Question: "0s"})
+		conn.Close()
+	}
+
+	// the pools acquired by the clients and their chats are released
+	require.Eventually(t, func() bool {
+		return poolClients(srv) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, srv.Shutdown(ctx))
+}
+
+func TestDisconnectUser(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+
+	userID := uuid.New()
+	conns := []*websocket.Conn{dialAs(t, httpServer, userID), dialAs(t, httpServer, userID)}
+	for _, conn := range conns {
+		start(t, conn, "")
+	}
+	other := dial(t, httpServer)
+	start(t, other, "")
+
+	require.Equal(t, 2, srv.DisconnectUser(userID))
+	for _, conn := range conns {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, _, err := conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), err)
+	}
+
+	// the clients of the user release their pools, other users keep chatting
+	require.Eventually(t, func() bool {
+		return poolClients(srv) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Zero(t, srv.DisconnectUser(userID))
+
+	send(t, other, "1", protocol.TypeChat, protocol.ChatPayload{Question: "0s"})
+	require.Equal(t, protocol.StatusSuccess, read(t, other).Status)
+}
+
+func TestDisconnectWorkspace(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+	resolver := fakeResolver{id: uuid.New(), organizationID: uuid.New()}
+	srv.resolver = resolver
+
+	startWorkspace := func(userID uuid.UUID) *websocket.Conn {
+		conn := dialAs(t, httpServer, userID)
+		send(t, conn, "hello", protocol.TypeHello, protocol.HelloPayload{Versions: []int{protocol.Version2}})
+		require.Equal(t, protocol.StatusSuccess, read(t, conn).Status)
+		send(t, conn, "start", protocol.TypeStart, protocol.StartPayload{ConnectionID: resolver.id.String()})
+		require.Equal(t, protocol.StatusSuccess, read(t, conn).Status)
+		return conn
+	}
+	requireClosed := func(conn *websocket.Conn) {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, _, err := conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), err)
+	}
+
+	// removed members stop chatting with the connections of the organization
+	member, other := uuid.New(), uuid.New()
+	memberConn, otherConn := startWorkspace(member), startWorkspace(other)
+	direct := dialAs(t, httpServer, member)
+	start(t, direct, "")
+
+	require.Zero(t, srv.DisconnectMember(uuid.New(), member))
+	require.Equal(t, 1, srv.DisconnectMember(resolver.organizationID, member))
+	requireClosed(memberConn)
+
+	send(t, direct, "1", protocol.TypeChat, protocol.ChatPayload{Question: "0s"})
+	require.Equal(t, protocol.StatusSuccess, read(t, direct).Status)
+
+	// every chat with a deleted connection is closed
+	require.Zero(t, srv.DisconnectConnection(uuid.New()))
+	require.Equal(t, 1, srv.DisconnectConnection(resolver.id))
+	requireClosed(otherConn)
+
+	require.Eventually(t, func() bool {
+		return poolClients(srv) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestConfirmQuery(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+	srv.costGuard = costGuard{limits: conv.CostLimits{MaxRows: 1000}, action: CostActionConfirm}
+
+	conn := dial(t, httpServer)
+	start(t, conn, "")
+
+	send(t, conn, "1", protocol.TypeChat, protocol.ChatPayload{Question: expensiveQuestion})
+	resp := read(t, conn)
+	require.Equal(t, protocol.ErrConfirmationRequired, resp.Error.Code)
+	confirmation := resp.Error.Confirmation
+	require.NotNil(t, confirmation)
+	require.NotEmpty(t, confirmation.Token)
+	require.Equal(t, expensiveQuery, confirmation.Query)
+	require.Equal(t, int64(1000), confirmation.MaxRows)
+
+	// the confirmation retains the pool of the session
+	require.Equal(t, 3, poolClients(srv))
+
+	send(t, conn, "2", protocol.TypeConfirmQuery, protocol.ConfirmQueryPayload{Token: confirmation.Token})
+	resp = read(t, conn)
+	require.Equal(t, protocol.TypeConfirmQueryResponse, resp.Type)
+	var result protocol.ChatResult
+	require.NoError(t, resp.DecodePayload(&result))
+	require.Equal(t, expensiveQuery, result.Answer)
+	require.Equal(t, 2, poolClients(srv))
+
+	// confirmations can only be used once
+	send(t, conn, "3", protocol.TypeConfirmQuery, protocol.ConfirmQueryPayload{Token: confirmation.Token})
+	require.Equal(t, protocol.ErrConfirmationNotFound, read(t, conn).Error.Code)
+}
+
+func TestRefuseExpensiveQuery(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+	srv.costGuard = costGuard{limits: conv.CostLimits{MaxCost: 100}, action: CostActionRefuse}
+
+	conn := dial(t, httpServer)
+	start(t, conn, "")
+
+	send(t, conn, "1", protocol.TypeChat, protocol.ChatPayload{Question: expensiveQuestion})
+	resp := read(t, conn)
+	require.Equal(t, protocol.ErrQueryTooExpensive, resp.Error.Code)
+	require.Nil(t, resp.Error.Confirmation)
+}
+
+func TestRefuseExpensiveQueryVersion1(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+	srv.costGuard = costGuard{limits: conv.CostLimits{MaxRows: 1000}, action: CostActionConfirm}
+
+	// version 1 responses cannot carry a confirmation
+	conn := dial(t, httpServer)
+	var resp Response
+	send(t, conn, "start", protocol.TypeStart, protocol.StartPayload{DBType: testDBType, DBName: testDBName, DBUrl: testDBUrl})
+	require.NoError(t, conn.ReadJSON(&resp))
+	require.Equal(t, protocol.StatusSuccess, resp.Status)
+
+	send(t, conn, "1", protocol.TypeChat, protocol.ChatPayload{Question: expensiveQuestion})
+	require.NoError(t, conn.ReadJSON(&resp))
+	require.Equal(t, protocol.StatusError, resp.Status)
+	require.NotContains(t, resp.Message, "confirm")
+	require.Equal(t, 2, poolClients(srv))
+}
+
+func TestSQLOnly(t *testing.T) {
+	_, httpServer := newTestServer(t)
+	conn := dial(t, httpServer)
+	start(t, conn, "")
+
+	send(t, conn, "valid", protocol.TypeChat, protocol.ChatPayload{Question: "SELECT name FROM users", Mode: protocol.ModeSQLOnly})
+	resp := read(t, conn)
+	require.Equal(t, protocol.TypeChatResponse, resp.Type)
+
+	var result protocol.SQLResult
+	require.NoError(t, resp.DecodePayload(&result))
+	require.Equal(t, "SELECT name FROM users", result.Query)
+	require.True(t, result.Valid)
+	require.Empty(t, result.Diagnostics)
+	require.Equal(t, []string{"users"}, result.Tables)
+	require.Equal(t, []string{"users.name"}, result.Columns)
+
+	// invalid queries are returned with their diagnostics rather than rejected
+	send(t, conn, "invalid", protocol.TypeChat, protocol.ChatPayload{Question: "DELETE FROM users", Mode: protocol.ModeSQLOnly})
+	resp = read(t, conn)
+	require.NoError(t, resp.DecodePayload(&result))
+	require.False(t, result.Valid)
+	require.Equal(t, []string{"the query is not a SELECT statement: DELETE"}, result.Diagnostics)
+
+	send(t, conn, "unknown", protocol.TypeChat, protocol.ChatPayload{Question: "0s", Mode: "explain"})
+	resp = read(t, conn)
+	require.Equal(t, protocol.StatusError, resp.Status)
+	require.Equal(t, protocol.ErrInvalidPayload, resp.Error.Code)
+}
+
+func TestExecuteSQL(t *testing.T) {
+	srv, httpServer := newTestServer(t)
+	conn := dial(t, httpServer)
+	start(t, conn, "")
+
+	send(t, conn, "1", protocol.TypeExecuteSQL, protocol.ExecuteSQLPayload{Query: "SELECT COUNT(*) FROM users"})
+	resp := read(t, conn)
+	require.Equal(t, protocol.TypeExecuteSQLResponse, resp.Type)
+	var result protocol.ExecuteSQLResult
+	require.NoError(t, resp.DecodePayload(&result))
+	require.Len(t, result.Rows, 1)
+	require.Empty(t, result.Answer)
+	require.Nil(t, result.Grounded)
+
+	// the result is summarised with the question
+	send(t, conn, "2", protocol.TypeExecuteSQL, protocol.ExecuteSQLPayload{Query: "SELECT COUNT(*) FROM users", Question: "How many users?"})
+	resp = read(t, conn)
+	require.NoError(t, resp.DecodePayload(&result))
+	require.Equal(t, "How many users?", result.Answer)
+	require.NotNil(t, result.Grounded)
+
+	// the query is validated like generated queries
+	send(t, conn, "3", protocol.TypeExecuteSQL, protocol.ExecuteSQLPayload{Query: "DELETE FROM users"})
+	resp = read(t, conn)
+	require.Equal(t, protocol.ErrQueryRejected, resp.Error.Code)
+
+	send(t, conn, "4", protocol.TypeExecuteSQL, protocol.ExecuteSQLPayload{})
+	require.Equal(t, protocol.ErrInvalidPayload, read(t, conn).Error.Code)
+
+	// expensive queries require a confirmation, which responds with the result of the query
+	srv.costGuard = costGuard{limits: conv.CostLimits{MaxRows: 1000}, action: CostActionConfirm}
+	send(t, conn, "5", protocol.TypeStart, protocol.StartPayload{DBType: testDBType, DBName: testDBName, DBUrl: testDBUrl})
+	require.Equal(t, protocol.StatusSuccess, read(t, conn).Status)
+
+	send(t, conn, "6", protocol.TypeExecuteSQL, protocol.ExecuteSQLPayload{Query: expensiveQuery})
+	resp = read(t, conn)
+	require.Equal(t, protocol.ErrConfirmationRequired, resp.Error.Code)
+
+	send(t, conn, "7", protocol.TypeConfirmQuery, protocol.ConfirmQueryPayload{Token: resp.Error.Confirmation.Token})
+	resp = read(t, conn)
+	require.Equal(t, protocol.TypeConfirmQueryResponse, resp.Type)
+	var confirmed protocol.ExecuteSQLResult
+	require.NoError(t, resp.DecodePayload(&confirmed))
+	require.Equal(t, expensiveQuery, confirmed.Query)
+}
+
+func TestExecuteSQLVersion1(t *testing.T) {
+	_, httpServer := newTestServer(t)
+
+	conn := dial(t, httpServer)
+	var resp Response
+	send(t, conn, "start", protocol.TypeStart, protocol.StartPayload{DBType: testDBType, DBName: testDBName, DBUrl: testDBUrl})
+	require.NoError(t, conn.ReadJSON(&resp))
+	require.Equal(t, protocol.StatusSuccess, resp.Status)
+
+	// the result set is the data of the response rather than JSON in the message
+	send(t, conn, "1", protocol.TypeExecuteSQL, protocol.ExecuteSQLPayload{Query: "SELECT COUNT(*) FROM users"})
+	require.NoError(t, conn.ReadJSON(&resp))
+	require.Equal(t, protocol.TypeExecuteSQLResponse, resp.Type)
+	require.Equal(t, "SELECT COUNT(*) FROM users", resp.Message)
+	require.Len(t, resp.Data, 1)
+
+	send(t, conn, "2", protocol.TypeExecuteSQL, protocol.ExecuteSQLPayload{Query: "SELECT COUNT(*) FROM users", Question: "How many users?"})
+	resp = Response{}
+	require.NoError(t, conn.ReadJSON(&resp))
+	require.Equal(t, "How many users?", resp.Message)
+	require.Len(t, resp.Data, 1)
+	require.NotNil(t, resp.Grounded)
+}
+
+func TestRejectReceived(t *testing.T) {
+	c := &Client{
+		send:    make(chan protocol.Response, 4),
+		receive: make(chan protocol.Message, 4),
+		close:   make(chan struct{}),
+		handoff: make(chan struct{}, 1),
+	}
+	defer c.stop()
+
+	// the read pump is handing over a message when the client drains
+	c.receive <- protocol.Message{ID: "received", Type: protocol.TypeChat}
+	c.handoff <- struct{}{}
+
+	rejected := make(chan struct{})
+	go func() {
+		c.rejectReceived()
+		close(rejected)
+	}()
+
+	require.Equal(t, "received", (<-c.send).ID)
+	select {
+	case <-rejected:
+		t.Fatal("returned while a message was being handed over")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	c.receive <- protocol.Message{ID: "handed over", Type: protocol.TypeChat}
+	<-c.handoff
+	<-rejected
+
+	resp := <-c.send
+	require.Equal(t, "handed over", resp.ID)
+	require.Equal(t, protocol.ErrShuttingDown, resp.Error.Code)
+	require.Empty(t, c.receive)
+	require.Empty(t, c.handoff)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/google/uuid"
+)
+
+// runCreateAdmin creates an admin account directly in the database, to create the first admin
+// or to regain access when no admin can log in. Further admins should be invited by an admin.
+// The password is read from the first line of stdin so that it is not recorded in the shell history.
+//
+// Usage: echo "$PASSWORD" | nlptosql create-admin -email admin@example.com -username admin -full-name "Jane Doe"
+func runCreateAdmin(store db.Store, args []string, stdin io.Reader) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the admin")
+	username := flags.String("username", "", "username of the admin")
+	fullName := flags.String("full-name", "", "full name of the admin")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *email == "" || *username == "" || *fullName == "" {
+		flags.Usage()
+		return errors.New("email, username and full-name are required")
+	}
+
+	password, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read password: %v", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	harshedPassword, err := util.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	adminTx, err := store.CreateAdminTx(context.Background(), db.CreateAdminTxParams{
+		CreateAdminAuthParams: db.CreateAdminAuthParams{
+			ID:              uuid.New(),
+			Email:           *email,
+			HarshedPassword: harshedPassword,
+		},
+		CreateAdminParams: db.CreateAdminParams{
+			ID:       uuid.New(),
+			Username: *username,
+			FullName: *fullName,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create admin: %v", err)
+	}
+
+	_, err = store.RecordAuditEvent(context.Background(), db.AuditEventParams{
+		ActorID:  adminTx.Auth.ID,
+		TargetID: adminTx.Auth.ID,
+		Action:   db.AuditAdminSignup,
+		Metadata: map[string]any{
+			"method": "cli",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %v", err)
+	}
+
+	fmt.Printf("Created admin %v (%v)\n", adminTx.Admin.Username, adminTx.Auth.Email)
+	return nil
+}
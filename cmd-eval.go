@@ -0,0 +1,93 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	conv "github.com/gentcod/nlp-to-sql/converter"
+	"github.com/gentcod/nlp-to-sql/eval"
+	mp "github.com/gentcod/nlp-to-sql/mapper"
+	"github.com/gentcod/nlp-to-sql/util"
+)
+
+// runEval runs the offline NL-to-SQL evaluation against a fixture database
+// and writes the JSON and Markdown reports.
+//
+// Usage: nlptosql eval -dataset cases.jsonl -db-type postgres -db-name fixture -db-url <dsn> [-llm llama] [-out eval-report] [-max-rows 1000] [-timeout 30s]
+func runEval(config util.Config, converter conv.Converter, args []string) error {
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	dataset := flags.String("dataset", "", "path to the JSONL dataset of question/expected_sql/expected_result cases")
+	dbType := flags.String("db-type", util.DialectPostgres, "fixture database type: postgres or mysql")
+	dbName := flags.String("db-name", "", "fixture database name")
+	dbUrl := flags.String("db-url", "", "fixture database connection string")
+	llmType := flags.String("llm", "llama", "llm type: gemini, openai or llama")
+	out := flags.String("out", "eval-report", "output path prefix for the .json and .md reports")
+	maxRows := flags.Int("max-rows", 1000, "maximum number of rows of the result of a query, cases exceeding it fail")
+	timeout := flags.Duration("timeout", 30*time.Second, "how long a query can run")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *dataset == "" || *dbName == "" || *dbUrl == "" {
+		flags.Usage()
+		return errors.New("dataset, db-name and db-url are required")
+	}
+
+	cases, err := eval.LoadDataset(*dataset)
+	if err != nil {
+		return err
+	}
+
+	conn, err := sql.Open(*dbType, *dbUrl)
+	if err != nil {
+		return fmt.Errorf("failed to connect to fixture database: %v", err)
+	}
+	defer conn.Close()
+
+	mapper := mp.InitMapper(*dbType)
+	if mapper == nil {
+		return fmt.Errorf("unsupported database type: %v", *dbType)
+	}
+
+	schema, err := mapper.MapSchema(conn, *dbName)
+	if err != nil {
+		return fmt.Errorf("failed to get fixture database context: %v", err)
+	}
+
+	caps := conv.QueryCaps{MaxRows: *maxRows, Timeout: *timeout}
+	evaluator := eval.NewEvaluator(converter, conn, *dbType, *llmType, schema, caps)
+	report := evaluator.Run(cases)
+	report.Dataset = *dataset
+	report.Model = config.Model
+
+	jsonFile, err := os.Create(*out + ".json")
+	if err != nil {
+		return fmt.Errorf("error creating json report: %v", err)
+	}
+	defer jsonFile.Close()
+
+	if err = report.WriteJSON(jsonFile); err != nil {
+		return fmt.Errorf("error writing json report: %v", err)
+	}
+
+	mdFile, err := os.Create(*out + ".md")
+	if err != nil {
+		return fmt.Errorf("error creating markdown report: %v", err)
+	}
+	defer mdFile.Close()
+
+	if err = report.WriteMarkdown(mdFile); err != nil {
+		return fmt.Errorf("error writing markdown report: %v", err)
+	}
+
+	log.Printf("Evaluated %d case(s): %d correct, execution accuracy %.2f%%. Reports written to %s.json and %s.md",
+		report.Total, report.Correct, report.ExecutionAccuracy*100, *out, *out)
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxTemplateRows    = 10
+	maxTemplateColumns = 4
+)
+
+var (
+	fromTablePattern = regexp.MustCompile("(?i)\\bfrom\\s+[`\"]?(?:\\w+[`\"]?\\.[`\"]?)?(\\w+)")
+	wherePattern     = regexp.MustCompile(`(?i)\bwhere\b`)
+	// countPattern matches queries selecting a single COUNT aggregate, e.g. SELECT COUNT(DISTINCT currency) AS total FROM.
+	countPattern = regexp.MustCompile("(?is)^\\s*select\\s+count\\s*\\(\\s*(distinct\\s+)?([^()]*?)\\s*\\)\\s*(?:as\\s+)?[`\"\\w]*\\s+from\\b")
+)
+
+// renderAnswer renders a textual response from templates for empty, single value and small results
+// e.g. "There are 114 accounts.". Columns are rendered in the order of the query, the columns of the data
+// are sorted when they are unknown. It returns false when the result is too complex for a template.
+func renderAnswer(query string, columns []string, data []map[string]any) (string, bool) {
+	if len(data) == 0 {
+		return "No records matched your request.", true
+	}
+
+	if len(columns) == 0 {
+		for col := range data[0] {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+	}
+
+	if len(data) == 1 && len(columns) == 1 {
+		col := columns[0]
+		val := formatValue(data[0][col])
+
+		if answer, ok := renderCount(query, data[0][col]); ok {
+			return answer, true
+		}
+
+		return fmt.Sprintf("The %s is %s.", humanize(col), val), true
+	}
+
+	if len(data) > maxTemplateRows || len(columns) > maxTemplateColumns {
+		return "", false
+	}
+
+	var sb strings.Builder
+	if len(data) == 1 {
+		sb.WriteString("Found 1 record:")
+	} else {
+		fmt.Fprintf(&sb, "Found %d records:", len(data))
+	}
+	for _, row := range data {
+		fields := make([]string, 0, len(columns))
+		for _, col := range columns {
+			fields = append(fields, fmt.Sprintf("%s: %s", humanize(col), formatValue(row[col])))
+		}
+		sb.WriteString("\n- ")
+		sb.WriteString(strings.Join(fields, ", "))
+	}
+
+	return sb.String(), true
+}
+
+// renderCount renders the result of a query selecting a single COUNT aggregate, it returns false
+// for other queries and for values that are not whole numbers.
+func renderCount(query string, val any) (string, bool) {
+	count, ok := numericValue(val)
+	if !ok || count != math.Trunc(count) {
+		return "", false
+	}
+	match := countPattern.FindStringSubmatch(query)
+	if match == nil {
+		return "", false
+	}
+
+	qualifier := ""
+	if wherePattern.MatchString(query) {
+		qualifier = "matching "
+	}
+	n := strconv.FormatFloat(count, 'f', -1, 64)
+
+	// COUNT(DISTINCT column) counts the values of the column rather than the records of the table
+	if match[1] != "" {
+		among := ""
+		if qualifier != "" {
+			among = " among matching records"
+		}
+		if count == 1 {
+			return fmt.Sprintf("There is 1 distinct %s value%s.", humanize(match[2]), among), true
+		}
+		return fmt.Sprintf("There are %s distinct %s values%s.", n, humanize(match[2]), among), true
+	}
+
+	table := "records"
+	if tableMatch := fromTablePattern.FindStringSubmatch(query); tableMatch != nil {
+		table = humanize(tableMatch[1])
+	}
+	if count == 1 {
+		return fmt.Sprintf("There is 1 %srecord in %s.", qualifier, table), true
+	}
+	return fmt.Sprintf("There are %s %s%s.", n, qualifier, table), true
+}
+
+// humanize converts column and table names to words e.g. total_balance to total balance.
+func humanize(name string) string {
+	name = strings.Trim(name, "`\"()*")
+	name = strings.NewReplacer("_", " ", "(*", "", "(", " ", ")", "").Replace(name)
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func formatValue(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "empty"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case time.Time:
+		return v.Format("January 2, 2006 15:04")
+	case string:
+		// times of normalised rows are RFC 3339 strings
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.Format("January 2, 2006 15:04")
+		}
+	}
+	return fmt.Sprint(val)
+}
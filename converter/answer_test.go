@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderAnswer(t *testing.T) {
+	resp, ok := renderAnswer(`SELECT COUNT(*) FROM accounts;`, nil, []map[string]any{{"count": int64(114)}})
+	require.True(t, ok)
+	require.Equal(t, "There are 114 accounts.", resp)
+
+	resp, ok = renderAnswer("SELECT COUNT(*) FROM `bank`.`accounts` WHERE currency = 'USD'", nil, []map[string]any{{"COUNT(*)": "1"}})
+	require.True(t, ok)
+	require.Equal(t, "There is 1 matching record in accounts.", resp)
+
+	resp, ok = renderAnswer(`SELECT SUM(balance) AS total_balance FROM accounts`, nil, []map[string]any{{"total_balance": 2000.5}})
+	require.True(t, ok)
+	require.Equal(t, "The total balance is 2000.5.", resp)
+
+	resp, ok = renderAnswer(`SELECT * FROM accounts WHERE id = 0`, nil, []map[string]any{})
+	require.True(t, ok)
+	require.Equal(t, "No records matched your request.", resp)
+
+	// columns are rendered in the order of the query
+	resp, ok = renderAnswer(`SELECT currency, COUNT(*) FROM accounts GROUP BY currency`, []string{"currency", "count"}, []map[string]any{
+		{"currency": "USD", "count": int64(2)},
+		{"currency": "EUR", "count": int64(1)},
+	})
+	require.True(t, ok)
+	require.Equal(t, "Found 2 records:\n- currency: USD, count: 2\n- currency: EUR, count: 1", resp)
+
+	// only COUNT aggregates are counts
+	resp, ok = renderAnswer(`SELECT country FROM users WHERE id = 1`, []string{"country"}, []map[string]any{{"country": "Nigeria"}})
+	require.True(t, ok)
+	require.Equal(t, "The country is Nigeria.", resp)
+
+	resp, ok = renderAnswer(`SELECT account_id FROM entries WHERE id = 1`, []string{"account_id"}, []map[string]any{{"account_id": int64(7)}})
+	require.True(t, ok)
+	require.Equal(t, "The account id is 7.", resp)
+
+	resp, ok = renderAnswer(`SELECT COUNT(DISTINCT currency) FROM accounts`, []string{"count"}, []map[string]any{{"count": int64(3)}})
+	require.True(t, ok)
+	require.Equal(t, "There are 3 distinct currency values.", resp)
+
+	resp, ok = renderAnswer(`SELECT COUNT(*) AS total FROM accounts`, []string{"total"}, []map[string]any{{"total": int64(5)}})
+	require.True(t, ok)
+	require.Equal(t, "There are 5 accounts.", resp)
+
+	wide := map[string]any{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+	_, ok = renderAnswer(`SELECT * FROM accounts`, nil, []map[string]any{wide})
+	require.False(t, ok)
+}
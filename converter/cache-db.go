@@ -0,0 +1,45 @@
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+)
+
+// DBCache is a Cache backed by the application database,
+// entries are shared between server instances and survive restarts.
+type DBCache struct {
+	store db.Store
+}
+
+// NewDBCache initializes a Cache that stores entries in the application database.
+func NewDBCache(store db.Store) Cache {
+	return &DBCache{
+		store: store,
+	}
+}
+
+func (cache *DBCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := cache.store.GetCacheEntry(ctx, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (cache *DBCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return cache.store.SetCacheEntry(ctx, db.SetCacheEntryParams{
+		Key:   key,
+		Value: value,
+		ExpiresAt: sql.NullTime{
+			Time:  time.Now().Add(ttl),
+			Valid: ttl > 0,
+		},
+	})
+}
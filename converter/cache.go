@@ -0,0 +1,148 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache stores generated queries and query results as JSON encoded values.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for the key and whether a live entry was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores the value for the key, a zero ttl stores the value without expiry.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// CacheOpts configures the caching of generated queries and query results.
+type CacheOpts struct {
+	// Cache is used to store queries and results, caching is disabled when nil.
+	Cache Cache
+	// ResultTTL is how long query results are cached per connection, result caching is disabled when zero.
+	ResultTTL time.Duration
+}
+
+// SchemaFingerprint returns a hash identifying a database schema,
+// it changes whenever a table, column or column type changes.
+func SchemaFingerprint(schema map[string]map[string]string) string {
+	var entries []string
+	for table, columns := range schema {
+		for col, dataType := range columns {
+			entries = append(entries, fmt.Sprintf("%s.%s:%s", table, col, dataType))
+		}
+	}
+	sort.Strings(entries)
+
+	return hash(strings.Join(entries, "\n"))
+}
+
+// normalizeQuestion lower cases the question, collapses whitespaces and trims trailing punctuation
+// so that trivially different phrasings of a question share a cache entry.
+func normalizeQuestion(que string) string {
+	que = strings.Join(strings.Fields(strings.ToLower(que)), " ")
+	return strings.TrimRight(que, " ?.!")
+}
+
+// queryCacheKey is the key of a generated query, it depends on the question, the schema and the LLM used.
+func queryCacheKey(que string, schema map[string]map[string]string, dbType, llmType, model string) string {
+	return "query:" + hash(strings.Join([]string{
+		normalizeQuestion(que),
+		SchemaFingerprint(schema),
+		dbType,
+		llmType,
+		model,
+	}, "\x00"))
+}
+
+// resultCacheKey is the key of a query result on a specific connection.
+func resultCacheKey(connID, query string) string {
+	return "result:" + hash(connID+"\x00"+strings.Join(strings.Fields(query), " "))
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// getCached decodes the cached value of the key into dest. Cache errors are logged and reported as a cache miss.
+func (converter *SQLConverter) getCached(key string, dest any) bool {
+	if converter.CacheOpts.Cache == nil {
+		return false
+	}
+
+	value, ok, err := converter.CacheOpts.Cache.Get(context.Background(), key)
+	if err != nil {
+		log.Printf("Error reading from cache: %v", err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	// numbers are decoded as json.Number, like normalizeRows converts the numbers of results that are not cached
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(dest); err != nil {
+		log.Printf("Error decoding cached value: %v", err)
+		return false
+	}
+	return true
+}
+
+// setCached encodes and stores the value of the key. Cache errors are logged and otherwise ignored.
+func (converter *SQLConverter) setCached(key string, value any, ttl time.Duration) {
+	if converter.CacheOpts.Cache == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error encoding value to cache: %v", err)
+		return
+	}
+
+	if err := converter.CacheOpts.Cache.Set(context.Background(), key, data, ttl); err != nil {
+		log.Printf("Error writing to cache: %v", err)
+	}
+}
+
+// normalizeRows converts the values of queried rows to the types they decode to from the cache, so that a result
+// renders and grounds the same whether it is served from the cache or not. Numbers become json.Number,
+// which keeps the precision of large integers, and times become RFC 3339 strings.
+func normalizeRows(rows []map[string]any) {
+	for _, row := range rows {
+		for col, val := range row {
+			row[col] = normalizeValue(val)
+		}
+	}
+}
+
+func normalizeValue(val any) any {
+	switch v := val.(type) {
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case int32:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int:
+		return json.Number(strconv.Itoa(v))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'f', -1, 64))
+	case float32:
+		return json.Number(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return val
+}
@@ -0,0 +1,74 @@
+package converter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(2)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), 0))
+	require.NoError(t, cache.Set(ctx, "b", []byte("2"), 0))
+
+	value, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	// b is the least recently used entry and is evicted
+	require.NoError(t, cache.Set(ctx, "c", []byte("3"), 0))
+	_, ok, _ = cache.Get(ctx, "b")
+	require.False(t, ok)
+	_, ok, _ = cache.Get(ctx, "a")
+	require.True(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "d", []byte("4"), time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, ok, _ = cache.Get(ctx, "d")
+	require.False(t, ok)
+}
+
+func TestCacheKeys(t *testing.T) {
+	schema := map[string]map[string]string{
+		"accounts": {"id": "integer", "currency": "text"},
+	}
+
+	key := queryCacheKey("How many accounts are there?", schema, "postgres", "llama", "llama3")
+	require.Equal(t, key, queryCacheKey("  how many   ACCOUNTS are there", schema, "postgres", "llama", "llama3"))
+	require.NotEqual(t, key, queryCacheKey("How many accounts are there?", schema, "mysql", "llama", "llama3"))
+	require.NotEqual(t, key, queryCacheKey("How many accounts are there?", schema, "postgres", "gemini", "gemini-pro"))
+
+	schema["accounts"]["balance"] = "numeric"
+	require.NotEqual(t, key, queryCacheKey("How many accounts are there?", schema, "postgres", "llama", "llama3"))
+
+	require.Equal(t, resultCacheKey("conn", "SELECT COUNT(*)\n FROM accounts"), resultCacheKey("conn", "SELECT COUNT(*) FROM accounts"))
+	require.NotEqual(t, resultCacheKey("conn", "SELECT 1"), resultCacheKey("other", "SELECT 1"))
+}
+
+func TestCachedResult(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	rows := []map[string]any{{"id": int64(9007199254740993), "balance": 2.5, "created_at": createdAt, "name": "Ada"}}
+	normalizeRows(rows)
+
+	cache := &SQLConverter{CacheOpts: CacheOpts{Cache: NewMemoryCache(1)}}
+	cache.setCached("result", rows, 0)
+
+	var cached []map[string]any
+	require.True(t, cache.getCached("result", &cached))
+
+	// a cached result is identical to the result it was cached from
+	require.Equal(t, rows, cached)
+	require.Equal(t, json.Number("9007199254740993"), cached[0]["id"])
+
+	fresh, ok := renderAnswer("SELECT created_at FROM accounts", []string{"created_at"}, []map[string]any{{"created_at": rows[0]["created_at"]}})
+	require.True(t, ok)
+	hit, _ := renderAnswer("SELECT created_at FROM accounts", []string{"created_at"}, []map[string]any{{"created_at": cached[0]["created_at"]}})
+	require.Equal(t, fresh, hit)
+	require.Equal(t, "The created at is May 1, 2024 10:30.", hit)
+}
@@ -1,50 +1,262 @@
 package converter
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	db "github.com/gentcod/nlp-to-sql/internal/database"
 	"github.com/gentcod/nlp-to-sql/rag"
 	"github.com/gentcod/nlp-to-sql/util"
 )
 
+// SQLConverter is shared by concurrent requests, the LLM of each request is initialized with its own copy of Opts.
 type SQLConverter struct {
-	Response string
-	Opts     rag.LLMOpts
+	Opts      rag.LLMOpts
+	CacheOpts CacheOpts
+	QueryLog  QueryLog
+
+	// newLLM initializes the LLM of a request, it is rag.InitLLM unless replaced in tests.
+	newLLM func(llmType string, opts rag.LLMOpts) rag.LLM
 }
 
-// NewSQLConverter initializes a Converter that can be used to handle SQL queries
-func NewSQLConverter(ragOpts rag.LLMOpts) Converter {
+// NewSQLConverter initializes a Converter that can be used to handle SQL queries.
+// Executed queries are not recorded when queryLog is nil.
+func NewSQLConverter(ragOpts rag.LLMOpts, cacheOpts CacheOpts, queryLog QueryLog) Converter {
 	return &SQLConverter{
-		Opts: ragOpts,
+		Opts:      ragOpts,
+		CacheOpts: cacheOpts,
+		QueryLog:  queryLog,
 	}
 }
 
-func (converter *SQLConverter) Convert(conn *sql.DB, llmType, que string, schema map[string]map[string]string) (string, error) {
-	converter.Opts.Context = schema
-	llm := rag.InitLLM(
-		llmType,
-		converter.Opts,
-	)
-	query, err := llm.GenerateQuery(que)
+// maxSummaryAttempts is the number of times a response is generated before it is returned flagged as ungrounded.
+const maxSummaryAttempts = 2
+
+func (converter *SQLConverter) Convert(arg ConvertParams) (result Result, err error) {
+	llm, err := converter.initLLM(arg.DBType, arg.LLMType, arg.Schema)
 	if err != nil {
-		return converter.Response, fmt.Errorf("error evaluating chat with LLM: %v", err)
+		return result, err
+	}
+	defer func() {
+		result.Usage = llm.Usage()
+	}()
+
+	if arg.Query != "" {
+		result.Query = arg.Query
+		if analysis := util.AnalyzeQuery(result.Query, arg.DBType); !analysis.Valid {
+			err = fmt.Errorf("%w %v", ErrQueryPolicy, strings.Join(analysis.Diagnostics, ", "))
+			converter.logQuery(queryLogEntry(arg, result, 0, 0, err))
+			return result, err
+		}
+	} else {
+		queryKey := queryCacheKey(arg.Question, arg.Schema, arg.DBType, arg.LLMType, converter.Opts.Model)
+		result.QueryCached = converter.getCached(queryKey, &result.Query)
+		if !result.QueryCached {
+			result.Query, err = converter.generateQuery(llm, arg.DBType, arg.Question)
+			if err != nil {
+				if errors.Is(err, ErrQueryPolicy) {
+					converter.logQuery(queryLogEntry(arg, result, 0, 0, err))
+				}
+				return result, err
+			}
+			converter.setCached(queryKey, result.Query, 0)
+		}
+	}
+	arg.progress(StageQueryGenerated)
+
+	var data db.QueryResult
+	cacheResult := arg.ConnID != "" && converter.CacheOpts.ResultTTL > 0
+	resultKey := resultCacheKey(arg.ConnID, result.Query)
+	if cacheResult {
+		result.ResultCached = converter.getCached(resultKey, &data)
+	}
+	if !result.ResultCached {
+		// cached results are served without running the query, so only queries that are run are estimated
+		if arg.CostLimits.Enabled() {
+			estimate, err := converter.explain(arg, result.Query)
+			if err != nil {
+				return result, err
+			}
+			if arg.CostLimits.Exceeded(estimate) {
+				err = &CostError{Query: result.Query, Estimate: estimate, Limits: arg.CostLimits}
+				converter.logQuery(queryLogEntry(arg, result, 0, 0, err))
+				return result, err
+			}
+		}
+
+		start := time.Now()
+		data, err = converter.getData(arg, result.Query)
+		converter.logQuery(queryLogEntry(arg, result, len(data.Rows), time.Since(start), err))
+		if err != nil {
+			return result, fmt.Errorf("error getting queried data: %v", err)
+		}
+		normalizeRows(data.Rows)
+		if cacheResult {
+			converter.setCached(resultKey, data, converter.CacheOpts.ResultTTL)
+		}
+	} else {
+		converter.logQuery(queryLogEntry(arg, result, len(data.Rows), 0, nil))
+	}
+	result.Columns, result.Data, result.Truncated = data.Columns, data.Rows, data.Truncated
+	arg.progress(StageDataFetched)
+
+	// provided queries are run without a question when their result is not summarised
+	if arg.Question == "" {
+		return result, nil
+	}
+
+	// templates describe the whole result, so truncated results are summarised by the LLM
+	if arg.AnswerMode == AnswerModeDeterministic && !data.Truncated {
+		if response, ok := renderAnswer(result.Query, data.Columns, data.Rows); ok {
+			result.Response = response
+			result.Grounded = true
+			result.Deterministic = true
+			return result, nil
+		}
 	}
 
-	if !util.ValidQuery(query) {
-		return converter.Response, fmt.Errorf("the generated query violates the rule of the policy of omitting sensitive data.")
+	que := arg.Question
+	prompt := que
+	if data.Truncated {
+		prompt = fmt.Sprintf("%v (the data is only the first %d rows of the result, do not state totals or counts of the whole result from it)",
+			que, len(data.Rows))
 	}
+	for attempt := 0; attempt < maxSummaryAttempts; attempt++ {
+		result.Response, err = llm.GenerateResponse(data.Rows, prompt)
+		if err != nil {
+			return result, fmt.Errorf("error converting data to textual response: %v", err)
+		}
 
-	data, err := db.GetData(conn, query)
+		result.Grounded, result.Ungrounded = CheckGrounding(result.Response, que, data.Rows)
+		if result.Grounded {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// GenerateQuery always generates a new query, bypassing the cache.
+func (converter *SQLConverter) GenerateQuery(dbType, llmType, que string, schema map[string]map[string]string) (string, error) {
+	llm, err := converter.initLLM(dbType, llmType, schema)
 	if err != nil {
-		return converter.Response, fmt.Errorf("error getting queried data: %v", err)
+		return "", err
 	}
 
-	converter.Response, err = llm.GenerateResponse(data, que)
+	query, err := converter.generateQuery(llm, dbType, que)
 	if err != nil {
-		return converter.Response, fmt.Errorf("error converting data to textual response: %v", err)
+		return "", err
 	}
 
-	return converter.Response, nil
+	return query, nil
+}
+
+func (converter *SQLConverter) DraftQuery(arg ConvertParams) (draft Draft, err error) {
+	queryKey := queryCacheKey(arg.Question, arg.Schema, arg.DBType, arg.LLMType, converter.Opts.Model)
+
+	if arg.Query != "" {
+		draft.Query = arg.Query
+	} else {
+		llm, err := converter.initLLM(arg.DBType, arg.LLMType, arg.Schema)
+		if err != nil {
+			return draft, err
+		}
+		defer func() {
+			draft.Usage = llm.Usage()
+		}()
+
+		draft.QueryCached = converter.getCached(queryKey, &draft.Query)
+		if !draft.QueryCached {
+			draft.Query, err = llm.GenerateQuery(arg.Question)
+			if err != nil {
+				return draft, fmt.Errorf("error evaluating chat with LLM: %v", err)
+			}
+		}
+	}
+
+	draft.Analysis = util.AnalyzeQuery(draft.Query, arg.DBType)
+	// only valid generated queries are cached, so that answering the question later does not generate it again
+	if arg.Query == "" && !draft.QueryCached && draft.Analysis.Valid {
+		converter.setCached(queryKey, draft.Query, 0)
+	}
+	arg.progress(StageQueryGenerated)
+
+	return draft, nil
+}
+
+// getData runs the query within the caps of the request.
+func (converter *SQLConverter) getData(arg ConvertParams, query string) (db.QueryResult, error) {
+	ctx, cancel := queryContext(arg.Caps)
+	defer cancel()
+
+	return db.GetReadOnlyData(ctx, arg.Conn, query, arg.Caps.MaxRows)
+}
+
+// explain estimates the query within the timeout of the request.
+func (converter *SQLConverter) explain(arg ConvertParams, query string) (Estimate, error) {
+	ctx, cancel := queryContext(arg.Caps)
+	defer cancel()
+
+	return Explain(ctx, arg.Conn, arg.DBType, query)
+}
+
+// queryContext returns the context of a statement run against the database of a request, cancelled after the timeout of the caps.
+func queryContext(caps QueryCaps) (context.Context, context.CancelFunc) {
+	if caps.Timeout > 0 {
+		return context.WithTimeout(context.Background(), caps.Timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// initLLM initializes the LLM with the schema and dialect of the database being queried.
+// The options are copied so that concurrent requests do not share the schema of another database.
+func (converter *SQLConverter) initLLM(dbType, llmType string, schema map[string]map[string]string) (rag.LLM, error) {
+	opts := converter.Opts
+	opts.Context = schema
+	opts.Dialect = dbType
+
+	newLLM := converter.newLLM
+	if newLLM == nil {
+		newLLM = rag.InitLLM
+	}
+	llm := newLLM(llmType, opts)
+	if llm == nil {
+		return nil, fmt.Errorf("unsupported llm type: %v", llmType)
+	}
+
+	return llm, nil
+}
+
+// generateQuery generates the SQL query for the request and validates it against the database dialect.
+// The query is returned along with ErrQueryPolicy when it fails validation so that it can be recorded.
+func (converter *SQLConverter) generateQuery(llm rag.LLM, dbType, que string) (string, error) {
+	query, err := llm.GenerateQuery(que)
+	if err != nil {
+		return "", fmt.Errorf("error evaluating chat with LLM: %v", err)
+	}
+
+	if !util.ValidQuery(query, dbType) {
+		return query, ErrQueryPolicy
+	}
+
+	return query, nil
+}
+
+// queryLogEntry describes the generated query of the request, a query is valid unless it was rejected by ErrQueryPolicy.
+func queryLogEntry(arg ConvertParams, result Result, rowCount int, duration time.Duration, err error) QueryLogEntry {
+	return QueryLogEntry{
+		UserID:       arg.UserID,
+		ConnID:       arg.ConnID,
+		DBType:       arg.DBType,
+		Question:     arg.Question,
+		Query:        result.Query,
+		Valid:        !errors.Is(err, ErrQueryPolicy),
+		ResultCached: result.ResultCached,
+		RowCount:     rowCount,
+		Duration:     duration,
+		Err:          err,
+	}
 }
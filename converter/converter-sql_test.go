@@ -0,0 +1,146 @@
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/gentcod/nlp-to-sql/rag"
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLLM queries the only table of the schema it was initialized with and answers with the table,
+// so that a response reveals the schema of the request it was generated for.
+type stubLLM struct {
+	opts rag.LLMOpts
+}
+
+func newStubLLM(llmType string, opts rag.LLMOpts) rag.LLM {
+	return &stubLLM{opts: opts}
+}
+
+func (llm *stubLLM) table() string {
+	for table := range llm.opts.Context.(map[string]map[string]string) {
+		return table
+	}
+	return ""
+}
+
+func (llm *stubLLM) GenerateQuery(que string) (string, error) {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %v", llm.table()), nil
+}
+
+func (llm *stubLLM) GenerateResponse(data any, que string) (string, error) {
+	return fmt.Sprintf("%v of %v", que, llm.table()), nil
+}
+
+func (llm *stubLLM) Usage() rag.Usage {
+	return rag.Usage{}
+}
+
+// fakeConnector connects to a database whose queries return two counts.
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+func (fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	read int64
+}
+
+func (*fakeRows) Columns() []string { return []string{"count"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (rows *fakeRows) Next(dest []driver.Value) error {
+	if rows.read == 2 {
+		return io.EOF
+	}
+	rows.read++
+	dest[0] = rows.read
+	return nil
+}
+
+func TestConvertConcurrently(t *testing.T) {
+	converter := &SQLConverter{newLLM: newStubLLM}
+	conn := sql.OpenDB(fakeConnector{})
+	defer conn.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(table string) {
+			defer wg.Done()
+
+			result, err := converter.Convert(ConvertParams{
+				Conn:     conn,
+				DBType:   util.DialectPostgres,
+				LLMType:  "stub",
+				Question: "How many rows?",
+				Schema:   map[string]map[string]string{table: {"id": "integer"}},
+			})
+			require.NoError(t, err)
+
+			// requests only see the schema of their own database
+			require.Equal(t, "SELECT COUNT(*) FROM "+table, result.Query)
+			require.Equal(t, "How many rows? of "+table, result.Response)
+			require.Len(t, result.Data, 2)
+		}(fmt.Sprintf("table_%d", i))
+	}
+	wg.Wait()
+
+	require.Nil(t, converter.Opts.Context)
+}
+
+func TestConvertTruncated(t *testing.T) {
+	converter := &SQLConverter{newLLM: newStubLLM}
+	conn := sql.OpenDB(fakeConnector{})
+	defer conn.Close()
+
+	arg := ConvertParams{
+		Conn:       conn,
+		DBType:     util.DialectPostgres,
+		LLMType:    "stub",
+		Question:   "How many rows?",
+		Schema:     map[string]map[string]string{"entries": {"id": "integer"}},
+		AnswerMode: AnswerModeDeterministic,
+		Caps:       QueryCaps{MaxRows: 1},
+	}
+
+	// truncated results are not rendered with templates, and the LLM is told that it only has the first rows
+	result, err := converter.Convert(arg)
+	require.NoError(t, err)
+	require.True(t, result.Truncated)
+	require.False(t, result.Deterministic)
+	require.Len(t, result.Data, 1)
+	require.Contains(t, result.Response, "only the first 1 rows")
+
+	arg.Caps.MaxRows = 2
+	result, err = converter.Convert(arg)
+	require.NoError(t, err)
+	require.False(t, result.Truncated)
+	require.True(t, result.Deterministic)
+}
@@ -2,10 +2,118 @@ package converter
 
 import (
 	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gentcod/nlp-to-sql/rag"
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// AnswerModeLLM summarizes every result with the LLM.
+	AnswerModeLLM = "llm"
+	// AnswerModeDeterministic renders simple results with templates
+	// and only summarizes complex results with the LLM.
+	AnswerModeDeterministic = "deterministic"
+)
+
+// ErrQueryPolicy is returned when a generated query is not a read-only query of the database dialect.
+var ErrQueryPolicy = errors.New("the generated query violates the rule of the policy of omitting sensitive data.")
+
+// Stages of a conversion reported to the Progress of ConvertParams.
+const (
+	// StageQueryGenerated is reached once the query is generated and validated, or served from the cache.
+	StageQueryGenerated = "query_generated"
+	// StageDataFetched is reached once the query is executed, or its result served from the cache.
+	StageDataFetched = "data_fetched"
 )
 
+// QueryCaps limit how queries are run, zero caps are disabled. Queries are always run in a read-only transaction,
+// at most MaxRows rows of their result are read and they are cancelled after Timeout.
+type QueryCaps struct {
+	MaxRows int
+	Timeout time.Duration
+}
+
+// ConvertParams contains the textual request and the database it is converted against.
+// ConnID identifies the database connection for caching query results and the query log, results are not cached when it is empty.
+// UserID is the account making the request, it is recorded in the query log.
+// Progress is called with the stages of the conversion as they are reached, it is optional.
+// Query is validated and run instead of generating a query when it is set, e.g. once a user confirmed or edited it,
+// the response is then only generated when Question is set too.
+// Queries are explained before they are run when CostLimits are enabled, a CostError is returned when they are exceeded.
+// Queries are run within the Caps, generated and provided queries alike.
+type ConvertParams struct {
+	UserID     uuid.UUID
+	Conn       *sql.DB
+	ConnID     string
+	DBType     string
+	LLMType    string
+	Question   string
+	Schema     map[string]map[string]string
+	AnswerMode string
+	Progress   func(stage string)
+	Query      string
+	CostLimits CostLimits
+	Caps       QueryCaps
+}
+
+// progress reports a stage of the conversion.
+func (arg ConvertParams) progress(stage string) {
+	if arg.Progress != nil {
+		arg.Progress(stage)
+	}
+}
+
+// Result contains the textual response to a request and details about how it was produced.
+type Result struct {
+	Response string
+	Query    string
+
+	// Grounded reports whether every number and named entity in the response
+	// was found in, or derived from, the queried data.
+	Grounded   bool
+	Ungrounded []string
+
+	// QueryCached and ResultCached report whether the query and the queried data were served from the cache.
+	QueryCached  bool
+	ResultCached bool
+
+	// Deterministic reports whether the response was rendered from a template rather than by the LLM.
+	Deterministic bool
+
+	// Data is the queried data and Columns its column names in the order of the query,
+	// Truncated reports whether it was cut to the MaxRows of the QueryCaps.
+	Columns   []string
+	Data      []map[string]any
+	Truncated bool
+
+	// Usage is the number of LLM tokens used by the request, it is also set when an error is returned.
+	Usage rag.Usage
+}
+
+// Draft is a query generated for a request without running it, along with its validation.
+// Queries that fail validation are drafts too, Analysis then explains why they would be rejected.
+type Draft struct {
+	Query       string
+	Analysis    util.QueryAnalysis
+	QueryCached bool
+
+	// Usage is the number of LLM tokens used by the request, it is also set when an error is returned.
+	Usage rag.Usage
+}
+
 type Converter interface {
 	// Convert converts a textual request to database query which is used to get data.
 	// The data returned from the database is then converted to textual response containing information based on the request context.
-	Convert(conn *sql.DB, llmType, que string, schema map[string]map[string]string) (string, error)
+	// DBType is the SQL dialect of the database, it is used for prompting and validating the generated query.
+	Convert(arg ConvertParams) (Result, error)
+
+	// GenerateQuery converts a textual request to a validated database query without executing it.
+	GenerateQuery(dbType, llmType, que string, schema map[string]map[string]string) (string, error)
+
+	// DraftQuery generates the query of a request like Convert, or analyses its Query when it is set,
+	// without running it. Only the database description, Question and Query of the params are used.
+	DraftQuery(arg ConvertParams) (Draft, error)
 }
@@ -0,0 +1,166 @@
+package converter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gentcod/nlp-to-sql/util"
+)
+
+// Estimate is the estimate of the query planner of a database for a query.
+// Rows is the largest number of rows a step of the plan is estimated to read or produce,
+// and Cost is the total cost of the plan in the units of the planner of the database.
+type Estimate struct {
+	Rows float64
+	Cost float64
+}
+
+// CostLimits are the largest estimate a query can have to be run, zero limits are disabled.
+type CostLimits struct {
+	MaxRows int64
+	MaxCost float64
+}
+
+// Enabled reports whether queries have to be estimated.
+func (limits CostLimits) Enabled() bool {
+	return limits.MaxRows > 0 || limits.MaxCost > 0
+}
+
+// Exceeded reports whether an estimate exceeds the limits.
+func (limits CostLimits) Exceeded(estimate Estimate) bool {
+	return (limits.MaxRows > 0 && estimate.Rows > float64(limits.MaxRows)) ||
+		(limits.MaxCost > 0 && estimate.Cost > limits.MaxCost)
+}
+
+// CostError is returned when the estimate of a query exceeds the CostLimits of ConvertParams, the query is not run.
+type CostError struct {
+	Query    string
+	Estimate Estimate
+	Limits   CostLimits
+}
+
+func (err *CostError) Error() string {
+	return fmt.Sprintf("the query is estimated to read %.0f rows at a cost of %.2f, which exceeds the limits of the connection",
+		err.Estimate.Rows, err.Estimate.Cost)
+}
+
+// Explain returns the estimate of the query planner of the database for a query, without running the query.
+// The query is explained in a read-only transaction, which is rolled back once the plan is read.
+func Explain(ctx context.Context, conn *sql.DB, dbType, query string) (Estimate, error) {
+	var explain string
+	switch dbType {
+	case util.DialectPostgres:
+		explain = "EXPLAIN (FORMAT JSON) "
+	case util.DialectMySQL:
+		explain = "EXPLAIN FORMAT=JSON "
+	default:
+		return Estimate{}, fmt.Errorf("unsupported database type: %v", dbType)
+	}
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return Estimate{}, fmt.Errorf("error explaining query: %v", err)
+	}
+	defer tx.Rollback()
+
+	var plan []byte
+	if err := tx.QueryRowContext(ctx, explain+query).Scan(&plan); err != nil {
+		return Estimate{}, fmt.Errorf("error explaining query: %v", err)
+	}
+
+	if dbType == util.DialectMySQL {
+		return parseMySQLPlan(plan)
+	}
+	return parsePostgresPlan(plan)
+}
+
+// parsePostgresPlan parses the output of EXPLAIN (FORMAT JSON), a list with the plan of the statement.
+func parsePostgresPlan(data []byte) (Estimate, error) {
+	var plans []struct {
+		Plan map[string]any `json:"Plan"`
+	}
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return Estimate{}, fmt.Errorf("invalid query plan: %v", err)
+	}
+	if len(plans) == 0 || plans[0].Plan == nil {
+		return Estimate{}, errors.New("invalid query plan: no plan")
+	}
+
+	cost, ok := number(plans[0].Plan["Total Cost"])
+	if !ok {
+		return Estimate{}, errors.New("invalid query plan: no total cost")
+	}
+
+	return Estimate{
+		Rows: maxNumber(plans[0].Plan, "Plan Rows"),
+		Cost: cost,
+	}, nil
+}
+
+// parseMySQLPlan parses the output of EXPLAIN FORMAT=JSON, the query block of the statement.
+func parseMySQLPlan(data []byte) (Estimate, error) {
+	var plan struct {
+		QueryBlock map[string]any `json:"query_block"`
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return Estimate{}, fmt.Errorf("invalid query plan: %v", err)
+	}
+	if plan.QueryBlock == nil {
+		return Estimate{}, errors.New("invalid query plan: no query block")
+	}
+
+	costInfo, _ := plan.QueryBlock["cost_info"].(map[string]any)
+	cost, ok := number(costInfo["query_cost"])
+	if !ok {
+		return Estimate{}, errors.New("invalid query plan: no query cost")
+	}
+
+	return Estimate{
+		Rows: maxNumber(plan.QueryBlock, "rows_examined_per_scan", "rows_produced_per_join"),
+		Cost: cost,
+	}, nil
+}
+
+// maxNumber returns the largest number of the keys anywhere in a decoded JSON value, plans nest their steps.
+func maxNumber(v any, keys ...string) float64 {
+	max := 0.0
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			for _, k := range keys {
+				if key != k {
+					continue
+				}
+				if n, ok := number(value); ok && n > max {
+					max = n
+				}
+			}
+			if n := maxNumber(value, keys...); n > max {
+				max = n
+			}
+		}
+	case []any:
+		for _, value := range v {
+			if n := maxNumber(value, keys...); n > max {
+				max = n
+			}
+		}
+	}
+	return max
+}
+
+// number returns the value of a JSON number, MySQL encodes some numbers as strings.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePostgresPlan(t *testing.T) {
+	estimate, err := parsePostgresPlan([]byte(`[{"Plan": {
+		"Node Type": "Hash Join", "Total Cost": 1520.75, "Plan Rows": 120,
+		"Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "entries", "Total Cost": 1200.5, "Plan Rows": 50000},
+			{"Node Type": "Hash", "Total Cost": 20.1, "Plan Rows": 100}
+		]
+	}}]`))
+	require.NoError(t, err)
+	require.Equal(t, Estimate{Rows: 50000, Cost: 1520.75}, estimate)
+
+	_, err = parsePostgresPlan([]byte(`[]`))
+	require.Error(t, err)
+	_, err = parsePostgresPlan([]byte(`[{"Plan": {"Plan Rows": 1}}]`))
+	require.Error(t, err)
+}
+
+func TestParseMySQLPlan(t *testing.T) {
+	estimate, err := parseMySQLPlan([]byte(`{"query_block": {
+		"select_id": 1,
+		"cost_info": {"query_cost": "2043.25"},
+		"nested_loop": [
+			{"table": {"table_name": "accounts", "rows_examined_per_scan": 100, "rows_produced_per_join": 100}},
+			{"table": {"table_name": "entries", "rows_examined_per_scan": 200, "rows_produced_per_join": 20000}}
+		]
+	}}`))
+	require.NoError(t, err)
+	require.Equal(t, Estimate{Rows: 20000, Cost: 2043.25}, estimate)
+
+	_, err = parseMySQLPlan([]byte(`{"query_block": {"select_id": 1}}`))
+	require.Error(t, err)
+}
+
+func TestCostLimits(t *testing.T) {
+	require.False(t, CostLimits{}.Enabled())
+	require.False(t, CostLimits{}.Exceeded(Estimate{Rows: 1e9, Cost: 1e9}))
+
+	limits := CostLimits{MaxRows: 1000, MaxCost: 500}
+	require.True(t, limits.Enabled())
+	require.False(t, limits.Exceeded(Estimate{Rows: 1000, Cost: 500}))
+	require.True(t, limits.Exceeded(Estimate{Rows: 1001, Cost: 1}))
+	require.True(t, limits.Exceeded(Estimate{Rows: 1, Cost: 500.5}))
+
+	require.False(t, CostLimits{MaxCost: 500}.Exceeded(Estimate{Rows: 1e9, Cost: 1}))
+}
@@ -0,0 +1,209 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	numberPattern  = regexp.MustCompile(`-?\d[\d,]*(?:\.\d+)?(?:\s*(?:thousand|million|billion)\b)?`)
+	entityPattern  = regexp.MustCompile(`\b[A-Z][\w'-]*(?:\s+[A-Z][\w'-]*)*`)
+	sentenceBounds = regexp.MustCompile(`(?:^|[.!?:]\s+|\n+)$`)
+)
+
+// ignoredWords are capitalized words which are not named entities.
+var ignoredWords = map[string]bool{
+	"i":    true,
+	"i'm":  true,
+	"i've": true,
+	"ok":   true,
+}
+
+var numberMultipliers = map[string]float64{
+	"thousand": 1e3,
+	"million":  1e6,
+	"billion":  1e9,
+}
+
+// CheckGrounding verifies that the numbers and named entities mentioned in a summary
+// appear in the queried data or the question, or can be derived from the data as counts or sums.
+// It returns whether the summary is grounded and the ungrounded values found.
+func CheckGrounding(summary, que string, data []map[string]any) (bool, []string) {
+	facts := collectFacts(que, data)
+
+	var ungrounded []string
+	for _, match := range numberPattern.FindAllString(summary, -1) {
+		num, ok := parseNumber(match)
+		if !ok || facts.hasNumber(num) {
+			continue
+		}
+		ungrounded = append(ungrounded, strings.TrimSpace(match))
+	}
+
+	for _, loc := range entityPattern.FindAllStringIndex(summary, -1) {
+		entity := summary[loc[0]:loc[1]]
+
+		// sentence-initial words are capitalized regardless of being an entity,
+		// only the remainder of the phrase is checked.
+		if sentenceBounds.MatchString(summary[:loc[0]]) {
+			words := strings.Fields(entity)
+			entity = strings.Join(words[1:], " ")
+		}
+		if entity == "" || facts.hasText(entity) {
+			continue
+		}
+		ungrounded = append(ungrounded, entity)
+	}
+
+	return len(ungrounded) == 0, ungrounded
+}
+
+// groundingFacts contains the values a summary can be grounded on.
+type groundingFacts struct {
+	numbers []float64
+	text    string
+}
+
+func (facts groundingFacts) hasNumber(num float64) bool {
+	// signs are ignored as hyphens are also used in ranges and dates e.g. 10-20 or 2024-05-01.
+	num = math.Abs(num)
+	for _, n := range facts.numbers {
+		n = math.Abs(n)
+		// allow rounding of values in the summary e.g. 1234.567 as 1,234.57 or 1.2 million.
+		if math.Abs(num-n) <= math.Max(0.01, n*0.05) {
+			return true
+		}
+	}
+	return false
+}
+
+func (facts groundingFacts) hasText(entity string) bool {
+	for _, word := range strings.Fields(strings.ToLower(entity)) {
+		word = strings.TrimSuffix(word, "'s")
+		if ignoredWords[word] {
+			continue
+		}
+		if !strings.Contains(facts.text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+func collectFacts(que string, data []map[string]any) groundingFacts {
+	var facts groundingFacts
+	var text strings.Builder
+
+	addText := func(s string) {
+		text.WriteString(strings.ToLower(s))
+		text.WriteString("\n")
+		for _, match := range numberPattern.FindAllString(s, -1) {
+			if num, ok := parseNumber(match); ok {
+				facts.numbers = append(facts.numbers, num)
+			}
+		}
+	}
+
+	addText(que)
+	facts.numbers = append(facts.numbers, float64(len(data)))
+
+	sums := map[string]float64{}
+	counts := map[string]int{}
+	distinct := map[string]map[string]bool{}
+	for _, row := range data {
+		for col, val := range row {
+			if _, ok := distinct[col]; !ok {
+				addText(col)
+				distinct[col] = map[string]bool{}
+			}
+			if val == nil {
+				continue
+			}
+
+			counts[col]++
+			str := stringValue(val)
+			distinct[col][str] = true
+			addText(str)
+
+			if num, ok := numericValue(val); ok {
+				sums[col] += num
+			}
+			if t, ok := timeValue(val); ok {
+				addText(t.Format("January Monday 2 2006"))
+			}
+		}
+	}
+
+	for col, sum := range sums {
+		facts.numbers = append(facts.numbers, sum)
+		if counts[col] > 0 {
+			facts.numbers = append(facts.numbers, sum/float64(counts[col]))
+		}
+	}
+	for col, count := range counts {
+		facts.numbers = append(facts.numbers, float64(count), float64(len(distinct[col])))
+	}
+
+	facts.text = text.String()
+	return facts
+}
+
+func parseNumber(s string) (float64, bool) {
+	fields := strings.Fields(s)
+	multiplier := 1.0
+	if len(fields) > 1 {
+		multiplier = numberMultipliers[strings.ToLower(fields[1])]
+	}
+
+	num, err := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	return num * multiplier, true
+}
+
+func numericValue(val any) (float64, bool) {
+	switch v := val.(type) {
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case json.Number:
+		num, err := v.Float64()
+		return num, err == nil
+	case string:
+		num, err := strconv.ParseFloat(v, 64)
+		return num, err == nil
+	}
+	return 0, false
+}
+
+// timeValue returns the time of a value, times of normalised rows are RFC 3339 strings.
+func timeValue(val any) (time.Time, bool) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+func stringValue(val any) string {
+	if t, ok := val.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(val)
+}
@@ -0,0 +1,31 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckGrounding(t *testing.T) {
+	data := []map[string]any{
+		{"currency": "USD", "balance": int64(1200), "created_at": time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{"currency": "EUR", "balance": int64(800), "created_at": time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)},
+		{"currency": "NGN", "balance": "1234567.89", "created_at": nil},
+	}
+	que := "What are the balances of accounts opened in the last 30 days?"
+
+	grounded, ungrounded := CheckGrounding("There are 3 accounts. The USD account holds 1,200 and the EUR account 800.", que, data)
+	require.True(t, grounded)
+	require.Empty(t, ungrounded)
+
+	// sums, rounding, dates and numbers from the question are grounded
+	grounded, _ = CheckGrounding("Accounts opened in the last 30 days hold about 1.2 million in total.", que, data)
+	require.True(t, grounded)
+	grounded, _ = CheckGrounding("The first account was opened on May 1, 2024 and the NGN account holds 1,234,567.89.", que, data)
+	require.True(t, grounded)
+
+	grounded, ungrounded = CheckGrounding("There are 7 accounts, mostly held by Acme Bank.", que, data)
+	require.False(t, grounded)
+	require.Equal(t, []string{"7", "Acme Bank"}, ungrounded)
+}
@@ -0,0 +1,40 @@
+package converter
+
+import (
+	"context"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+)
+
+// DBQueryLog is a QueryLog backed by the application database.
+type DBQueryLog struct {
+	store db.Store
+}
+
+// NewDBQueryLog initializes a QueryLog that stores entries in the application database.
+func NewDBQueryLog(store db.Store) QueryLog {
+	return &DBQueryLog{
+		store: store,
+	}
+}
+
+func (queryLog *DBQueryLog) RecordQuery(ctx context.Context, entry QueryLogEntry) error {
+	var errMsg string
+	if entry.Err != nil {
+		errMsg = entry.Err.Error()
+	}
+
+	_, err := queryLog.store.CreateQueryLog(ctx, db.CreateQueryLogParams{
+		UserID:       entry.UserID,
+		ConnectionID: entry.ConnID,
+		DbType:       entry.DBType,
+		Question:     entry.Question,
+		SqlQuery:     entry.Query,
+		Valid:        entry.Valid,
+		ResultCached: entry.ResultCached,
+		RowCount:     int32(entry.RowCount),
+		DurationMs:   entry.Duration.Milliseconds(),
+		Error:        errMsg,
+	})
+	return err
+}
@@ -0,0 +1,41 @@
+package converter
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryLog records every generated query run against a customer database.
+type QueryLog interface {
+	RecordQuery(ctx context.Context, entry QueryLogEntry) error
+}
+
+// QueryLogEntry describes a generated query and the outcome of running it.
+// ConnID identifies the database connection, the connection string is never recorded.
+// Valid is false when the query was rejected by validation and was therefore not run.
+type QueryLogEntry struct {
+	UserID       uuid.UUID
+	ConnID       string
+	DBType       string
+	Question     string
+	Query        string
+	Valid        bool
+	ResultCached bool
+	RowCount     int
+	Duration     time.Duration
+	Err          error
+}
+
+// logQuery records the entry when a query log is configured. Errors are logged and otherwise ignored.
+func (converter *SQLConverter) logQuery(entry QueryLogEntry) {
+	if converter.QueryLog == nil {
+		return
+	}
+
+	if err := converter.QueryLog.RecordQuery(context.Background(), entry); err != nil {
+		log.Printf("Error recording query log: %v", err)
+	}
+}
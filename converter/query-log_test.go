@@ -0,0 +1,39 @@
+package converter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryLogEntry(t *testing.T) {
+	arg := ConvertParams{
+		UserID:   uuid.New(),
+		ConnID:   "conn",
+		DBType:   "postgres",
+		Question: "how many accounts are there?",
+	}
+	result := Result{Query: "SELECT COUNT(*) FROM accounts"}
+
+	entry := queryLogEntry(arg, result, 1, time.Second, nil)
+	require.Equal(t, arg.UserID, entry.UserID)
+	require.Equal(t, arg.ConnID, entry.ConnID)
+	require.Equal(t, arg.Question, entry.Question)
+	require.Equal(t, result.Query, entry.Query)
+	require.True(t, entry.Valid)
+	require.Equal(t, 1, entry.RowCount)
+	require.Equal(t, time.Second, entry.Duration)
+	require.NoError(t, entry.Err)
+
+	// queries that fail to run were still valid
+	entry = queryLogEntry(arg, result, 0, time.Second, errors.New("relation does not exist"))
+	require.True(t, entry.Valid)
+	require.Error(t, entry.Err)
+
+	entry = queryLogEntry(arg, Result{Query: "DELETE FROM accounts"}, 0, 0, ErrQueryPolicy)
+	require.False(t, entry.Valid)
+	require.ErrorIs(t, entry.Err, ErrQueryPolicy)
+}
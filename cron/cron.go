@@ -60,6 +60,31 @@ func (dbcron *DBCron) runCleanup(batchSize int) {
 	} else {
 		log.Printf("Cleanup job completed successfully. Total records deleted: %d", totalDeleted)
 	}
+
+	for _, job := range dbcron.expiryJobs() {
+		deleted, err := job.run(context.Background())
+		if err != nil {
+			log.Printf("Error deleting expired %v -> %v", job.name, err)
+		} else {
+			log.Printf("Deleted %d expired %v", deleted, job.name)
+		}
+	}
+}
+
+// expiryJob deletes the expired records named by name, returning the number of deleted records.
+type expiryJob struct {
+	name string
+	run  func(ctx context.Context) (int64, error)
+}
+
+// expiryJobs lists the records that are deleted once they expire on every cleanup.
+func (dbcron *DBCron) expiryJobs() []expiryJob {
+	return []expiryJob{
+		{"cache entries", dbcron.store.DeleteExpiredCacheEntries},
+		{"login attempts", dbcron.store.DeleteExpiredLoginAttempts},
+		{"auth tokens", dbcron.store.DeleteExpiredAuthTokens},
+		{"admin invitations", dbcron.store.DeleteExpiredAdminInvitations},
+	}
 }
 
 func (dbcron *DBCron) InitCron() error {
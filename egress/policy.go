@@ -0,0 +1,381 @@
+// Package egress restricts the databases that users can connect the server to,
+// so that it cannot be used to reach hosts of the internal network.
+package egress
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+const (
+	// ModeAllowList only allows connections to targets matching a rule.
+	ModeAllowList = "allowlist"
+	// ModeOpen allows connections to any target outside of the blocked ranges,
+	// targets inside them still have to match a rule.
+	ModeOpen = "open"
+)
+
+var (
+	// ErrDenied is returned when the policy does not allow connecting to a target.
+	ErrDenied = errors.New("connection target is not allowed")
+	// ErrUnsupportedDriver is returned for database drivers other than postgres and mysql.
+	ErrUnsupportedDriver = errors.New("unsupported database driver")
+)
+
+// drivers are the database drivers the policy can enforce, it has to control how they dial.
+var drivers = []string{"postgres", "mysql"}
+
+// blockedRanges are never connected to unless a rule explicitly allows an address or range within them.
+var blockedRanges = mustParseCIDRs(
+	"0.0.0.0/8",          // "this" network
+	"127.0.0.0/8",        // loopback
+	"169.254.0.0/16",     // link-local, includes the metadata service of most cloud providers
+	"100.100.100.200/32", // metadata service of Alibaba Cloud
+	"224.0.0.0/4",        // multicast
+	"255.255.255.255/32", // broadcast
+	"::/128",             // unspecified
+	"::1/128",            // loopback
+	"fe80::/10",          // link-local
+	"fd00:ec2::254/128",  // metadata service of AWS
+	"ff00::/8",           // multicast
+)
+
+// RuleStore lists the rules of the policy.
+type RuleStore interface {
+	ListConnectionRules(ctx context.Context) ([]db.ConnectionRule, error)
+}
+
+// Policy checks the targets of database connections against the connection rules managed by admins.
+type Policy struct {
+	store    RuleStore
+	mode     string
+	resolver *net.Resolver
+	dialer   net.Dialer
+}
+
+// NewPolicy initializes a policy with the rules of store, mode is ModeAllowList when empty.
+func NewPolicy(store RuleStore, mode string) (*Policy, error) {
+	if mode == "" {
+		mode = ModeAllowList
+	}
+	if mode != ModeAllowList && mode != ModeOpen {
+		return nil, fmt.Errorf("unsupported connection policy: %v", mode)
+	}
+
+	return &Policy{
+		store:    store,
+		mode:     mode,
+		resolver: net.DefaultResolver,
+		dialer:   net.Dialer{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Open opens a database whose connections are checked by the policy when they are dialed,
+// so that targets cannot change after a check, e.g. by rebinding DNS. The database is pinged
+// to report denied targets and connection failures before it is used.
+func (p *Policy) Open(ctx context.Context, driverName, dsn string) (*sql.DB, error) {
+	var connector driver.Connector
+	switch driverName {
+	case "postgres":
+		pqConnector, err := pq.NewConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		pqConnector.Dialer(pqDialer{policy: p})
+		connector = pqConnector
+	case "mysql":
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		cfg.DialFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return p.DialContext(ctx, driverName, network, address)
+		}
+		connector, err = mysql.NewConnector(cfg)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedDriver, driverName)
+	}
+
+	conn := sql.OpenDB(connector)
+	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// DialContext connects to address when the policy allows the driver to, dialing the checked addresses
+// of its host rather than resolving it again.
+func (p *Policy) DialContext(ctx context.Context, driverName, network, address string) (net.Conn, error) {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, fmt.Errorf("%w: %v connections", ErrDenied, network)
+	}
+
+	ips, err := p.Check(ctx, driverName, address)
+	if err != nil {
+		return nil, err
+	}
+
+	_, port, _ := net.SplitHostPort(address)
+
+	var dialErr error
+	for _, ip := range ips {
+		conn, err := p.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+	}
+
+	return nil, dialErr
+}
+
+// Check resolves the host of address, a host and port, and returns its addresses
+// when the policy allows the driver to connect to all of them.
+func (p *Policy) Check(ctx context.Context, driverName, address string) ([]net.IP, error) {
+	if !supportedDriver(driverName) {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedDriver, driverName)
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port: %v", portStr)
+	}
+
+	rules, err := p.rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := p.resolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %v: %w", host, err)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	for _, ip := range ips {
+		if !p.allowed(rules, driverName, normalizeHost(host), int32(port), ip) {
+			return nil, fmt.Errorf("%w: %v (%v) on port %d", ErrDenied, host, ip, port)
+		}
+	}
+
+	return ips, nil
+}
+
+func (p *Policy) allowed(rules []rule, driverName, host string, port int32, ip net.IP) bool {
+	blocked := blockedRange(ip)
+	if blocked == nil && p.mode == ModeOpen {
+		return true
+	}
+
+	for _, r := range rules {
+		if !r.appliesTo(driverName, port) {
+			continue
+		}
+
+		if r.network != nil {
+			// hostnames and ranges reaching beyond a blocked range, such as 0.0.0.0/0, cannot allow addresses within it
+			if r.network.Contains(ip) && (blocked == nil || contains(blocked, r.network)) {
+				return true
+			}
+			continue
+		}
+
+		if blocked == nil && r.matchesHost(host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rules returns the parsed rules of the store, invalid rules are logged and skipped.
+func (p *Policy) rules(ctx context.Context) ([]rule, error) {
+	stored, err := p.store.ListConnectionRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connection rules: %w", err)
+	}
+
+	rules := make([]rule, 0, len(stored))
+	for _, s := range stored {
+		r, err := parseRule(s.Target, s.Port.Int32, s.DbType.String)
+		if err != nil {
+			log.Printf("Skipping invalid connection rule %v: %v", s.ID, err)
+			continue
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// ValidateRule validates a rule before it is stored. target is a hostname, a wildcard hostname
+// such as *.example.com, an IP address or a CIDR range. port and dbType are 0 and empty for any port and driver.
+func ValidateRule(target string, port int32, dbType string) error {
+	_, err := parseRule(target, port, dbType)
+	return err
+}
+
+// rule allows a driver to connect to a target on a port.
+type rule struct {
+	network *net.IPNet
+	host    string
+	port    int32
+	driver  string
+}
+
+func parseRule(target string, port int32, dbType string) (rule, error) {
+	if port < 0 || port > 65535 {
+		return rule{}, fmt.Errorf("invalid port: %v", port)
+	}
+	if dbType != "" && !supportedDriver(dbType) {
+		return rule{}, fmt.Errorf("%w: %v", ErrUnsupportedDriver, dbType)
+	}
+
+	r := rule{port: port, driver: dbType}
+
+	if ip := net.ParseIP(target); ip != nil {
+		bits := 8 * len(ip)
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		r.network = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		return r, nil
+	}
+
+	if strings.Contains(target, "/") {
+		_, network, err := net.ParseCIDR(target)
+		if err != nil {
+			return rule{}, fmt.Errorf("invalid CIDR range: %v", target)
+		}
+		r.network = network
+		return r, nil
+	}
+
+	host := normalizeHost(target)
+	if !validHostname(strings.TrimPrefix(host, "*.")) {
+		return rule{}, fmt.Errorf("invalid hostname: %v", target)
+	}
+	r.host = host
+
+	return r, nil
+}
+
+func (r rule) appliesTo(driverName string, port int32) bool {
+	return (r.driver == "" || r.driver == driverName) && (r.port == 0 || r.port == port)
+}
+
+// matchesHost matches the hostname of the rule, a wildcard matches subdomains but not the domain itself.
+func (r rule) matchesHost(host string) bool {
+	if suffix, ok := strings.CutPrefix(r.host, "*"); ok {
+		return strings.HasSuffix(host, suffix)
+	}
+	return host == r.host
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+func validHostname(host string) bool {
+	if host == "" || len(host) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func supportedDriver(driverName string) bool {
+	for _, d := range drivers {
+		if d == driverName {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedRange returns the blocked range containing ip, or nil when it is not blocked.
+func blockedRange(ip net.IP) *net.IPNet {
+	for _, network := range blockedRanges {
+		if network.Contains(ip) {
+			return network
+		}
+	}
+	return nil
+}
+
+// contains reports whether inner is within outer.
+func contains(outer, inner *net.IPNet) bool {
+	outerOnes, outerBits := outer.Mask.Size()
+	innerOnes, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && innerOnes >= outerOnes && outer.Contains(inner.IP)
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+// pqDialer dials the connections of the postgres driver through the policy.
+type pqDialer struct {
+	policy *Policy
+}
+
+func (d pqDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d pqDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+func (d pqDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d.policy.DialContext(ctx, "postgres", network, address)
+}
@@ -0,0 +1,105 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EqualResults compares two result sets regardless of row order. The values of a row are compared in the
+// order of their column names, case-insensitively, so that a value only matches the value of the same column,
+// while columns named differently by aliases still compare equal as long as their names sort alike.
+// Values are normalised so that the same data returned with different types compare equal,
+// e.g. a MySQL DECIMAL returned as []byte("2.50") and a JSON number 2.5.
+func EqualResults(expected, actual []map[string]any) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	rows := make(map[string]int, len(expected))
+	for _, row := range expected {
+		rows[rowKey(row)]++
+	}
+
+	for _, row := range actual {
+		key := rowKey(row)
+		if rows[key] == 0 {
+			return false
+		}
+		rows[key]--
+	}
+
+	return true
+}
+
+// rowKey returns a canonical representation of a row from its normalised values, in the order of their column names.
+func rowKey(row map[string]any) string {
+	columns := make([]string, 0, len(row))
+	for col := range row {
+		columns = append(columns, col)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return strings.ToLower(columns[i]) < strings.ToLower(columns[j])
+	})
+
+	values := make([]string, len(columns))
+	for i, col := range columns {
+		values[i] = normalizeValue(row[col])
+	}
+
+	return strings.Join(values, "\x1f")
+}
+
+// normalizeValue converts a value scanned from a database or decoded from JSON to a comparable string.
+func normalizeValue(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return normalizeString(string(v))
+	case string:
+		return normalizeString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return normalizeNumber(float64(v))
+	case int32:
+		return normalizeNumber(float64(v))
+	case int64:
+		return normalizeNumber(float64(v))
+	case uint64:
+		return normalizeNumber(float64(v))
+	case float32:
+		return normalizeNumber(float64(v))
+	case float64:
+		return normalizeNumber(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(val)
+}
+
+func normalizeString(s string) string {
+	s = strings.TrimSpace(s)
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return normalizeNumber(f)
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	return s
+}
+
+// normalizeNumber rounds numbers to 6 decimal places to absorb floating point noise.
+func normalizeNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1e6)/1e6, 'f', -1, 64)
+}
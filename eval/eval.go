@@ -0,0 +1,214 @@
+package eval
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	conv "github.com/gentcod/nlp-to-sql/converter"
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+)
+
+// Case is a single entry of an evaluation dataset.
+// ExpectedResult takes precedence over ExpectedSQL, which is only executed
+// against the fixture database when no expected result is provided.
+type Case struct {
+	ID             string           `json:"id"`
+	Question       string           `json:"question"`
+	ExpectedSQL    string           `json:"expected_sql"`
+	ExpectedResult []map[string]any `json:"expected_result"`
+}
+
+// CaseResult contains the outcome of evaluating a single case.
+type CaseResult struct {
+	ID           string `json:"id"`
+	Question     string `json:"question"`
+	ExpectedSQL  string `json:"expected_sql,omitempty"`
+	GeneratedSQL string `json:"generated_sql"`
+	ExpectedRows int    `json:"expected_rows"`
+	ActualRows   int    `json:"actual_rows"`
+	Executed     bool   `json:"executed"`
+	Correct      bool   `json:"correct"`
+	Error        string `json:"error,omitempty"`
+	DurationMs   int64  `json:"duration_ms"`
+}
+
+// Report contains the per-case results and aggregate metrics of an evaluation run.
+type Report struct {
+	Dataset           string       `json:"dataset"`
+	DBType            string       `json:"db_type"`
+	LLMType           string       `json:"llm_type"`
+	Model             string       `json:"model"`
+	Total             int          `json:"total"`
+	Executed          int          `json:"executed"`
+	Correct           int          `json:"correct"`
+	ExecutionAccuracy float64      `json:"execution_accuracy"`
+	StartedAt         time.Time    `json:"started_at"`
+	DurationMs        int64        `json:"duration_ms"`
+	Cases             []CaseResult `json:"cases"`
+}
+
+// LoadDataset reads a JSONL dataset where each line is a Case.
+func LoadDataset(path string) ([]Case, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening dataset: %v", err)
+	}
+	defer file.Close()
+
+	var cases []Case
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var c Case
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("invalid case on line %d: %v", line, err)
+		}
+		if c.Question == "" {
+			return nil, fmt.Errorf("invalid case on line %d: question cannot be empty", line)
+		}
+		if c.ExpectedSQL == "" && c.ExpectedResult == nil {
+			return nil, fmt.Errorf("invalid case on line %d: expected_sql or expected_result is required", line)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("%d", line)
+		}
+
+		cases = append(cases, c)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading dataset: %v", err)
+	}
+
+	return cases, nil
+}
+
+// Evaluator runs dataset cases through a Converter against a fixture database.
+type Evaluator struct {
+	converter conv.Converter
+	conn      *sql.DB
+	dbType    string
+	llmType   string
+	schema    map[string]map[string]string
+	caps      conv.QueryCaps
+}
+
+// NewEvaluator creates an Evaluator for the fixture database connection and its schema.
+// Expected and generated queries are run in read-only transactions within the caps.
+func NewEvaluator(converter conv.Converter, conn *sql.DB, dbType, llmType string, schema map[string]map[string]string, caps conv.QueryCaps) *Evaluator {
+	return &Evaluator{
+		converter: converter,
+		conn:      conn,
+		dbType:    dbType,
+		llmType:   llmType,
+		schema:    schema,
+		caps:      caps,
+	}
+}
+
+// Run evaluates every case and computes the execution accuracy,
+// the ratio of cases whose generated query returns the expected result set.
+func (e *Evaluator) Run(cases []Case) Report {
+	report := Report{
+		DBType:    e.dbType,
+		LLMType:   e.llmType,
+		Total:     len(cases),
+		StartedAt: time.Now(),
+		Cases:     make([]CaseResult, 0, len(cases)),
+	}
+
+	for _, c := range cases {
+		result := e.runCase(c)
+		if result.Executed {
+			report.Executed++
+		}
+		if result.Correct {
+			report.Correct++
+		}
+		report.Cases = append(report.Cases, result)
+	}
+
+	if report.Total > 0 {
+		report.ExecutionAccuracy = float64(report.Correct) / float64(report.Total)
+	}
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+
+	return report
+}
+
+func (e *Evaluator) runCase(c Case) (result CaseResult) {
+	start := time.Now()
+	result = CaseResult{
+		ID:          c.ID,
+		Question:    c.Question,
+		ExpectedSQL: c.ExpectedSQL,
+	}
+	defer func() {
+		result.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	expected := c.ExpectedResult
+	if expected == nil {
+		var err error
+		expected, err = e.query(c.ExpectedSQL)
+		if err != nil {
+			result.Error = fmt.Sprintf("error executing expected sql: %v", err)
+			return result
+		}
+	}
+	result.ExpectedRows = len(expected)
+
+	query, err := e.converter.GenerateQuery(e.dbType, e.llmType, c.Question, e.schema)
+	result.GeneratedSQL = query
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	actual, err := e.query(query)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Executed = true
+	result.ActualRows = len(actual)
+	result.Correct = EqualResults(expected, actual)
+
+	return result
+}
+
+// query runs a query against the fixture database in a read-only transaction within the caps,
+// results exceeding the maximum number of rows cannot be compared and are rejected.
+func (e *Evaluator) query(query string) ([]map[string]any, error) {
+	ctx := context.Background()
+	if e.caps.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.caps.Timeout)
+		defer cancel()
+	}
+
+	data, err := db.GetReadOnlyData(ctx, e.conn, query, e.caps.MaxRows)
+	if err != nil {
+		return nil, err
+	}
+	if data.Truncated {
+		return nil, fmt.Errorf("result exceeds %d rows", e.caps.MaxRows)
+	}
+
+	return data.Rows, nil
+}
@@ -0,0 +1,171 @@
+package eval
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	conv "github.com/gentcod/nlp-to-sql/converter"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConverter generates the query of a question after a delay.
+type fakeConverter struct {
+	conv.Converter
+	queries map[string]string
+}
+
+func (converter fakeConverter) GenerateQuery(dbType, llmType, que string, schema map[string]map[string]string) (string, error) {
+	time.Sleep(2 * time.Millisecond)
+	return converter.queries[que], nil
+}
+
+// fakeConnector connects to a database where "SELECT <n>" returns n rows, queries can only run in read-only transactions.
+type fakeConnector struct {
+	readOnly *atomic.Bool
+}
+
+func (connector fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return fakeConn(connector), nil
+}
+
+func (fakeConnector) Driver() driver.Driver { return nil }
+
+type fakeConn fakeConnector
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (conn fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	conn.readOnly.Store(opts.ReadOnly)
+	return fakeTx{}, nil
+}
+
+func (conn fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !conn.readOnly.Load() {
+		return nil, errors.New("query outside of a read-only transaction")
+	}
+
+	count, err := strconv.Atoi(strings.TrimPrefix(query, "SELECT "))
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{count: int64(count)}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	count, read int64
+}
+
+func (*fakeRows) Columns() []string { return []string{"n"} }
+func (*fakeRows) Close() error      { return nil }
+
+func (rows *fakeRows) Next(dest []driver.Value) error {
+	if rows.read == rows.count {
+		return io.EOF
+	}
+	rows.read++
+	dest[0] = rows.read
+	return nil
+}
+
+func TestEqualResults(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	expected := []map[string]any{
+		{"currency": "USD", "count": float64(2)},
+		{"currency": "EUR", "count": float64(1)},
+	}
+	actual := []map[string]any{
+		{"COUNT(*)": []byte("1"), "currency": []byte("EUR")},
+		{"COUNT(*)": int64(2), "currency": "USD"},
+	}
+	require.True(t, EqualResults(expected, actual))
+
+	// differing values, row counts and duplicates are detected
+	require.False(t, EqualResults(expected, actual[:1]))
+	require.False(t, EqualResults(expected, []map[string]any{
+		{"count": int64(2), "currency": "USD"},
+		{"count": int64(2), "currency": "USD"},
+	}))
+	require.False(t, EqualResults(expected, []map[string]any{
+		{"count": int64(2), "currency": "USD"},
+		{"count": int64(3), "currency": "EUR"},
+	}))
+
+	// values are compared with the values of the same column
+	require.False(t, EqualResults(
+		[]map[string]any{{"a": int64(1), "b": int64(2)}},
+		[]map[string]any{{"a": int64(2), "b": int64(1)}},
+	))
+
+	// types are normalised
+	require.True(t, EqualResults(
+		[]map[string]any{{"total": 2.5, "created_at": "2024-05-01T10:00:00Z", "name": nil}},
+		[]map[string]any{{"sum": []byte("2.50"), "created_at": createdAt, "name": nil}},
+	))
+	require.True(t, EqualResults([]map[string]any{}, nil))
+}
+
+func TestLoadDataset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cases.jsonl")
+	data := `{"id": "count-users", "question": "How many users are there?", "expected_sql": "SELECT COUNT(*) FROM users"}
+
+{"question": "How many accounts are there?", "expected_result": [{"count": 114}]}
+`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	cases, err := LoadDataset(path)
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	require.Equal(t, "count-users", cases[0].ID)
+	require.Equal(t, "3", cases[1].ID)
+	require.Equal(t, float64(114), cases[1].ExpectedResult[0]["count"])
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"question": "How many users are there?"}`), 0644))
+	_, err = LoadDataset(path)
+	require.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	conn := sql.OpenDB(fakeConnector{readOnly: &atomic.Bool{}})
+	defer conn.Close()
+
+	converter := fakeConverter{queries: map[string]string{
+		"How many rows?":  "SELECT 1",
+		"List every row.": "SELECT 3",
+	}}
+	evaluator := NewEvaluator(converter, conn, "postgres", "llama", nil, conv.QueryCaps{MaxRows: 2, Timeout: time.Second})
+
+	report := evaluator.Run([]Case{
+		{ID: "expected-sql", Question: "How many rows?", ExpectedSQL: "SELECT 1"},
+		{ID: "expected-result", Question: "How many rows?", ExpectedResult: []map[string]any{{"n": 1}}},
+		{ID: "too-many-rows", Question: "List every row.", ExpectedResult: []map[string]any{{"n": 1}}},
+	})
+	require.Equal(t, 3, report.Total)
+	require.Equal(t, 2, report.Executed)
+	require.Equal(t, 2, report.Correct)
+
+	for _, result := range report.Cases {
+		require.Positive(t, result.DurationMs, result.ID)
+	}
+
+	// results beyond the row cap cannot be compared
+	require.False(t, report.Cases[2].Executed)
+	require.Contains(t, report.Cases[2].Error, "exceeds 2 rows")
+}
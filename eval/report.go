@@ -0,0 +1,67 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteJSON writes the report as indented JSON.
+func (report Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// WriteMarkdown writes the report as a Markdown summary followed by a per-case table.
+func (report Report) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+
+	sb.WriteString("# NL-to-SQL evaluation report\n\n")
+	fmt.Fprintf(&sb, "- Dataset: `%s`\n", report.Dataset)
+	fmt.Fprintf(&sb, "- Database: %s\n", report.DBType)
+	fmt.Fprintf(&sb, "- LLM: %s (%s)\n", report.LLMType, report.Model)
+	fmt.Fprintf(&sb, "- Started at: %s\n", report.StartedAt.Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(&sb, "- Duration: %dms\n\n", report.DurationMs)
+
+	sb.WriteString("| Total | Executed | Correct | Execution accuracy |\n")
+	sb.WriteString("| ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(&sb, "| %d | %d | %d | %.2f%% |\n\n",
+		report.Total, report.Executed, report.Correct, report.ExecutionAccuracy*100)
+
+	sb.WriteString("| ID | Question | Correct | Expected rows | Actual rows | Duration | Generated SQL | Error |\n")
+	sb.WriteString("| --- | --- | :---: | ---: | ---: | ---: | --- | --- |\n")
+	for _, c := range report.Cases {
+		correct := "no"
+		if c.Correct {
+			correct = "yes"
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %d | %d | %dms | %s | %s |\n",
+			escapeCell(c.ID),
+			escapeCell(c.Question),
+			correct,
+			c.ExpectedRows,
+			c.ActualRows,
+			c.DurationMs,
+			codeCell(c.GeneratedSQL),
+			escapeCell(c.Error),
+		)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// escapeCell makes a value safe to use in a Markdown table cell.
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func codeCell(s string) string {
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(escapeCell(s), "`", "'"))
+}
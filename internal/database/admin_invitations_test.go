@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func randomCreateAdminTxParams(t *testing.T, email string) CreateAdminTxParams {
+	harshedPassword, err := util.HashPassword(util.RandomStr(8))
+	require.NoError(t, err)
+
+	return CreateAdminTxParams{
+		CreateAdminAuthParams: CreateAdminAuthParams{
+			ID:              uuid.New(),
+			Email:           email,
+			HarshedPassword: harshedPassword,
+		},
+		CreateAdminParams: CreateAdminParams{
+			ID:       uuid.New(),
+			Username: util.RandomUser(),
+			FullName: util.RandomUser(),
+		},
+	}
+}
+
+func createRandomAdminInvitation(t *testing.T, invitedBy uuid.UUID, email string, expiresAt time.Time) (AdminInvitation, string) {
+	token, hash, err := util.NewSecretToken()
+	require.NoError(t, err)
+
+	invitation, err := testQueries.CreateAdminInvitation(context.Background(), CreateAdminInvitationParams{
+		ID:        uuid.New(),
+		Email:     email,
+		TokenHash: hash,
+		InvitedBy: uuid.NullUUID{UUID: invitedBy, Valid: true},
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, email, invitation.Email)
+	require.False(t, invitation.AcceptedAt.Valid)
+
+	return invitation, token
+}
+
+func TestCreateInvitedAdminTx(t *testing.T) {
+	store := NewStore(testDB)
+	_, inviter := createRandomUserOrAdminTx(t, RoleTypeAdmin)
+
+	email := util.RandomEmail(10)
+	invitation, token := createRandomAdminInvitation(t, inviter.Auth.ID, email, time.Now().Add(time.Hour))
+
+	// invitations can only be accepted with the invited email
+	_, _, err := store.CreateInvitedAdminTx(context.Background(), CreateInvitedAdminTxParams{
+		CreateAdminTxParams: randomCreateAdminTxParams(t, util.RandomEmail(10)),
+		TokenHash:           util.HashSecretToken(token),
+	})
+	require.ErrorIs(t, err, ErrInvalidInvitation)
+
+	// registered emails fail like unregistered ones, so invitations cannot be used to find registered emails
+	_, _, err = store.CreateInvitedAdminTx(context.Background(), CreateInvitedAdminTxParams{
+		CreateAdminTxParams: randomCreateAdminTxParams(t, inviter.Auth.Email),
+		TokenHash:           util.HashSecretToken(token),
+	})
+	require.ErrorIs(t, err, ErrInvalidInvitation)
+
+	arg := randomCreateAdminTxParams(t, email)
+	result, accepted, err := store.CreateInvitedAdminTx(context.Background(), CreateInvitedAdminTxParams{
+		CreateAdminTxParams: arg,
+		TokenHash:           util.HashSecretToken(token),
+	})
+	require.NoError(t, err)
+	require.Equal(t, email, result.Auth.Email)
+	require.Equal(t, RoleTypeAdmin, result.Auth.Role.RoleType)
+	require.Equal(t, invitation.ID, accepted.ID)
+	require.Equal(t, inviter.Auth.ID, accepted.InvitedBy.UUID)
+	require.Equal(t, result.Auth.ID, accepted.AcceptedBy.UUID)
+	require.True(t, accepted.AcceptedAt.Valid)
+
+	// invitations are single-use
+	_, _, err = store.CreateInvitedAdminTx(context.Background(), CreateInvitedAdminTxParams{
+		CreateAdminTxParams: randomCreateAdminTxParams(t, email),
+		TokenHash:           util.HashSecretToken(token),
+	})
+	require.ErrorIs(t, err, ErrInvalidInvitation)
+}
+
+func TestCreateInvitedAdminTxRevokedOrExpired(t *testing.T) {
+	store := NewStore(testDB)
+	_, inviter := createRandomUserOrAdminTx(t, RoleTypeAdmin)
+
+	email := util.RandomEmail(10)
+	_, expired := createRandomAdminInvitation(t, inviter.Auth.ID, email, time.Now().Add(-time.Minute))
+	revoked, revokedToken := createRandomAdminInvitation(t, inviter.Auth.ID, email, time.Now().Add(time.Hour))
+
+	count, err := store.RevokeAdminInvitation(context.Background(), revoked.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	pending, err := store.ListPendingAdminInvitations(context.Background())
+	require.NoError(t, err)
+	for _, invitation := range pending {
+		require.NotEqual(t, revoked.ID, invitation.ID)
+	}
+
+	for _, token := range []string{expired, revokedToken} {
+		_, _, err = store.CreateInvitedAdminTx(context.Background(), CreateInvitedAdminTxParams{
+			CreateAdminTxParams: randomCreateAdminTxParams(t, email),
+			TokenHash:           util.HashSecretToken(token),
+		})
+		require.ErrorIs(t, err, ErrInvalidInvitation)
+	}
+}
+
+func TestCreateBootstrapAdminTx(t *testing.T) {
+	store := NewStore(testDB)
+	createRandomUserOrAdminTx(t, RoleTypeAdmin)
+
+	_, err := store.CreateBootstrapAdminTx(context.Background(), randomCreateAdminTxParams(t, util.RandomEmail(10)))
+	require.ErrorIs(t, err, ErrAdminExists)
+}
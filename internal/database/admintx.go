@@ -2,12 +2,22 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrAdminExists is returned when bootstrapping the first admin after an admin has been created.
+	ErrAdminExists = errors.New("an admin account already exists")
+	// ErrInvalidInvitation is returned when an invitation does not exist, is not for the email,
+	// has expired, has been revoked or has already been accepted.
+	ErrInvalidInvitation = errors.New("invitation is invalid or has expired")
+)
+
 type CreateAdminTxParams struct {
 	CreateAdminAuthParams CreateAdminAuthParams
 	CreateAdminParams     CreateAdminParams
@@ -25,21 +35,104 @@ func (store *SQLStore) CreateAdminTx(ctx context.Context, arg CreateAdminTxParam
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
-		result.Auth, err = q.CreateAdminAuth(ctx, arg.CreateAdminAuthParams)
+		result, err = createAdminRecords(ctx, q, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// CreateBootstrapAdminTx creates the first admin, it fails with ErrAdminExists once any admin has been created.
+func (store *SQLStore) CreateBootstrapAdminTx(ctx context.Context, arg CreateAdminTxParams) (AdminTxResult, error) {
+	var result AdminTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// concurrent bootstraps are serialized so that only one of them can create the first admin
+		err := q.LockAdminBootstrap(ctx)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to lock admin bootstrap: %w", err)
+		}
+
+		count, err := q.CountAdmins(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count admins: %w", err)
+		}
+		if count > 0 {
+			return ErrAdminExists
+		}
+
+		result, err = createAdminRecords(ctx, q, arg)
+		return err
+	})
+
+	return result, err
+}
+
+type CreateInvitedAdminTxParams struct {
+	CreateAdminTxParams
+	TokenHash string
+}
+
+// CreateInvitedAdminTx creates an admin and accepts the invitation of the token, which must be for the admin's email.
+// The invitation is locked before the account is created, so that an invalid invitation fails the same way
+// whether or not the email is already registered.
+func (store *SQLStore) CreateInvitedAdminTx(ctx context.Context, arg CreateInvitedAdminTxParams) (AdminTxResult, AdminInvitation, error) {
+	var result AdminTxResult
+	var invitation AdminInvitation
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		_, err = q.LockPendingAdminInvitation(ctx, LockPendingAdminInvitationParams{
+			TokenHash: arg.TokenHash,
+			Email:     arg.CreateAdminAuthParams.Email,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidInvitation
+			}
+			return fmt.Errorf("failed to lock invitation: %w", err)
 		}
 
-		arg.CreateAdminParams.AuthID = result.Auth.ID
-		result.Admin, err = q.CreateAdmin(ctx, arg.CreateAdminParams)
+		result, err = createAdminRecords(ctx, q, arg.CreateAdminTxParams)
 		if err != nil {
 			return err
 		}
 
+		invitation, err = q.AcceptAdminInvitation(ctx, AcceptAdminInvitationParams{
+			AcceptedBy: uuid.NullUUID{UUID: result.Auth.ID, Valid: true},
+			TokenHash:  arg.TokenHash,
+			Email:      result.Auth.Email,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidInvitation
+			}
+			return fmt.Errorf("failed to accept invitation: %w", err)
+		}
+
 		return nil
 	})
 
-	return result, err
+	return result, invitation, err
+}
+
+func createAdminRecords(ctx context.Context, q *Queries, arg CreateAdminTxParams) (AdminTxResult, error) {
+	var result AdminTxResult
+	var err error
+
+	result.Auth, err = q.CreateAdminAuth(ctx, arg.CreateAdminAuthParams)
+	if err != nil {
+		return result, err
+	}
+
+	arg.CreateAdminParams.AuthID = result.Auth.ID
+	result.Admin, err = q.CreateAdmin(ctx, arg.CreateAdminParams)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
 }
 
 type UpdateAdminTxParams struct {
@@ -85,7 +178,7 @@ func (store *SQLStore) DeleteAdminTx(ctx context.Context, authID uuid.UUID, admi
 
 		arg := DeleteAuthParams{
 			ID:        authID,
-			UpdatedAt: time.Now(),
+			DeletedAt: sql.NullTime{Time: time.Now(), Valid: true},
 		}
 		err = q.DeleteAuth(ctx, arg)
 		if err != nil {
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Audited actions, named after the account type and what happened to it.
+const (
+	AuditUserSignup                 = "user.signup"
+	AuditUserLogin                  = "user.login"
+	AuditUserLoginFailed            = "user.login_failed"
+	AuditUserLoginUnverified        = "user.login_unverified"
+	AuditUserUpdated                = "user.updated"
+	AuditUserPasswordChanged        = "user.password_changed"
+	AuditUserDeleted                = "user.deleted"
+	AuditUserRestricted             = "user.restricted"
+	AuditUserUnrestricted           = "user.unrestricted"
+	AuditUserRestored               = "user.restored"
+	AuditUserLimitsUpdated          = "user.limits_updated"
+	AuditUserEmailVerified          = "user.email_verified"
+	AuditUserPasswordResetRequested = "user.password_reset_requested"
+	AuditUserPasswordReset          = "user.password_reset"
+
+	AuditAdminSignup          = "admin.signup"
+	AuditAdminLogin           = "admin.login"
+	AuditAdminLoginFailed     = "admin.login_failed"
+	AuditAdminUpdated         = "admin.updated"
+	AuditAdminPasswordChanged = "admin.password_changed"
+	AuditAdminMFAEnabled      = "admin.mfa_enabled"
+	AuditAdminMFADisabled     = "admin.mfa_disabled"
+	AuditAdminInvited         = "admin.invited"
+	AuditAdminInviteRevoked   = "admin.invite_revoked"
+
+	AuditConnectionRuleCreated = "connection_rule.created"
+	AuditConnectionRuleDeleted = "connection_rule.deleted"
+
+	AuditOrganizationMemberAdded       = "organization.member_added"
+	AuditOrganizationMemberUpdated     = "organization.member_updated"
+	AuditOrganizationMemberRemoved     = "organization.member_removed"
+	AuditOrganizationConnectionCreated = "organization.connection_created"
+	AuditOrganizationConnectionUpdated = "organization.connection_updated"
+	AuditOrganizationConnectionDeleted = "organization.connection_deleted"
+)
+
+// AuditEventParams describes an audited action. ActorID or TargetID are omitted when uuid.Nil.
+type AuditEventParams struct {
+	ActorID   uuid.UUID
+	TargetID  uuid.UUID
+	Action    string
+	Metadata  map[string]any
+	IpAddress string
+}
+
+// RecordAuditEvent stores an audit event.
+func (store *SQLStore) RecordAuditEvent(ctx context.Context, arg AuditEventParams) (AuditEvent, error) {
+	return recordAuditEvent(ctx, store.Queries, arg)
+}
+
+func recordAuditEvent(ctx context.Context, q *Queries, arg AuditEventParams) (AuditEvent, error) {
+	metadata := arg.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return AuditEvent{}, fmt.Errorf("failed to encode audit metadata: %w", err)
+	}
+
+	event, err := q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		ActorID: uuid.NullUUID{
+			UUID:  arg.ActorID,
+			Valid: arg.ActorID != uuid.Nil,
+		},
+		TargetID: uuid.NullUUID{
+			UUID:  arg.TargetID,
+			Valid: arg.TargetID != uuid.Nil,
+		},
+		Action:    arg.Action,
+		Metadata:  data,
+		IpAddress: arg.IpAddress,
+	})
+	if err != nil {
+		return event, fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return event, nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAuditEvent(t *testing.T) {
+	store := NewStore(testDB)
+
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+
+	arg := AuditEventParams{
+		TargetID:  userTx.Auth.ID,
+		Action:    AuditUserLoginFailed,
+		Metadata:  map[string]any{"email": userTx.Auth.Email},
+		IpAddress: "127.0.0.1",
+	}
+
+	event, err := store.RecordAuditEvent(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, event.ID)
+	require.False(t, event.ActorID.Valid)
+	require.Equal(t, userTx.Auth.ID, event.TargetID.UUID)
+	require.Equal(t, arg.Action, event.Action)
+	require.Equal(t, arg.IpAddress, event.IpAddress)
+	require.NotZero(t, event.CreatedAt)
+
+	var metadata map[string]any
+	err = json.Unmarshal(event.Metadata, &metadata)
+	require.NoError(t, err)
+	require.Equal(t, userTx.Auth.Email, metadata["email"])
+}
+
+func TestListAuditEvents(t *testing.T) {
+	store := NewStore(testDB)
+
+	actorID := uuid.New()
+	action := "test." + util.RandomStr(8)
+
+	var events []AuditEvent
+	for i := 0; i < 3; i++ {
+		event, err := store.RecordAuditEvent(context.Background(), AuditEventParams{
+			ActorID: actorID,
+			Action:  action,
+		})
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+
+	page, err := store.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		ActorID:   uuid.NullUUID{UUID: actorID, Valid: true},
+		Action:    sql.NullString{String: action, Valid: true},
+		PageLimit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, events[2].ID, page[0].ID)
+	require.Equal(t, events[1].ID, page[1].ID)
+
+	page, err = store.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Cursor:    sql.NullInt64{Int64: page[1].ID, Valid: true},
+		ActorID:   uuid.NullUUID{UUID: actorID, Valid: true},
+		PageLimit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, events[0].ID, page[0].ID)
+}
@@ -16,7 +16,7 @@ import (
 const createAdminAuth = `-- name: CreateAdminAuth :one
 INSERT INTO auth (id, email, harshed_password, role)
 VALUES ($1, $2, $3, 'admin')
-RETURNING id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role
+RETURNING id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role, last_login_at, failed_attempts, locked_until, email_verified_at, deleted_at, last_failed_login_at
 `
 
 type CreateAdminAuthParams struct {
@@ -38,6 +38,12 @@ func (q *Queries) CreateAdminAuth(ctx context.Context, arg CreateAdminAuthParams
 		&i.Restricted,
 		&i.Deleted,
 		&i.Role,
+		&i.LastLoginAt,
+		&i.FailedAttempts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.DeletedAt,
+		&i.LastFailedLoginAt,
 	)
 	return i, err
 }
@@ -45,7 +51,7 @@ func (q *Queries) CreateAdminAuth(ctx context.Context, arg CreateAdminAuthParams
 const createAuth = `-- name: CreateAuth :one
 INSERT INTO auth (id, email, harshed_password)
 VALUES ($1, $2, $3)
-RETURNING id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role
+RETURNING id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role, last_login_at, failed_attempts, locked_until, email_verified_at, deleted_at, last_failed_login_at
 `
 
 type CreateAuthParams struct {
@@ -67,36 +73,46 @@ func (q *Queries) CreateAuth(ctx context.Context, arg CreateAuthParams) (Auth, e
 		&i.Restricted,
 		&i.Deleted,
 		&i.Role,
+		&i.LastLoginAt,
+		&i.FailedAttempts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.DeletedAt,
+		&i.LastFailedLoginAt,
 	)
 	return i, err
 }
 
 const deleteAuth = `-- name: DeleteAuth :exec
 UPDATE auth
-SET deleted = TRUE, updated_at = $2
+SET deleted = TRUE, deleted_at = $2, updated_at = $2
 WHERE id = $1
 `
 
 type DeleteAuthParams struct {
-	ID        uuid.UUID `json:"id"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID    `json:"id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
 }
 
 func (q *Queries) DeleteAuth(ctx context.Context, arg DeleteAuthParams) error {
-	_, err := q.db.ExecContext(ctx, deleteAuth, arg.ID, arg.UpdatedAt)
+	_, err := q.db.ExecContext(ctx, deleteAuth, arg.ID, arg.DeletedAt)
 	return err
 }
 
 const deleteUserAuthCron = `-- name: DeleteUserAuthCron :many
-DELETE FROM auth 
-WHERE id IN (
+WITH expired AS (
    SELECT id FROM
    auth WHERE role = 'user'
    and deleted = TRUE
-   and updated_at < NOW() - INTERVAL '30 days'
+   and deleted_at < NOW() - INTERVAL '30 days'
    LIMIT $1
+), expired_users AS (
+   DELETE FROM users
+   WHERE auth_id IN (SELECT id FROM expired)
 )
-RETURNING id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role
+DELETE FROM auth 
+WHERE id IN (SELECT id FROM expired)
+RETURNING id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role, last_login_at, failed_attempts, locked_until, email_verified_at, deleted_at, last_failed_login_at
 `
 
 func (q *Queries) DeleteUserAuthCron(ctx context.Context, limit int32) ([]Auth, error) {
@@ -118,6 +134,12 @@ func (q *Queries) DeleteUserAuthCron(ctx context.Context, limit int32) ([]Auth,
 			&i.Restricted,
 			&i.Deleted,
 			&i.Role,
+			&i.LastLoginAt,
+			&i.FailedAttempts,
+			&i.LockedUntil,
+			&i.EmailVerifiedAt,
+			&i.DeletedAt,
+			&i.LastFailedLoginAt,
 		); err != nil {
 			return nil, err
 		}
@@ -167,7 +189,7 @@ SELECT COUNT(*)
    FROM auth 
 WHERE role = 'user' 
    and deleted = TRUE
-   AND updated_at < NOW() - INTERVAL '30 days'
+   AND deleted_at < NOW() - INTERVAL '30 days'
 `
 
 func (q *Queries) GetDeletedUsers(ctx context.Context) (int64, error) {
@@ -177,6 +199,77 @@ func (q *Queries) GetDeletedUsers(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const lockAuth = `-- name: LockAuth :exec
+UPDATE auth
+SET locked_until = $2
+WHERE id = $1
+`
+
+type LockAuthParams struct {
+	ID          uuid.UUID    `json:"id"`
+	LockedUntil sql.NullTime `json:"locked_until"`
+}
+
+func (q *Queries) LockAuth(ctx context.Context, arg LockAuthParams) error {
+	_, err := q.db.ExecContext(ctx, lockAuth, arg.ID, arg.LockedUntil)
+	return err
+}
+
+const recordAuthLogin = `-- name: RecordAuthLogin :exec
+UPDATE auth
+SET failed_attempts = 0, locked_until = NULL, last_login_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RecordAuthLogin(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, recordAuthLogin, id)
+	return err
+}
+
+const recordAuthLoginFailure = `-- name: RecordAuthLoginFailure :one
+UPDATE auth
+SET failed_attempts = CASE
+      WHEN last_failed_login_at < $1 THEN 1
+      ELSE failed_attempts + 1
+   END,
+   last_failed_login_at = NOW()
+WHERE id = $2
+RETURNING failed_attempts
+`
+
+type RecordAuthLoginFailureParams struct {
+	WindowStart time.Time `json:"window_start"`
+	ID          uuid.UUID `json:"id"`
+}
+
+func (q *Queries) RecordAuthLoginFailure(ctx context.Context, arg RecordAuthLoginFailureParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, recordAuthLoginFailure, arg.WindowStart, arg.ID)
+	var failed_attempts int32
+	err := row.Scan(&failed_attempts)
+	return failed_attempts, err
+}
+
+const restoreAuth = `-- name: RestoreAuth :execrows
+UPDATE auth
+SET deleted = FALSE, deleted_at = NULL, updated_at = $2
+WHERE id = $1
+   AND deleted = TRUE
+   AND deleted_at > NOW() - INTERVAL '30 days'
+`
+
+type RestoreAuthParams struct {
+	ID        uuid.UUID `json:"id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) RestoreAuth(ctx context.Context, arg RestoreAuthParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreAuth, arg.ID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const restrictAuth = `-- name: RestrictAuth :exec
 UPDATE auth
 SET restricted = TRUE, updated_at = $2
@@ -193,15 +286,49 @@ func (q *Queries) RestrictAuth(ctx context.Context, arg RestrictAuthParams) erro
 	return err
 }
 
+const unlockAuth = `-- name: UnlockAuth :exec
+UPDATE auth
+SET failed_attempts = 0, locked_until = NULL
+WHERE id = $1
+`
+
+func (q *Queries) UnlockAuth(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, unlockAuth, id)
+	return err
+}
+
+const unrestrictAuth = `-- name: UnrestrictAuth :execrows
+UPDATE auth
+SET restricted = FALSE, updated_at = $2
+WHERE id = $1 AND restricted = TRUE
+`
+
+type UnrestrictAuthParams struct {
+	ID        uuid.UUID `json:"id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (q *Queries) UnrestrictAuth(ctx context.Context, arg UnrestrictAuthParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unrestrictAuth, arg.ID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateAuth = `-- name: UpdateAuth :one
 UPDATE auth 
 SET 
    email = COALESCE($1, email),
    harshed_password = COALESCE($2, harshed_password), 
    password_changed_at = COALESCE($3, password_changed_at),
+   email_verified_at = CASE
+      WHEN $1 IS NOT NULL AND $1 <> email THEN NULL
+      ELSE email_verified_at
+   END,
    updated_at = $4
 WHERE id = $5
-RETURNING id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role
+RETURNING id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role, last_login_at, failed_attempts, locked_until, email_verified_at, deleted_at, last_failed_login_at
 `
 
 type UpdateAuthParams struct {
@@ -231,12 +358,18 @@ func (q *Queries) UpdateAuth(ctx context.Context, arg UpdateAuthParams) (Auth, e
 		&i.Restricted,
 		&i.Deleted,
 		&i.Role,
+		&i.LastLoginAt,
+		&i.FailedAttempts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.DeletedAt,
+		&i.LastFailedLoginAt,
 	)
 	return i, err
 }
 
 const validateAuth = `-- name: ValidateAuth :one
-SELECT id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role FROM auth
+SELECT id, email, harshed_password, password_changed_at, created_at, updated_at, restricted, deleted, role, last_login_at, failed_attempts, locked_until, email_verified_at, deleted_at, last_failed_login_at FROM auth
 WHERE email = $1 LIMIT 1
 `
 
@@ -253,6 +386,23 @@ func (q *Queries) ValidateAuth(ctx context.Context, email string) (Auth, error)
 		&i.Restricted,
 		&i.Deleted,
 		&i.Role,
+		&i.LastLoginAt,
+		&i.FailedAttempts,
+		&i.LockedUntil,
+		&i.EmailVerifiedAt,
+		&i.DeletedAt,
+		&i.LastFailedLoginAt,
 	)
 	return i, err
 }
+
+const verifyAuthEmail = `-- name: VerifyAuthEmail :exec
+UPDATE auth
+SET email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND email_verified_at IS NULL
+`
+
+func (q *Queries) VerifyAuthEmail(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, verifyAuthEmail, id)
+	return err
+}
@@ -2,9 +2,11 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
+	"github.com/gentcod/nlp-to-sql/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -28,3 +30,87 @@ func TestRestrictUserAuth(t *testing.T) {
 	require.False(t, userTx.Auth.Restricted)
 	require.True(t, result.Restricted)
 }
+
+func TestRecordAuthLoginFailure(t *testing.T) {
+	store := NewStore(testDB)
+
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	require.Zero(t, userTx.Auth.FailedAttempts)
+	require.False(t, userTx.Auth.LastLoginAt.Valid)
+
+	arg := RecordAuthLoginFailureParams{
+		ID:          userTx.Auth.ID,
+		WindowStart: time.Now().Add(-time.Minute),
+	}
+	for i := 1; i <= 2; i++ {
+		attempts, err := store.RecordAuthLoginFailure(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, int32(i), attempts)
+	}
+
+	// failures before the window are not counted
+	arg.WindowStart = time.Now().Add(time.Minute)
+	attempts, err := store.RecordAuthLoginFailure(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempts)
+
+	arg.WindowStart = time.Now().Add(-time.Minute)
+	attempts, err = store.RecordAuthLoginFailure(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), attempts)
+
+	lockedUntil := time.Now().Add(time.Minute)
+	err = store.LockAuth(context.Background(), LockAuthParams{
+		ID:          userTx.Auth.ID,
+		LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true},
+	})
+	require.NoError(t, err)
+
+	auth, err := store.ValidateAuth(context.Background(), userTx.Auth.Email)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), auth.FailedAttempts)
+	require.WithinDuration(t, lockedUntil, auth.LockedUntil.Time, time.Second)
+
+	err = store.RecordAuthLogin(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
+
+	auth, err = store.ValidateAuth(context.Background(), userTx.Auth.Email)
+	require.NoError(t, err)
+	require.Zero(t, auth.FailedAttempts)
+	require.False(t, auth.LockedUntil.Valid)
+	require.True(t, auth.LastLoginAt.Valid)
+}
+
+func TestRecordLoginAttemptFailure(t *testing.T) {
+	store := NewStore(testDB)
+
+	ipAddress := util.RandomStr(12)
+	_, err := store.GetLoginAttempt(context.Background(), ipAddress)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg := RecordLoginAttemptFailureParams{
+		IpAddress:   ipAddress,
+		WindowStart: time.Now().Add(-time.Minute),
+	}
+	for i := 1; i <= 2; i++ {
+		attempt, err := store.RecordLoginAttemptFailure(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, int32(i), attempt.FailedAttempts)
+	}
+
+	// failures before the window are not counted
+	arg.WindowStart = time.Now().Add(time.Minute)
+	attempt, err := store.RecordLoginAttemptFailure(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempt.FailedAttempts)
+
+	err = store.LockLoginAttempt(context.Background(), LockLoginAttemptParams{
+		IpAddress:   ipAddress,
+		LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	attempt, err = store.GetLoginAttempt(context.Background(), ipAddress)
+	require.NoError(t, err)
+	require.True(t, attempt.LockedUntil.Valid)
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Purposes of single-use auth tokens.
+const (
+	AuthTokenEmailVerification = "email_verification"
+	AuthTokenPasswordReset     = "password_reset"
+	AuthTokenMFAChallenge      = "mfa_challenge"
+)
+
+// ErrInvalidAuthToken is returned when a token does not exist, has expired or has already been used.
+var ErrInvalidAuthToken = errors.New("token is invalid or has expired")
+
+type IssueAuthTokenTxParams struct {
+	AuthID    uuid.UUID
+	Purpose   string
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+// IssueAuthTokenTx invalidates the unused tokens of the account for the purpose and creates a new one,
+// so that only the most recently issued token can be used.
+func (store *SQLStore) IssueAuthTokenTx(ctx context.Context, arg IssueAuthTokenTxParams) (AuthToken, error) {
+	var token AuthToken
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.InvalidateAuthTokens(ctx, InvalidateAuthTokensParams{
+			AuthID:  arg.AuthID,
+			Purpose: arg.Purpose,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to invalidate tokens: %w", err)
+		}
+
+		token, err = q.CreateAuthToken(ctx, CreateAuthTokenParams{
+			ID:        uuid.New(),
+			AuthID:    arg.AuthID,
+			Purpose:   arg.Purpose,
+			TokenHash: arg.TokenHash,
+			ExpiresAt: arg.ExpiresAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create token: %w", err)
+		}
+
+		return nil
+	})
+
+	return token, err
+}
+
+// VerifyEmailTx uses an email verification token and marks the email of its account as verified.
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, tokenHash string) (AuthToken, error) {
+	var token AuthToken
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		token, err = consumeAuthToken(ctx, q, tokenHash, AuthTokenEmailVerification)
+		if err != nil {
+			return err
+		}
+
+		err = q.VerifyAuthEmail(ctx, token.AuthID)
+		if err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+
+		return nil
+	})
+
+	return token, err
+}
+
+type ResetPasswordTxParams struct {
+	TokenHash       string
+	HarshedPassword string
+}
+
+// ResetPasswordTx uses a password reset token and sets the new password of its account.
+// The email is also marked as verified as the reset token was delivered to it, and the failed logins
+// and lockout of the account are cleared so that the new password can be used right away.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (AuthToken, error) {
+	var token AuthToken
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		token, err = consumeAuthToken(ctx, q, arg.TokenHash, AuthTokenPasswordReset)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UpdateAuth(ctx, UpdateAuthParams{
+			ID: token.AuthID,
+			HarshedPassword: sql.NullString{
+				String: arg.HarshedPassword,
+				Valid:  true,
+			},
+			PasswordChangedAt: sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			},
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		err = q.VerifyAuthEmail(ctx, token.AuthID)
+		if err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+
+		err = q.UnlockAuth(ctx, token.AuthID)
+		if err != nil {
+			return fmt.Errorf("failed to unlock auth: %w", err)
+		}
+
+		return nil
+	})
+
+	return token, err
+}
+
+func consumeAuthToken(ctx context.Context, q *Queries, tokenHash, purpose string) (AuthToken, error) {
+	token, err := q.UseAuthToken(ctx, UseAuthTokenParams{
+		TokenHash: tokenHash,
+		Purpose:   purpose,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return token, ErrInvalidAuthToken
+		}
+		return token, fmt.Errorf("failed to use token: %w", err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/stretchr/testify/require"
+)
+
+func issueRandomAuthToken(t *testing.T, store Store, auth Auth, purpose string, expiresAt time.Time) string {
+	token, hash, err := util.NewSecretToken()
+	require.NoError(t, err)
+
+	authToken, err := store.IssueAuthTokenTx(context.Background(), IssueAuthTokenTxParams{
+		AuthID:    auth.ID,
+		Purpose:   purpose,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, auth.ID, authToken.AuthID)
+	require.Equal(t, purpose, authToken.Purpose)
+	require.Equal(t, hash, authToken.TokenHash)
+	require.False(t, authToken.UsedAt.Valid)
+
+	return token
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	require.False(t, userTx.Auth.EmailVerifiedAt.Valid)
+
+	// only the most recently issued token can be used
+	previous := issueRandomAuthToken(t, store, userTx.Auth, AuthTokenEmailVerification, time.Now().Add(time.Hour))
+	token := issueRandomAuthToken(t, store, userTx.Auth, AuthTokenEmailVerification, time.Now().Add(time.Hour))
+
+	_, err := store.VerifyEmailTx(context.Background(), util.HashSecretToken(previous))
+	require.ErrorIs(t, err, ErrInvalidAuthToken)
+
+	authToken, err := store.VerifyEmailTx(context.Background(), util.HashSecretToken(token))
+	require.NoError(t, err)
+	require.Equal(t, userTx.Auth.ID, authToken.AuthID)
+	require.True(t, authToken.UsedAt.Valid)
+
+	auth, err := store.ValidateAuth(context.Background(), userTx.Auth.Email)
+	require.NoError(t, err)
+	require.True(t, auth.EmailVerifiedAt.Valid)
+
+	// tokens are single-use
+	_, err = store.VerifyEmailTx(context.Background(), util.HashSecretToken(token))
+	require.ErrorIs(t, err, ErrInvalidAuthToken)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+
+	expired := issueRandomAuthToken(t, store, userTx.Auth, AuthTokenPasswordReset, time.Now().Add(-time.Minute))
+	_, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:       util.HashSecretToken(expired),
+		HarshedPassword: "new-password-hash",
+	})
+	require.ErrorIs(t, err, ErrInvalidAuthToken)
+
+	// tokens cannot be used for another purpose
+	verification := issueRandomAuthToken(t, store, userTx.Auth, AuthTokenEmailVerification, time.Now().Add(time.Hour))
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:       util.HashSecretToken(verification),
+		HarshedPassword: "new-password-hash",
+	})
+	require.ErrorIs(t, err, ErrInvalidAuthToken)
+
+	_, err = store.RecordAuthLoginFailure(context.Background(), RecordAuthLoginFailureParams{
+		ID:          userTx.Auth.ID,
+		WindowStart: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	err = store.LockAuth(context.Background(), LockAuthParams{
+		ID:          userTx.Auth.ID,
+		LockedUntil: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+
+	token := issueRandomAuthToken(t, store, userTx.Auth, AuthTokenPasswordReset, time.Now().Add(time.Hour))
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:       util.HashSecretToken(token),
+		HarshedPassword: "new-password-hash",
+	})
+	require.NoError(t, err)
+
+	auth, err := store.ValidateAuth(context.Background(), userTx.Auth.Email)
+	require.NoError(t, err)
+	require.Equal(t, "new-password-hash", auth.HarshedPassword)
+	require.True(t, auth.PasswordChangedAt.After(userTx.Auth.PasswordChangedAt))
+	require.True(t, auth.EmailVerifiedAt.Valid)
+	require.Zero(t, auth.FailedAttempts)
+	require.False(t, auth.LockedUntil.Valid)
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheEntry(t *testing.T) {
+	store := NewStore(testDB)
+
+	key := util.RandomStr(16)
+	value := json.RawMessage(`{"count": 114}`)
+
+	err := store.SetCacheEntry(context.Background(), SetCacheEntryParams{
+		Key:   key,
+		Value: value,
+	})
+	require.NoError(t, err)
+
+	result, err := store.GetCacheEntry(context.Background(), key)
+	require.NoError(t, err)
+	require.JSONEq(t, string(value), string(result))
+
+	// entries are overwritten and no longer returned once expired
+	err = store.SetCacheEntry(context.Background(), SetCacheEntryParams{
+		Key:   key,
+		Value: value,
+		ExpiresAt: sql.NullTime{
+			Time:  time.Now().Add(-time.Minute),
+			Valid: true,
+		},
+	})
+	require.NoError(t, err)
+
+	result, err = store.GetCacheEntry(context.Background(), key)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.Empty(t, result)
+
+	deleted, err := store.DeleteExpiredCacheEntries(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionRules(t *testing.T) {
+	_, admin := createRandomUserOrAdminTx(t, RoleTypeAdmin)
+
+	rule, err := testQueries.CreateConnectionRule(context.Background(), CreateConnectionRuleParams{
+		ID:          uuid.New(),
+		Target:      "10.0.0.0/8",
+		Port:        sql.NullInt32{Int32: 5432, Valid: true},
+		DbType:      sql.NullString{String: "postgres", Valid: true},
+		Description: "internal databases",
+		CreatedBy:   uuid.NullUUID{UUID: admin.Auth.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.0/8", rule.Target)
+	require.Equal(t, int32(5432), rule.Port.Int32)
+
+	rules, err := testQueries.ListConnectionRules(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, rules, rule)
+
+	deleted, err := testQueries.DeleteConnectionRule(context.Background(), rule.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	deleted, err = testQueries.DeleteConnectionRule(context.Background(), rule.ID)
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+}
@@ -18,12 +18,12 @@ func TestDBCron(t *testing.T) {
 	for i := 0; i < 4; i++ {
 		userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
 
-		err := store.DeleteUserTx(context.Background(), userTx.Auth.ID, userTx.User.ID)
+		err := store.DeleteUserTx(context.Background(), userTx.Auth.ID)
 		require.NoError(t, err)
 
 		_, err = testDB.Exec(`
 		UPDATE auth
-		SET updated_at = NOW() - INTERVAL '30 days'
+		SET deleted_at = NOW() - INTERVAL '30 days'
 		WHERE id = $1`, userTx.Auth.ID)
 		require.NoError(t, err)
 	}
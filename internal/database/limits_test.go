@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetUserLimits(t *testing.T) {
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+
+	_, err := testQueries.GetUserLimits(context.Background(), userTx.Auth.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	limits, err := testQueries.SetUserLimits(context.Background(), SetUserLimitsParams{
+		AuthID:            userTx.Auth.ID,
+		RequestsPerMinute: sql.NullInt32{Int32: 30, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(30), limits.RequestsPerMinute.Int32)
+	require.False(t, limits.Burst.Valid)
+	require.False(t, limits.MonthlyTokenQuota.Valid)
+
+	limits, err = testQueries.SetUserLimits(context.Background(), SetUserLimitsParams{
+		AuthID:            userTx.Auth.ID,
+		MonthlyTokenQuota: sql.NullInt64{Int64: 5000, Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, limits.RequestsPerMinute.Valid)
+	require.Equal(t, int64(5000), limits.MonthlyTokenQuota.Int64)
+
+	got, err := testQueries.GetUserLimits(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
+	require.Equal(t, limits.MonthlyTokenQuota, got.MonthlyTokenQuota)
+}
+
+func TestAddLLMUsage(t *testing.T) {
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	period := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := testQueries.GetLLMUsage(context.Background(), GetLLMUsageParams{
+		AuthID: userTx.Auth.ID,
+		Period: period,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	for i := 0; i < 2; i++ {
+		_, err = testQueries.AddLLMUsage(context.Background(), AddLLMUsageParams{
+			AuthID:           userTx.Auth.ID,
+			Period:           period,
+			PromptTokens:     100,
+			CompletionTokens: 20,
+		})
+		require.NoError(t, err)
+	}
+
+	usage, err := testQueries.GetLLMUsage(context.Background(), GetLLMUsageParams{
+		AuthID: userTx.Auth.ID,
+		Period: period,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(200), usage.PromptTokens)
+	require.Equal(t, int64(40), usage.CompletionTokens)
+	require.Equal(t, int32(2), usage.Requests)
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrTOTPNotPending is returned when enabling TOTP for an account that has not enrolled or has already enabled it.
+var ErrTOTPNotPending = errors.New("two-factor authentication is not pending verification")
+
+type EnableTOTPTxParams struct {
+	AuthID uuid.UUID
+	// Step is the time step of the code that verified the enrolment, it cannot be used again.
+	Step               int64
+	RecoveryCodeHashes []string
+}
+
+// EnableTOTPTx confirms the pending TOTP enrolment of the account and replaces its recovery codes.
+func (store *SQLStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		confirmed, err := q.ConfirmTOTPCredential(ctx, ConfirmTOTPCredentialParams{
+			AuthID:       arg.AuthID,
+			LastUsedStep: arg.Step,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to confirm totp: %w", err)
+		}
+		if confirmed == 0 {
+			return ErrTOTPNotPending
+		}
+
+		err = q.DeleteRecoveryCodes(ctx, arg.AuthID)
+		if err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+
+		for _, hash := range arg.RecoveryCodeHashes {
+			err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				ID:       uuid.New(),
+				AuthID:   arg.AuthID,
+				CodeHash: hash,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create recovery code: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// DisableTOTPTx removes the TOTP credential and the recovery codes of the account.
+func (store *SQLStore) DisableTOTPTx(ctx context.Context, authID uuid.UUID) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteTOTPCredential(ctx, authID)
+		if err != nil {
+			return fmt.Errorf("failed to delete totp: %w", err)
+		}
+
+		err = q.DeleteRecoveryCodes(ctx, authID)
+		if err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+
+		return nil
+	})
+}
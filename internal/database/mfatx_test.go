@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, adminTx := createRandomUserOrAdminTx(t, RoleTypeAdmin)
+	authID := adminTx.Auth.ID
+
+	// an account that has not enrolled cannot enable TOTP
+	err := store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{AuthID: authID, Step: 1})
+	require.ErrorIs(t, err, ErrTOTPNotPending)
+
+	credential, err := store.CreateTOTPCredential(context.Background(), CreateTOTPCredentialParams{
+		AuthID: authID,
+		Secret: util.RandomStr(32),
+	})
+	require.NoError(t, err)
+	require.False(t, credential.ConfirmedAt.Valid)
+
+	// enrolling again replaces the pending secret
+	credential, err = store.CreateTOTPCredential(context.Background(), CreateTOTPCredentialParams{
+		AuthID: authID,
+		Secret: util.RandomStr(32),
+	})
+	require.NoError(t, err)
+
+	hashes := []string{util.HashSecretToken("code-1"), util.HashSecretToken("code-2")}
+	err = store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{
+		AuthID:             authID,
+		Step:               10,
+		RecoveryCodeHashes: hashes,
+	})
+	require.NoError(t, err)
+
+	enabled, err := store.GetTOTPCredential(context.Background(), authID)
+	require.NoError(t, err)
+	require.Equal(t, credential.Secret, enabled.Secret)
+	require.True(t, enabled.ConfirmedAt.Valid)
+	require.Equal(t, int64(10), enabled.LastUsedStep)
+
+	count, err := store.CountRecoveryCodes(context.Background(), authID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// an enabled credential is not replaced by enrolling again
+	_, err = store.CreateTOTPCredential(context.Background(), CreateTOTPCredentialParams{
+		AuthID: authID,
+		Secret: util.RandomStr(32),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{AuthID: authID, Step: 11})
+	require.ErrorIs(t, err, ErrTOTPNotPending)
+
+	// steps and recovery codes cannot be reused
+	used, err := store.UseTOTPStep(context.Background(), UseTOTPStepParams{AuthID: authID, LastUsedStep: 10})
+	require.NoError(t, err)
+	require.Zero(t, used)
+
+	used, err = store.UseTOTPStep(context.Background(), UseTOTPStepParams{AuthID: authID, LastUsedStep: 11})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+
+	used, err = store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{AuthID: authID, CodeHash: hashes[0]})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), used)
+
+	used, err = store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{AuthID: authID, CodeHash: hashes[0]})
+	require.NoError(t, err)
+	require.Zero(t, used)
+
+	count, err = store.CountRecoveryCodes(context.Background(), authID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestDisableTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, adminTx := createRandomUserOrAdminTx(t, RoleTypeAdmin)
+	authID := adminTx.Auth.ID
+
+	_, err := store.CreateTOTPCredential(context.Background(), CreateTOTPCredentialParams{
+		AuthID: authID,
+		Secret: util.RandomStr(32),
+	})
+	require.NoError(t, err)
+
+	err = store.EnableTOTPTx(context.Background(), EnableTOTPTxParams{
+		AuthID:             authID,
+		Step:               1,
+		RecoveryCodeHashes: []string{util.HashSecretToken("code")},
+	})
+	require.NoError(t, err)
+
+	err = store.DisableTOTPTx(context.Background(), authID)
+	require.NoError(t, err)
+
+	_, err = store.GetTOTPCredential(context.Background(), authID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	count, err := store.CountRecoveryCodes(context.Background(), authID)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
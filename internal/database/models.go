@@ -5,7 +5,9 @@
 package db
 
 import (
+	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -54,6 +56,49 @@ func (ns NullRoleType) Value() (driver.Value, error) {
 	return string(ns.RoleType), nil
 }
 
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+	WorkspaceRoleEditor WorkspaceRole = "editor"
+	WorkspaceRoleViewer WorkspaceRole = "viewer"
+)
+
+func (e *WorkspaceRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceRole(s)
+	case string:
+		*e = WorkspaceRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceRole: %T", src)
+	}
+	return nil
+}
+
+type NullWorkspaceRole struct {
+	WorkspaceRole WorkspaceRole `json:"workspace_role"`
+	Valid         bool          `json:"valid"` // Valid is true if WorkspaceRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkspaceRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkspaceRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkspaceRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkspaceRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkspaceRole), nil
+}
+
 type Admin struct {
 	ID        uuid.UUID `json:"id"`
 	AuthID    uuid.UUID `json:"auth_id"`
@@ -63,6 +108,28 @@ type Admin struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type AdminInvitation struct {
+	ID         uuid.UUID     `json:"id"`
+	Email      string        `json:"email"`
+	TokenHash  string        `json:"token_hash"`
+	InvitedBy  uuid.NullUUID `json:"invited_by"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	AcceptedBy uuid.NullUUID `json:"accepted_by"`
+	AcceptedAt sql.NullTime  `json:"accepted_at"`
+	RevokedAt  sql.NullTime  `json:"revoked_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID        int64           `json:"id"`
+	ActorID   uuid.NullUUID   `json:"actor_id"`
+	TargetID  uuid.NullUUID   `json:"target_id"`
+	Action    string          `json:"action"`
+	Metadata  json.RawMessage `json:"metadata"`
+	IpAddress string          `json:"ip_address"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
 type Auth struct {
 	ID                uuid.UUID    `json:"id"`
 	Email             string       `json:"email"`
@@ -73,6 +140,136 @@ type Auth struct {
 	Restricted        bool         `json:"restricted"`
 	Deleted           bool         `json:"deleted"`
 	Role              NullRoleType `json:"role"`
+	LastLoginAt       sql.NullTime `json:"last_login_at"`
+	FailedAttempts    int32        `json:"failed_attempts"`
+	LockedUntil       sql.NullTime `json:"locked_until"`
+	EmailVerifiedAt   sql.NullTime `json:"email_verified_at"`
+	DeletedAt         sql.NullTime `json:"deleted_at"`
+	LastFailedLoginAt sql.NullTime `json:"last_failed_login_at"`
+}
+
+type AuthToken struct {
+	ID        uuid.UUID    `json:"id"`
+	AuthID    uuid.UUID    `json:"auth_id"`
+	Purpose   string       `json:"purpose"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type CacheEntry struct {
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt sql.NullTime    `json:"expires_at"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type Connection struct {
+	ID               uuid.UUID       `json:"id"`
+	OrganizationID   uuid.UUID       `json:"organization_id"`
+	Name             string          `json:"name"`
+	DbType           string          `json:"db_type"`
+	DbName           string          `json:"db_name"`
+	DbUrl            string          `json:"db_url"`
+	CreatedBy        uuid.NullUUID   `json:"created_by"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	MaxEstimatedRows sql.NullInt64   `json:"max_estimated_rows"`
+	MaxEstimatedCost sql.NullFloat64 `json:"max_estimated_cost"`
+	CostAction       sql.NullString  `json:"cost_action"`
+}
+
+type ConnectionRule struct {
+	ID          uuid.UUID      `json:"id"`
+	Target      string         `json:"target"`
+	Port        sql.NullInt32  `json:"port"`
+	DbType      sql.NullString `json:"db_type"`
+	Description string         `json:"description"`
+	CreatedBy   uuid.NullUUID  `json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type LlmUsage struct {
+	AuthID           uuid.UUID `json:"auth_id"`
+	Period           time.Time `json:"period"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	Requests         int32     `json:"requests"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type LoginAttempt struct {
+	IpAddress      string       `json:"ip_address"`
+	FailedAttempts int32        `json:"failed_attempts"`
+	LastFailedAt   time.Time    `json:"last_failed_at"`
+	LockedUntil    sql.NullTime `json:"locked_until"`
+}
+
+type ModerationAction struct {
+	ID        uuid.UUID `json:"id"`
+	AuthID    uuid.UUID `json:"auth_id"`
+	AdminID   uuid.UUID `json:"admin_id"`
+	Action    string    `json:"action"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Organization struct {
+	ID        uuid.UUID     `json:"id"`
+	Name      string        `json:"name"`
+	CreatedBy uuid.NullUUID `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type OrganizationMember struct {
+	OrganizationID uuid.UUID     `json:"organization_id"`
+	AuthID         uuid.UUID     `json:"auth_id"`
+	Role           WorkspaceRole `json:"role"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type QueryLog struct {
+	ID           int64     `json:"id"`
+	UserID       uuid.UUID `json:"user_id"`
+	ConnectionID string    `json:"connection_id"`
+	DbType       string    `json:"db_type"`
+	Question     string    `json:"question"`
+	SqlQuery     string    `json:"sql_query"`
+	Valid        bool      `json:"valid"`
+	ResultCached bool      `json:"result_cached"`
+	RowCount     int32     `json:"row_count"`
+	DurationMs   int64     `json:"duration_ms"`
+	Error        string    `json:"error"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID    `json:"id"`
+	AuthID    uuid.UUID    `json:"auth_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type SavedQuestion struct {
+	ID             uuid.UUID     `json:"id"`
+	OrganizationID uuid.UUID     `json:"organization_id"`
+	ConnectionID   uuid.NullUUID `json:"connection_id"`
+	Title          string        `json:"title"`
+	Question       string        `json:"question"`
+	CreatedBy      uuid.NullUUID `json:"created_by"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type TotpCredential struct {
+	AuthID       uuid.UUID    `json:"auth_id"`
+	Secret       string       `json:"secret"`
+	ConfirmedAt  sql.NullTime `json:"confirmed_at"`
+	LastUsedStep int64        `json:"last_used_step"`
+	CreatedAt    time.Time    `json:"created_at"`
 }
 
 type User struct {
@@ -83,3 +280,11 @@ type User struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+type UserLimit struct {
+	AuthID            uuid.UUID     `json:"auth_id"`
+	RequestsPerMinute sql.NullInt32 `json:"requests_per_minute"`
+	Burst             sql.NullInt32 `json:"burst"`
+	MonthlyTokenQuota sql.NullInt64 `json:"monthly_token_quota"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
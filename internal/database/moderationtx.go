@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ModerationActionRestrict   = "restrict"
+	ModerationActionUnrestrict = "unrestrict"
+	ModerationActionRestore    = "restore"
+	ModerationActionDelete     = "delete"
+)
+
+var (
+	ErrNotRestricted = errors.New("user is not restricted")
+	ErrNotRestorable = errors.New("user is not deleted or the restoration period has expired")
+)
+
+// ModerateUserTxParams identifies the user being moderated, the admin moderating and the reason.
+type ModerateUserTxParams struct {
+	AuthID    uuid.UUID
+	AdminID   uuid.UUID
+	Reason    string
+	IpAddress string
+}
+
+// RestrictUserTx is used to restrict a user and record the moderation action in the same database transaction
+func (store *SQLStore) RestrictUserTx(ctx context.Context, arg ModerateUserTxParams) (ModerationAction, error) {
+	var result ModerationAction
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.RestrictAuth(ctx, RestrictAuthParams{
+			ID:        arg.AuthID,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update auth to restricted: %w", err)
+		}
+
+		result, err = recordModerationAction(ctx, q, arg, ModerationActionRestrict, AuditUserRestricted)
+		return err
+	})
+
+	return result, err
+}
+
+// UnrestrictUserTx is used to lift the restriction of a user and record the moderation action in the same database transaction
+func (store *SQLStore) UnrestrictUserTx(ctx context.Context, arg ModerateUserTxParams) (ModerationAction, error) {
+	var result ModerationAction
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		updated, err := q.UnrestrictAuth(ctx, UnrestrictAuthParams{
+			ID:        arg.AuthID,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update auth to unrestricted: %w", err)
+		}
+		if updated == 0 {
+			return ErrNotRestricted
+		}
+
+		result, err = recordModerationAction(ctx, q, arg, ModerationActionUnrestrict, AuditUserUnrestricted)
+		return err
+	})
+
+	return result, err
+}
+
+// RestoreUserTx is used to restore a soft deleted user within the 30 days before it is purged
+// and record the moderation action in the same database transaction
+func (store *SQLStore) RestoreUserTx(ctx context.Context, arg ModerateUserTxParams) (ModerationAction, error) {
+	var result ModerationAction
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		updated, err := q.RestoreAuth(ctx, RestoreAuthParams{
+			ID:        arg.AuthID,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update auth to restored: %w", err)
+		}
+		if updated == 0 {
+			return ErrNotRestorable
+		}
+
+		// accounts deleted before user records were kept cannot be restored
+		_, err = q.GetUser(ctx, arg.AuthID)
+		if err != nil {
+			return ErrNotRestorable
+		}
+
+		result, err = recordModerationAction(ctx, q, arg, ModerationActionRestore, AuditUserRestored)
+		return err
+	})
+
+	return result, err
+}
+
+// DeleteUserByAdminTx is used by an admin to soft delete a user and record the moderation action in the same database transaction
+func (store *SQLStore) DeleteUserByAdminTx(ctx context.Context, arg ModerateUserTxParams) (ModerationAction, error) {
+	var result ModerationAction
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteAuth(ctx, DeleteAuthParams{
+			ID:        arg.AuthID,
+			DeletedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update auth to deleted: %w", err)
+		}
+
+		result, err = recordModerationAction(ctx, q, arg, ModerationActionDelete, AuditUserDeleted)
+		return err
+	})
+
+	return result, err
+}
+
+// recordModerationAction records the moderation action and its audit event.
+func recordModerationAction(ctx context.Context, q *Queries, arg ModerateUserTxParams, action, auditAction string) (ModerationAction, error) {
+	result, err := q.CreateModerationAction(ctx, CreateModerationActionParams{
+		ID:      uuid.New(),
+		AuthID:  arg.AuthID,
+		AdminID: arg.AdminID,
+		Action:  action,
+		Reason:  arg.Reason,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to record moderation action: %w", err)
+	}
+
+	_, err = recordAuditEvent(ctx, q, AuditEventParams{
+		ActorID:  arg.AdminID,
+		TargetID: arg.AuthID,
+		Action:   auditAction,
+		Metadata: map[string]any{
+			"reason":               arg.Reason,
+			"moderation_action_id": result.ID,
+		},
+		IpAddress: arg.IpAddress,
+	})
+
+	return result, err
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnrestrictUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	_, adminTx := createRandomUserOrAdminTx(t, RoleTypeAdmin)
+
+	arg := ModerateUserTxParams{
+		AuthID:  userTx.Auth.ID,
+		AdminID: adminTx.Auth.ID,
+		Reason:  "restricted by mistake",
+	}
+
+	_, err := store.UnrestrictUserTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrNotRestricted)
+
+	err = store.RestrictAuth(context.Background(), RestrictAuthParams{
+		ID:        userTx.Auth.ID,
+		UpdatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	action, err := store.UnrestrictUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, userTx.Auth.ID, action.AuthID)
+	require.Equal(t, adminTx.Auth.ID, action.AdminID)
+	require.Equal(t, ModerationActionUnrestrict, action.Action)
+	require.Equal(t, arg.Reason, action.Reason)
+
+	auth, err := store.GetAuth(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
+	require.False(t, auth.Restricted)
+
+	history, err := store.ListModerationActions(context.Background(), ListModerationActionsParams{
+		AuthID: userTx.Auth.ID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, action.ID, history[0].ID)
+}
+
+func TestRestoreUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	_, adminTx := createRandomUserOrAdminTx(t, RoleTypeAdmin)
+
+	arg := ModerateUserTxParams{
+		AuthID:  userTx.Auth.ID,
+		AdminID: adminTx.Auth.ID,
+		Reason:  "requested by account owner",
+	}
+
+	_, err := store.RestoreUserTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrNotRestorable)
+
+	err = store.DeleteUserTx(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
+
+	action, err := store.RestoreUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, ModerationActionRestore, action.Action)
+
+	user, err := store.GetUser(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
+	require.Equal(t, userTx.User.Username, user.Username)
+
+	// accounts cannot be restored after the grace period
+	err = store.DeleteUserTx(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
+
+	_, err = testDB.Exec(`
+		UPDATE auth
+		SET deleted_at = NOW() - INTERVAL '31 days'
+		WHERE id = $1`, userTx.Auth.ID)
+	require.NoError(t, err)
+
+	// later writes to the account do not move the grace period
+	err = store.RestrictAuth(context.Background(), RestrictAuthParams{ID: userTx.Auth.ID, UpdatedAt: time.Now()})
+	require.NoError(t, err)
+
+	_, err = store.RestoreUserTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrNotRestorable)
+}
+
+func TestDeleteUserByAdminTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	_, adminTx := createRandomUserOrAdminTx(t, RoleTypeAdmin)
+
+	arg := ModerateUserTxParams{
+		AuthID:  userTx.Auth.ID,
+		AdminID: adminTx.Auth.ID,
+		Reason:  "spam",
+	}
+
+	action, err := store.DeleteUserByAdminTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, ModerationActionDelete, action.Action)
+	require.Equal(t, arg.Reason, action.Reason)
+
+	auth, err := store.GetAuth(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
+	require.True(t, auth.Deleted)
+
+	history, err := store.ListModerationActions(context.Background(), ListModerationActionsParams{
+		AuthID: userTx.Auth.ID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, action.ID, history[0].ID)
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrLastOwner is returned when a change would leave an organization without an owner.
+var ErrLastOwner = errors.New("an organization must have at least one owner")
+
+// CreateOrganizationTx creates an organization with its creator as the owner.
+func (store *SQLStore) CreateOrganizationTx(ctx context.Context, arg CreateOrganizationParams) (Organization, error) {
+	var org Organization
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		org, err = q.CreateOrganization(ctx, arg)
+		if err != nil {
+			return fmt.Errorf("failed to create organization: %w", err)
+		}
+
+		_, err = q.AddOrganizationMember(ctx, AddOrganizationMemberParams{
+			OrganizationID: org.ID,
+			AuthID:         arg.CreatedBy.UUID,
+			Role:           WorkspaceRoleOwner,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add owner: %w", err)
+		}
+
+		return nil
+	})
+
+	return org, err
+}
+
+// UpdateOrganizationMemberRoleTx changes the role of a member, it fails with ErrLastOwner when demoting the last owner.
+func (store *SQLStore) UpdateOrganizationMemberRoleTx(ctx context.Context, arg UpdateOrganizationMemberRoleParams) (OrganizationMember, error) {
+	var member OrganizationMember
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		// membership changes of an organization are serialized so that concurrent changes cannot remove every owner
+		err = q.LockOrganization(ctx, arg.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to lock organization: %w", err)
+		}
+
+		member, err = q.UpdateOrganizationMemberRole(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return checkOrganizationOwners(ctx, q, arg.OrganizationID)
+	})
+
+	return member, err
+}
+
+// RemoveOrganizationMemberTx removes a member, it fails with ErrLastOwner when removing the last owner
+// and with sql.ErrNoRows when the account is not a member.
+func (store *SQLStore) RemoveOrganizationMemberTx(ctx context.Context, arg RemoveOrganizationMemberParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.LockOrganization(ctx, arg.OrganizationID)
+		if err != nil {
+			return fmt.Errorf("failed to lock organization: %w", err)
+		}
+
+		removed, err := q.RemoveOrganizationMember(ctx, arg)
+		if err != nil {
+			return fmt.Errorf("failed to remove member: %w", err)
+		}
+		if removed == 0 {
+			return sql.ErrNoRows
+		}
+
+		return checkOrganizationOwners(ctx, q, arg.OrganizationID)
+	})
+}
+
+func checkOrganizationOwners(ctx context.Context, q *Queries, organizationID uuid.UUID) error {
+	owners, err := q.CountOrganizationOwners(ctx, organizationID)
+	if err != nil {
+		return fmt.Errorf("failed to count owners: %w", err)
+	}
+	if owners == 0 {
+		return ErrLastOwner
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOrganization(t *testing.T, owner uuid.UUID) Organization {
+	store := NewStore(testDB)
+
+	arg := CreateOrganizationParams{
+		ID:        uuid.New(),
+		Name:      util.RandomStr(10),
+		CreatedBy: uuid.NullUUID{UUID: owner, Valid: true},
+	}
+
+	org, err := store.CreateOrganizationTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, org.ID)
+	require.Equal(t, arg.Name, org.Name)
+
+	member, err := store.GetOrganizationMember(context.Background(), GetOrganizationMemberParams{
+		OrganizationID: org.ID,
+		AuthID:         owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WorkspaceRoleOwner, member.Role)
+
+	return org
+}
+
+func TestCreateOrganizationTx(t *testing.T) {
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	org := createRandomOrganization(t, userTx.Auth.ID)
+
+	orgs, err := testQueries.ListUserOrganizations(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	require.Equal(t, org.ID, orgs[0].ID)
+	require.Equal(t, WorkspaceRoleOwner, orgs[0].Role)
+}
+
+func TestOrganizationMemberTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	owner, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	editor, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	org := createRandomOrganization(t, owner.Auth.ID)
+
+	_, err := store.AddOrganizationMember(context.Background(), AddOrganizationMemberParams{
+		OrganizationID: org.ID,
+		AuthID:         editor.Auth.ID,
+		Role:           WorkspaceRoleEditor,
+	})
+	require.NoError(t, err)
+
+	members, err := store.ListOrganizationMembers(context.Background(), org.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	// the last owner can neither be demoted nor removed
+	_, err = store.UpdateOrganizationMemberRoleTx(context.Background(), UpdateOrganizationMemberRoleParams{
+		OrganizationID: org.ID,
+		AuthID:         owner.Auth.ID,
+		Role:           WorkspaceRoleViewer,
+	})
+	require.ErrorIs(t, err, ErrLastOwner)
+
+	err = store.RemoveOrganizationMemberTx(context.Background(), RemoveOrganizationMemberParams{
+		OrganizationID: org.ID,
+		AuthID:         owner.Auth.ID,
+	})
+	require.ErrorIs(t, err, ErrLastOwner)
+
+	member, err := store.UpdateOrganizationMemberRoleTx(context.Background(), UpdateOrganizationMemberRoleParams{
+		OrganizationID: org.ID,
+		AuthID:         editor.Auth.ID,
+		Role:           WorkspaceRoleOwner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WorkspaceRoleOwner, member.Role)
+
+	err = store.RemoveOrganizationMemberTx(context.Background(), RemoveOrganizationMemberParams{
+		OrganizationID: org.ID,
+		AuthID:         owner.Auth.ID,
+	})
+	require.NoError(t, err)
+
+	err = store.RemoveOrganizationMemberTx(context.Background(), RemoveOrganizationMemberParams{
+		OrganizationID: org.ID,
+		AuthID:         owner.Auth.ID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestConnectionsAndSavedQuestions(t *testing.T) {
+	owner, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	org := createRandomOrganization(t, owner.Auth.ID)
+	createdBy := uuid.NullUUID{UUID: owner.Auth.ID, Valid: true}
+
+	conn, err := testQueries.CreateConnection(context.Background(), CreateConnectionParams{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		Name:           util.RandomStr(8),
+		DbType:         util.DialectPostgres,
+		DbName:         util.RandomStr(8),
+		DbUrl:          util.RandomStr(32),
+		CreatedBy:      createdBy,
+	})
+	require.NoError(t, err)
+
+	question, err := testQueries.CreateSavedQuestion(context.Background(), CreateSavedQuestionParams{
+		ID:             uuid.New(),
+		OrganizationID: org.ID,
+		ConnectionID:   uuid.NullUUID{UUID: conn.ID, Valid: true},
+		Title:          util.RandomStr(8),
+		Question:       "How many users signed up last month?",
+		CreatedBy:      createdBy,
+	})
+	require.NoError(t, err)
+
+	updated, err := testQueries.UpdateConnection(context.Background(), UpdateConnectionParams{
+		ID:   conn.ID,
+		Name: sql.NullString{String: "renamed", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "renamed", updated.Name)
+	require.Equal(t, conn.DbUrl, updated.DbUrl)
+
+	conns, err := testQueries.ListConnections(context.Background(), org.ID)
+	require.NoError(t, err)
+	require.Len(t, conns, 1)
+
+	// saved questions outlive the connection they were asked against
+	err = testQueries.DeleteConnection(context.Background(), conn.ID)
+	require.NoError(t, err)
+
+	question, err = testQueries.GetSavedQuestion(context.Background(), question.ID)
+	require.NoError(t, err)
+	require.False(t, question.ConnectionID.Valid)
+
+	// deleting the organization deletes everything it owns
+	err = testQueries.DeleteOrganization(context.Background(), org.ID)
+	require.NoError(t, err)
+
+	questions, err := testQueries.ListSavedQuestions(context.Background(), org.ID)
+	require.NoError(t, err)
+	require.Empty(t, questions)
+}
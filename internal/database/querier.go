@@ -6,28 +6,102 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AcceptAdminInvitation(ctx context.Context, arg AcceptAdminInvitationParams) (AdminInvitation, error)
+	AddLLMUsage(ctx context.Context, arg AddLLMUsageParams) (LlmUsage, error)
+	AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error)
+	ConfirmTOTPCredential(ctx context.Context, arg ConfirmTOTPCredentialParams) (int64, error)
+	CountAdmins(ctx context.Context) (int64, error)
+	CountOrganizationOwners(ctx context.Context, organizationID uuid.UUID) (int64, error)
+	CountRecoveryCodes(ctx context.Context, authID uuid.UUID) (int64, error)
+	CountUsers(ctx context.Context, arg CountUsersParams) (int64, error)
 	CreateAdmin(ctx context.Context, arg CreateAdminParams) (Admin, error)
 	CreateAdminAuth(ctx context.Context, arg CreateAdminAuthParams) (Auth, error)
+	CreateAdminInvitation(ctx context.Context, arg CreateAdminInvitationParams) (AdminInvitation, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateAuth(ctx context.Context, arg CreateAuthParams) (Auth, error)
+	CreateAuthToken(ctx context.Context, arg CreateAuthTokenParams) (AuthToken, error)
+	CreateConnection(ctx context.Context, arg CreateConnectionParams) (Connection, error)
+	CreateConnectionRule(ctx context.Context, arg CreateConnectionRuleParams) (ConnectionRule, error)
+	CreateModerationAction(ctx context.Context, arg CreateModerationActionParams) (ModerationAction, error)
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	CreateQueryLog(ctx context.Context, arg CreateQueryLogParams) (QueryLog, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateSavedQuestion(ctx context.Context, arg CreateSavedQuestionParams) (SavedQuestion, error)
+	CreateTOTPCredential(ctx context.Context, arg CreateTOTPCredentialParams) (TotpCredential, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAdmin(ctx context.Context, id uuid.UUID) error
 	DeleteAuth(ctx context.Context, arg DeleteAuthParams) error
+	DeleteConnection(ctx context.Context, id uuid.UUID) error
+	DeleteConnectionRule(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteExpiredAdminInvitations(ctx context.Context) (int64, error)
+	DeleteExpiredAuthTokens(ctx context.Context) (int64, error)
+	DeleteExpiredCacheEntries(ctx context.Context) (int64, error)
+	DeleteExpiredLoginAttempts(ctx context.Context) (int64, error)
+	DeleteOrganization(ctx context.Context, id uuid.UUID) error
+	DeleteRecoveryCodes(ctx context.Context, authID uuid.UUID) error
+	DeleteSavedQuestion(ctx context.Context, id uuid.UUID) error
+	DeleteTOTPCredential(ctx context.Context, authID uuid.UUID) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 	DeleteUserAuthCron(ctx context.Context, limit int32) ([]Auth, error)
 	GetAdmin(ctx context.Context, authID uuid.UUID) (GetAdminRow, error)
 	GetAuth(ctx context.Context, id uuid.UUID) (GetAuthRow, error)
+	GetCacheEntry(ctx context.Context, key string) (json.RawMessage, error)
+	GetConnection(ctx context.Context, id uuid.UUID) (Connection, error)
 	GetDeletedUsers(ctx context.Context) (int64, error)
+	GetLLMUsage(ctx context.Context, arg GetLLMUsageParams) (LlmUsage, error)
+	GetLoginAttempt(ctx context.Context, ipAddress string) (LoginAttempt, error)
+	GetOrganization(ctx context.Context, id uuid.UUID) (Organization, error)
+	GetOrganizationMember(ctx context.Context, arg GetOrganizationMemberParams) (OrganizationMember, error)
+	GetSavedQuestion(ctx context.Context, id uuid.UUID) (SavedQuestion, error)
+	GetTOTPCredential(ctx context.Context, authID uuid.UUID) (TotpCredential, error)
 	GetUser(ctx context.Context, authID uuid.UUID) (GetUserRow, error)
+	GetUserDetail(ctx context.Context, id uuid.UUID) (GetUserDetailRow, error)
+	GetUserLimits(ctx context.Context, authID uuid.UUID) (UserLimit, error)
+	InvalidateAuthTokens(ctx context.Context, arg InvalidateAuthTokensParams) error
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListConnectionRules(ctx context.Context) ([]ConnectionRule, error)
+	ListConnections(ctx context.Context, organizationID uuid.UUID) ([]Connection, error)
+	ListModerationActions(ctx context.Context, arg ListModerationActionsParams) ([]ModerationAction, error)
+	ListOrganizationMembers(ctx context.Context, organizationID uuid.UUID) ([]ListOrganizationMembersRow, error)
+	ListPendingAdminInvitations(ctx context.Context) ([]AdminInvitation, error)
+	ListQueryLogs(ctx context.Context, arg ListQueryLogsParams) ([]QueryLog, error)
+	ListSavedQuestions(ctx context.Context, organizationID uuid.UUID) ([]SavedQuestion, error)
+	ListUserOrganizations(ctx context.Context, authID uuid.UUID) ([]ListUserOrganizationsRow, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
+	LockAdminBootstrap(ctx context.Context) error
+	LockAuth(ctx context.Context, arg LockAuthParams) error
+	LockLoginAttempt(ctx context.Context, arg LockLoginAttemptParams) error
+	LockOrganization(ctx context.Context, id uuid.UUID) error
+	LockPendingAdminInvitation(ctx context.Context, arg LockPendingAdminInvitationParams) (AdminInvitation, error)
+	RecordAuthLogin(ctx context.Context, id uuid.UUID) error
+	RecordAuthLoginFailure(ctx context.Context, arg RecordAuthLoginFailureParams) (int32, error)
+	RecordLoginAttemptFailure(ctx context.Context, arg RecordLoginAttemptFailureParams) (LoginAttempt, error)
+	RemoveOrganizationMember(ctx context.Context, arg RemoveOrganizationMemberParams) (int64, error)
+	RestoreAuth(ctx context.Context, arg RestoreAuthParams) (int64, error)
 	RestrictAuth(ctx context.Context, arg RestrictAuthParams) error
+	RevokeAdminInvitation(ctx context.Context, id uuid.UUID) (int64, error)
+	SetCacheEntry(ctx context.Context, arg SetCacheEntryParams) error
+	SetUserLimits(ctx context.Context, arg SetUserLimitsParams) (UserLimit, error)
+	UnlockAuth(ctx context.Context, id uuid.UUID) error
+	UnrestrictAuth(ctx context.Context, arg UnrestrictAuthParams) (int64, error)
 	UpdateAdmin(ctx context.Context, arg UpdateAdminParams) (Admin, error)
 	UpdateAuth(ctx context.Context, arg UpdateAuthParams) (Auth, error)
+	UpdateConnection(ctx context.Context, arg UpdateConnectionParams) (Connection, error)
+	UpdateOrganization(ctx context.Context, arg UpdateOrganizationParams) (Organization, error)
+	UpdateOrganizationMemberRole(ctx context.Context, arg UpdateOrganizationMemberRoleParams) (OrganizationMember, error)
+	UpdateSavedQuestion(ctx context.Context, arg UpdateSavedQuestionParams) (SavedQuestion, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UseAuthToken(ctx context.Context, arg UseAuthTokenParams) (AuthToken, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
 	ValidateAuth(ctx context.Context, email string) (Auth, error)
+	VerifyAuthEmail(ctx context.Context, id uuid.UUID) error
 }
 
 var _ Querier = (*Queries)(nil)
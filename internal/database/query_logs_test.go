@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/gentcod/nlp-to-sql/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomQueryLog(t *testing.T, userID uuid.UUID, connID string, valid bool) QueryLog {
+	arg := CreateQueryLogParams{
+		UserID:       userID,
+		ConnectionID: connID,
+		DbType:       "postgres",
+		Question:     "how many accounts are there?",
+		SqlQuery:     "SELECT COUNT(*) FROM accounts",
+		Valid:        valid,
+		RowCount:     1,
+		DurationMs:   12,
+	}
+
+	queryLog, err := testQueries.CreateQueryLog(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, queryLog.ID)
+	require.Equal(t, arg.UserID, queryLog.UserID)
+	require.Equal(t, arg.ConnectionID, queryLog.ConnectionID)
+	require.Equal(t, arg.SqlQuery, queryLog.SqlQuery)
+	require.Equal(t, arg.Valid, queryLog.Valid)
+	require.Equal(t, arg.RowCount, queryLog.RowCount)
+	require.Equal(t, arg.DurationMs, queryLog.DurationMs)
+	require.Empty(t, queryLog.Error)
+	require.NotZero(t, queryLog.CreatedAt)
+
+	return queryLog
+}
+
+func TestCreateQueryLog(t *testing.T) {
+	createRandomQueryLog(t, uuid.New(), util.RandomStr(16), true)
+}
+
+func TestListQueryLogs(t *testing.T) {
+	userID := uuid.New()
+	connID := util.RandomStr(16)
+
+	first := createRandomQueryLog(t, userID, connID, true)
+	rejected := createRandomQueryLog(t, userID, connID, false)
+	last := createRandomQueryLog(t, userID, connID, true)
+	createRandomQueryLog(t, uuid.New(), connID, true)
+
+	queryLogs, err := testQueries.ListQueryLogs(context.Background(), ListQueryLogsParams{
+		UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+		PageLimit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, queryLogs, 2)
+	require.Equal(t, last.ID, queryLogs[0].ID)
+	require.Equal(t, rejected.ID, queryLogs[1].ID)
+
+	queryLogs, err = testQueries.ListQueryLogs(context.Background(), ListQueryLogsParams{
+		Cursor:    sql.NullInt64{Int64: rejected.ID, Valid: true},
+		UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+		PageLimit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, queryLogs, 1)
+	require.Equal(t, first.ID, queryLogs[0].ID)
+
+	queryLogs, err = testQueries.ListQueryLogs(context.Background(), ListQueryLogsParams{
+		ConnectionID: sql.NullString{String: connID, Valid: true},
+		Valid:        sql.NullBool{Bool: false, Valid: true},
+		PageLimit:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, queryLogs, 1)
+	require.Equal(t, rejected.ID, queryLogs[0].ID)
+}
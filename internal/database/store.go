@@ -16,11 +16,26 @@ type Store interface {
 	Querier
 	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (UserTxResult, error)
 	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UserTxResult, error)
-	DeleteUserTx(ctx context.Context, authID uuid.UUID, userID uuid.UUID) error
+	DeleteUserTx(ctx context.Context, authID uuid.UUID) error
 	CreateAdminTx(ctx context.Context, arg CreateAdminTxParams) (AdminTxResult, error)
+	CreateBootstrapAdminTx(ctx context.Context, arg CreateAdminTxParams) (AdminTxResult, error)
+	CreateInvitedAdminTx(ctx context.Context, arg CreateInvitedAdminTxParams) (AdminTxResult, AdminInvitation, error)
 	UpdateAdminTx(ctx context.Context, arg UpdateAdminTxParams) (AdminTxResult, error)
 	DeleteAdminTx(ctx context.Context, authID uuid.UUID, adminID uuid.UUID) error
 	DeleteExpDeletedUserRecords(ctx context.Context, batchSize int) (totalDeleted int, err error)
+	UnrestrictUserTx(ctx context.Context, arg ModerateUserTxParams) (ModerationAction, error)
+	RestoreUserTx(ctx context.Context, arg ModerateUserTxParams) (ModerationAction, error)
+	RestrictUserTx(ctx context.Context, arg ModerateUserTxParams) (ModerationAction, error)
+	DeleteUserByAdminTx(ctx context.Context, arg ModerateUserTxParams) (ModerationAction, error)
+	RecordAuditEvent(ctx context.Context, arg AuditEventParams) (AuditEvent, error)
+	IssueAuthTokenTx(ctx context.Context, arg IssueAuthTokenTxParams) (AuthToken, error)
+	VerifyEmailTx(ctx context.Context, tokenHash string) (AuthToken, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (AuthToken, error)
+	EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) error
+	DisableTOTPTx(ctx context.Context, authID uuid.UUID) error
+	CreateOrganizationTx(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	UpdateOrganizationMemberRoleTx(ctx context.Context, arg UpdateOrganizationMemberRoleParams) (OrganizationMember, error)
+	RemoveOrganizationMemberTx(ctx context.Context, arg RemoveOrganizationMemberParams) error
 }
 
 // SQLStore provides all functions to execute db SQL queries
@@ -89,6 +104,14 @@ func (store *SQLStore) DeleteExpDeletedUserRecords(ctx context.Context, batchSiz
 	return totalDeleted, nil
 }
 
+// QueryResult is the data returned by a query. Columns are the names of the columns in the order of the query,
+// Truncated reports whether the query returned more rows than were read.
+type QueryResult struct {
+	Columns   []string                 `json:"columns"`
+	Rows      []map[string]interface{} `json:"rows"`
+	Truncated bool                     `json:"truncated"`
+}
+
 // GetData queries the database to return related data
 func GetData(db *sql.DB, query string) ([]map[string]interface{}, error) {
 	rows, err := db.Query(query)
@@ -97,9 +120,34 @@ func GetData(db *sql.DB, query string) ([]map[string]interface{}, error) {
 	}
 	defer rows.Close()
 
+	result, err := scanRows(rows, 0)
+	return result.Rows, err
+}
+
+// GetReadOnlyData queries the database in a read-only transaction, which is rolled back once the data is read,
+// so that a query can never modify the database even if it got past validation. At most maxRows rows are read
+// when maxRows is positive.
+func GetReadOnlyData(ctx context.Context, db *sql.DB, query string, maxRows int) (QueryResult, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return QueryResult{}, fmt.Errorf("failed to begin read-only transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return QueryResult{}, fmt.Errorf("query execution failed: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRows(rows, maxRows)
+}
+
+// scanRows reads the rows of a query as maps of column names to values, up to maxRows rows when it is positive.
+func scanRows(rows *sql.Rows, maxRows int) (QueryResult, error) {
 	columns, err := rows.Columns()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch column names: %w", err)
+		return QueryResult{}, fmt.Errorf("failed to fetch column names: %w", err)
 	}
 
 	values := make([]interface{}, len(columns))
@@ -108,10 +156,15 @@ func GetData(db *sql.DB, query string) ([]map[string]interface{}, error) {
 		valPointers[i] = &values[i]
 	}
 
-	results := []map[string]interface{}{}
+	result := QueryResult{Columns: columns, Rows: []map[string]interface{}{}}
 	for rows.Next() {
+		if maxRows > 0 && len(result.Rows) == maxRows {
+			result.Truncated = true
+			return result, nil
+		}
+
 		if err := rows.Scan(valPointers...); err != nil {
-			return nil, fmt.Errorf("row scanning failed: %w", err)
+			return QueryResult{}, fmt.Errorf("row scanning failed: %w", err)
 		}
 
 		row := make(map[string]interface{})
@@ -124,12 +177,12 @@ func GetData(db *sql.DB, query string) ([]map[string]interface{}, error) {
 			}
 		}
 
-		results = append(results, row)
+		result.Rows = append(result.Rows, row)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error during row iteration: %w", err)
+		return QueryResult{}, fmt.Errorf("error during row iteration: %w", err)
 	}
 
-	return results, nil
+	return result, nil
 }
@@ -86,7 +86,7 @@ func createRandomUserOrAdminTx(t *testing.T, role RoleType) (userTx UserTxResult
 
 func TestGetData(t *testing.T) {
 	query := `SELECT * FROM users LIMIT 10;`
-	valid := util.ValidQuery(query)
+	valid := util.ValidQuery(query, util.DialectPostgres)
 	require.True(t, valid)
 
 	result, err := GetData(testDB, query)
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -22,3 +23,57 @@ func TestGetUser(t *testing.T) {
 	require.Equal(t, userTx.User.CreatedAt, result.CreatedAt)
 	require.Equal(t, userTx.User.UpdatedAt, result.UpdatedAt)
 }
+
+func TestListUsers(t *testing.T) {
+	store := NewStore(testDB)
+
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+
+	arg := ListUsersParams{
+		Role: NullRoleType{
+			RoleType: RoleTypeUser,
+			Valid:    true,
+		},
+		Search: sql.NullString{
+			String: userTx.User.Username,
+			Valid:  true,
+		},
+		PageLimit:  10,
+		PageOffset: 0,
+	}
+
+	users, err := store.ListUsers(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, users)
+	require.Equal(t, userTx.Auth.ID, users[0].ID)
+	require.Equal(t, userTx.User.Username, users[0].Username)
+	require.Equal(t, userTx.Auth.Email, users[0].Email)
+
+	total, err := store.CountUsers(context.Background(), CountUsersParams{
+		Role:   arg.Role,
+		Search: arg.Search,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(users)), total)
+
+	arg.Restricted = sql.NullBool{Bool: true, Valid: true}
+	users, err = store.ListUsers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, users)
+}
+
+func TestGetUserDetail(t *testing.T) {
+	store := NewStore(testDB)
+
+	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+
+	result, err := store.GetUserDetail(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
+	require.Equal(t, userTx.Auth.ID, result.ID)
+	require.Equal(t, userTx.Auth.Email, result.Email)
+	require.Equal(t, userTx.User.Username, result.Username)
+	require.Equal(t, userTx.User.FullName, result.FullName)
+	require.Equal(t, RoleTypeUser, result.Role.RoleType)
+	require.False(t, result.Restricted)
+	require.False(t, result.Deleted)
+}
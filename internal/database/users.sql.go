@@ -13,6 +13,45 @@ import (
 	"github.com/google/uuid"
 )
 
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*)
+FROM auth
+LEFT JOIN users ON users.auth_id = auth.id
+LEFT JOIN admins ON admins.auth_id = auth.id
+WHERE ($1::BOOLEAN IS NULL OR auth.restricted = $1)
+   AND ($2::BOOLEAN IS NULL OR auth.deleted = $2)
+   AND ($3::role_type IS NULL OR auth.role = $3)
+   AND ($4::TIMESTAMPTZ IS NULL OR auth.created_at >= $4)
+   AND ($5::TIMESTAMPTZ IS NULL OR auth.created_at <= $5)
+   AND ($6::VARCHAR IS NULL
+      OR auth.email ILIKE '%' || $6 || '%'
+      OR users.username ILIKE '%' || $6 || '%'
+      OR admins.username ILIKE '%' || $6 || '%')
+`
+
+type CountUsersParams struct {
+	Restricted  sql.NullBool   `json:"restricted"`
+	Deleted     sql.NullBool   `json:"deleted"`
+	Role        NullRoleType   `json:"role"`
+	CreatedFrom sql.NullTime   `json:"created_from"`
+	CreatedTo   sql.NullTime   `json:"created_to"`
+	Search      sql.NullString `json:"search"`
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers,
+		arg.Restricted,
+		arg.Deleted,
+		arg.Role,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Search,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, auth_id, username, full_name)
 VALUES ($1, $2, $3, $4)
@@ -55,8 +94,9 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, username, full_name, created_at, updated_at FROM users
-WHERE auth_id = $1 LIMIT 1
+SELECT users.id, users.username, users.full_name, users.created_at, users.updated_at FROM users
+JOIN auth ON auth.id = users.auth_id
+WHERE users.auth_id = $1 AND auth.deleted = FALSE LIMIT 1
 `
 
 type GetUserRow struct {
@@ -80,6 +120,156 @@ func (q *Queries) GetUser(ctx context.Context, authID uuid.UUID) (GetUserRow, er
 	return i, err
 }
 
+const getUserDetail = `-- name: GetUserDetail :one
+SELECT
+   auth.id,
+   auth.email,
+   auth.role,
+   auth.restricted,
+   auth.deleted,
+   auth.password_changed_at,
+   auth.last_login_at,
+   auth.failed_attempts,
+   auth.locked_until,
+   auth.created_at,
+   auth.updated_at,
+   COALESCE(users.username, admins.username, '')::VARCHAR AS username,
+   COALESCE(users.full_name, admins.full_name, '')::VARCHAR AS full_name
+FROM auth
+LEFT JOIN users ON users.auth_id = auth.id
+LEFT JOIN admins ON admins.auth_id = auth.id
+WHERE auth.id = $1 LIMIT 1
+`
+
+type GetUserDetailRow struct {
+	ID                uuid.UUID    `json:"id"`
+	Email             string       `json:"email"`
+	Role              NullRoleType `json:"role"`
+	Restricted        bool         `json:"restricted"`
+	Deleted           bool         `json:"deleted"`
+	PasswordChangedAt time.Time    `json:"password_changed_at"`
+	LastLoginAt       sql.NullTime `json:"last_login_at"`
+	FailedAttempts    int32        `json:"failed_attempts"`
+	LockedUntil       sql.NullTime `json:"locked_until"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+	Username          string       `json:"username"`
+	FullName          string       `json:"full_name"`
+}
+
+func (q *Queries) GetUserDetail(ctx context.Context, id uuid.UUID) (GetUserDetailRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserDetail, id)
+	var i GetUserDetailRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Role,
+		&i.Restricted,
+		&i.Deleted,
+		&i.PasswordChangedAt,
+		&i.LastLoginAt,
+		&i.FailedAttempts,
+		&i.LockedUntil,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Username,
+		&i.FullName,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT
+   auth.id,
+   auth.email,
+   auth.role,
+   auth.restricted,
+   auth.deleted,
+   auth.created_at,
+   auth.updated_at,
+   COALESCE(users.username, admins.username, '')::VARCHAR AS username,
+   COALESCE(users.full_name, admins.full_name, '')::VARCHAR AS full_name
+FROM auth
+LEFT JOIN users ON users.auth_id = auth.id
+LEFT JOIN admins ON admins.auth_id = auth.id
+WHERE ($1::BOOLEAN IS NULL OR auth.restricted = $1)
+   AND ($2::BOOLEAN IS NULL OR auth.deleted = $2)
+   AND ($3::role_type IS NULL OR auth.role = $3)
+   AND ($4::TIMESTAMPTZ IS NULL OR auth.created_at >= $4)
+   AND ($5::TIMESTAMPTZ IS NULL OR auth.created_at <= $5)
+   AND ($6::VARCHAR IS NULL
+      OR auth.email ILIKE '%' || $6 || '%'
+      OR users.username ILIKE '%' || $6 || '%'
+      OR admins.username ILIKE '%' || $6 || '%')
+ORDER BY auth.created_at DESC, auth.id
+LIMIT $7 OFFSET $8
+`
+
+type ListUsersParams struct {
+	Restricted  sql.NullBool   `json:"restricted"`
+	Deleted     sql.NullBool   `json:"deleted"`
+	Role        NullRoleType   `json:"role"`
+	CreatedFrom sql.NullTime   `json:"created_from"`
+	CreatedTo   sql.NullTime   `json:"created_to"`
+	Search      sql.NullString `json:"search"`
+	PageLimit   int32          `json:"page_limit"`
+	PageOffset  int32          `json:"page_offset"`
+}
+
+type ListUsersRow struct {
+	ID         uuid.UUID    `json:"id"`
+	Email      string       `json:"email"`
+	Role       NullRoleType `json:"role"`
+	Restricted bool         `json:"restricted"`
+	Deleted    bool         `json:"deleted"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	Username   string       `json:"username"`
+	FullName   string       `json:"full_name"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers,
+		arg.Restricted,
+		arg.Deleted,
+		arg.Role,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Search,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersRow{}
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Role,
+			&i.Restricted,
+			&i.Deleted,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Username,
+			&i.FullName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users 
 SET 
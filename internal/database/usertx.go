@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -78,25 +79,21 @@ func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams)
 	return result, err
 }
 
-// DeleteUserTx is used to delete a user record and it's associated auth in the same database transaction
-func (store *SQLStore) DeleteUserTx(ctx context.Context, authID uuid.UUID, userID uuid.UUID) error {
+// DeleteUserTx is used to soft delete a user record and it's associated auth in the same database transaction.
+// The user record is kept so the account can be restored until it is purged by DeleteExpDeletedUserRecords.
+func (store *SQLStore) DeleteUserTx(ctx context.Context, authID uuid.UUID) error {
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
 		arg := DeleteAuthParams{
 			ID:        authID,
-			UpdatedAt: time.Now(),
+			DeletedAt: sql.NullTime{Time: time.Now(), Valid: true},
 		}
 		err = q.DeleteAuth(ctx, arg)
 		if err != nil {
 			return fmt.Errorf("failed to update auth to deleted: %w", err)
 		}
 
-		err = q.DeleteUser(ctx, userID)
-		if err != nil {
-			return fmt.Errorf("failed to delete user: %w", err)
-		}
-
 		return nil
 	})
 
@@ -17,6 +17,8 @@ func TestUpdateUserTx(t *testing.T) {
 	store := NewStore(testDB)
 
 	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
+	err := store.VerifyAuthEmail(context.Background(), userTx.Auth.ID)
+	require.NoError(t, err)
 
 	email := util.RandomEmail(10)
 	username := util.RandomUser()
@@ -56,6 +58,7 @@ func TestUpdateUserTx(t *testing.T) {
 
 	require.Equal(t, userTx.Auth.ID, result.Auth.ID)
 	require.Equal(t, arg.UpdateAuthParams.Email.String, result.Auth.Email)
+	require.False(t, result.Auth.EmailVerifiedAt.Valid)
 	require.Equal(t, arg.UpdateAuthParams.HarshedPassword.String, result.Auth.HarshedPassword)
 	require.Equal(t, userTx.Auth.CreatedAt, result.Auth.CreatedAt)
 	require.NotZero(t, result.Auth.UpdatedAt)
@@ -73,7 +76,7 @@ func TestDeleteUserTx(t *testing.T) {
 
 	userTx, _ := createRandomUserOrAdminTx(t, RoleTypeUser)
 
-	err := store.DeleteUserTx(context.Background(), userTx.Auth.ID, userTx.User.ID)
+	err := store.DeleteUserTx(context.Background(), userTx.Auth.ID)
 	require.NoError(t, err)
 
 	user, err := store.GetUser(context.Background(), userTx.User.AuthID)
@@ -0,0 +1,246 @@
+package limiter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/rag"
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+)
+
+var (
+	ErrRateLimited   = errors.New("rate limit exceeded, too many requests")
+	ErrQuotaExceeded = errors.New("monthly LLM token quota exceeded")
+)
+
+const (
+	// limitsTTL is how long the limits of a user are used before they are reloaded from the database.
+	limitsTTL = time.Minute
+	// idleTTL is how long the bucket of an inactive user is kept.
+	idleTTL = 10 * time.Minute
+)
+
+// Limits contains the request rate and monthly LLM token quota of a user.
+// A zero RequestsPerMinute disables rate limiting and a zero MonthlyTokenQuota allows unlimited tokens.
+// Burst is the number of requests that can be made at once, it defaults to RequestsPerMinute.
+type Limits struct {
+	RequestsPerMinute int32 `json:"requests_per_minute"`
+	Burst             int32 `json:"burst"`
+	MonthlyTokenQuota int64 `json:"monthly_token_quota"`
+}
+
+// Limiter enforces per-user request rates with a token bucket for each user,
+// and monthly LLM token quotas tracked in the application database.
+type Limiter struct {
+	store     db.Store
+	defaults  Limits
+	mutex     sync.Mutex
+	users     map[uuid.UUID]*userLimiter
+	lastPrune time.Time
+}
+
+type userLimiter struct {
+	limits   Limits
+	bucket   *rate.Limiter
+	loadedAt time.Time
+	lastSeen time.Time
+}
+
+// NewLimiter initializes a Limiter, defaults apply to users without limits set by an admin.
+func NewLimiter(store db.Store, defaults Limits) *Limiter {
+	return &Limiter{
+		store:     store,
+		defaults:  defaults,
+		users:     make(map[uuid.UUID]*userLimiter),
+		lastPrune: time.Now(),
+	}
+}
+
+// Allow reports whether the user can make a request now, otherwise it returns how long to wait before retrying.
+func (limiter *Limiter) Allow(ctx context.Context, userID uuid.UUID) (bool, time.Duration, error) {
+	user, err := limiter.userLimiter(ctx, userID)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if user.bucket == nil {
+		return true, 0, nil
+	}
+
+	now := time.Now()
+	reservation := user.bucket.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, 0, nil
+	}
+
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay, nil
+	}
+
+	return true, 0, nil
+}
+
+// CheckQuota returns ErrQuotaExceeded when the user has used their LLM token quota for the month.
+func (limiter *Limiter) CheckQuota(ctx context.Context, userID uuid.UUID) error {
+	user, err := limiter.userLimiter(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.limits.MonthlyTokenQuota <= 0 {
+		return nil
+	}
+
+	usage, err := limiter.Usage(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if usage.PromptTokens+usage.CompletionTokens >= user.limits.MonthlyTokenQuota {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+// RecordUsage adds the LLM tokens used by a request to the monthly usage of the user.
+func (limiter *Limiter) RecordUsage(ctx context.Context, userID uuid.UUID, usage rag.Usage) error {
+	_, err := limiter.store.AddLLMUsage(ctx, db.AddLLMUsageParams{
+		AuthID:           userID,
+		Period:           Period(time.Now()),
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+	})
+	if err != nil {
+		return fmt.Errorf("error recording llm usage: %v", err)
+	}
+
+	return nil
+}
+
+// Usage returns the LLM usage of the user for the current month.
+func (limiter *Limiter) Usage(ctx context.Context, userID uuid.UUID) (db.LlmUsage, error) {
+	period := Period(time.Now())
+	usage, err := limiter.store.GetLLMUsage(ctx, db.GetLLMUsageParams{
+		AuthID: userID,
+		Period: period,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.LlmUsage{AuthID: userID, Period: period}, nil
+		}
+		return usage, fmt.Errorf("error getting llm usage: %v", err)
+	}
+
+	return usage, nil
+}
+
+// Limits returns the limits of the user, falling back to the defaults for limits not set by an admin.
+func (limiter *Limiter) Limits(ctx context.Context, userID uuid.UUID) (Limits, error) {
+	limits := limiter.defaults
+
+	userLimits, err := limiter.store.GetUserLimits(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return limits, nil
+		}
+		return limits, fmt.Errorf("error getting user limits: %v", err)
+	}
+
+	if userLimits.RequestsPerMinute.Valid {
+		limits.RequestsPerMinute = userLimits.RequestsPerMinute.Int32
+	}
+	if userLimits.Burst.Valid {
+		limits.Burst = userLimits.Burst.Int32
+	}
+	if userLimits.MonthlyTokenQuota.Valid {
+		limits.MonthlyTokenQuota = userLimits.MonthlyTokenQuota.Int64
+	}
+
+	return limits, nil
+}
+
+// Reset discards the cached limits and bucket of the user, it is called after the limits of the user are updated.
+func (limiter *Limiter) Reset(userID uuid.UUID) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	delete(limiter.users, userID)
+}
+
+// Period returns the start of the monthly usage period of t.
+func Period(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// userLimiter returns the limits and bucket of the user, the bucket is kept when reloaded limits are unchanged.
+func (limiter *Limiter) userLimiter(ctx context.Context, userID uuid.UUID) (*userLimiter, error) {
+	now := time.Now()
+
+	limiter.mutex.Lock()
+	user, ok := limiter.users[userID]
+	if ok && now.Sub(user.loadedAt) < limitsTTL {
+		user.lastSeen = now
+		limiter.mutex.Unlock()
+		return user, nil
+	}
+	limiter.mutex.Unlock()
+
+	limits, err := limiter.Limits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	user, ok = limiter.users[userID]
+	if !ok || user.limits != limits {
+		user = &userLimiter{
+			limits: limits,
+			bucket: newBucket(limits),
+		}
+		limiter.users[userID] = user
+	}
+	user.loadedAt = now
+	user.lastSeen = now
+
+	limiter.prune(now)
+	return user, nil
+}
+
+// prune removes the buckets of inactive users, the caller must hold the mutex.
+func (limiter *Limiter) prune(now time.Time) {
+	if now.Sub(limiter.lastPrune) < idleTTL {
+		return
+	}
+
+	for userID, user := range limiter.users {
+		if now.Sub(user.lastSeen) >= idleTTL {
+			delete(limiter.users, userID)
+		}
+	}
+	limiter.lastPrune = now
+}
+
+func newBucket(limits Limits) *rate.Limiter {
+	if limits.RequestsPerMinute <= 0 {
+		return nil
+	}
+
+	burst := limits.Burst
+	if burst <= 0 {
+		burst = limits.RequestsPerMinute
+	}
+
+	return rate.NewLimiter(rate.Limit(float64(limits.RequestsPerMinute)/60), int(burst))
+}
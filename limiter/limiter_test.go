@@ -0,0 +1,138 @@
+package limiter
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/rag"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps user limits and llm usage in memory.
+type fakeStore struct {
+	db.Store
+	limits map[uuid.UUID]db.UserLimit
+	usage  map[uuid.UUID]db.LlmUsage
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		limits: make(map[uuid.UUID]db.UserLimit),
+		usage:  make(map[uuid.UUID]db.LlmUsage),
+	}
+}
+
+func (store *fakeStore) GetUserLimits(ctx context.Context, authID uuid.UUID) (db.UserLimit, error) {
+	limits, ok := store.limits[authID]
+	if !ok {
+		return limits, sql.ErrNoRows
+	}
+	return limits, nil
+}
+
+func (store *fakeStore) GetLLMUsage(ctx context.Context, arg db.GetLLMUsageParams) (db.LlmUsage, error) {
+	usage, ok := store.usage[arg.AuthID]
+	if !ok || !usage.Period.Equal(arg.Period) {
+		return usage, sql.ErrNoRows
+	}
+	return usage, nil
+}
+
+func (store *fakeStore) AddLLMUsage(ctx context.Context, arg db.AddLLMUsageParams) (db.LlmUsage, error) {
+	usage := store.usage[arg.AuthID]
+	usage.AuthID = arg.AuthID
+	usage.Period = arg.Period
+	usage.PromptTokens += arg.PromptTokens
+	usage.CompletionTokens += arg.CompletionTokens
+	usage.Requests++
+	store.usage[arg.AuthID] = usage
+	return usage, nil
+}
+
+func TestAllow(t *testing.T) {
+	limiter := NewLimiter(newFakeStore(), Limits{RequestsPerMinute: 60, Burst: 2})
+	userID := uuid.New()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := limiter.Allow(context.Background(), userID)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := limiter.Allow(context.Background(), userID)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Greater(t, retryAfter, time.Duration(0))
+	require.LessOrEqual(t, retryAfter, time.Second)
+
+	// each user has their own bucket
+	allowed, _, err = limiter.Allow(context.Background(), uuid.New())
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestAllowUserLimits(t *testing.T) {
+	store := newFakeStore()
+	limiter := NewLimiter(store, Limits{RequestsPerMinute: 1})
+	userID := uuid.New()
+
+	allowed, _, err := limiter.Allow(context.Background(), userID)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, _, err = limiter.Allow(context.Background(), userID)
+	require.NoError(t, err)
+	require.False(t, allowed)
+
+	// a zero rate set by an admin disables rate limiting for the user
+	store.limits[userID] = db.UserLimit{
+		AuthID:            userID,
+		RequestsPerMinute: sql.NullInt32{Int32: 0, Valid: true},
+	}
+	limiter.Reset(userID)
+
+	for i := 0; i < 10; i++ {
+		allowed, _, err = limiter.Allow(context.Background(), userID)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	store := newFakeStore()
+	limiter := NewLimiter(store, Limits{MonthlyTokenQuota: 100})
+	userID := uuid.New()
+
+	require.NoError(t, limiter.CheckQuota(context.Background(), userID))
+
+	err := limiter.RecordUsage(context.Background(), userID, rag.Usage{PromptTokens: 60, CompletionTokens: 30})
+	require.NoError(t, err)
+	require.NoError(t, limiter.CheckQuota(context.Background(), userID))
+
+	err = limiter.RecordUsage(context.Background(), userID, rag.Usage{PromptTokens: 10})
+	require.NoError(t, err)
+	require.ErrorIs(t, limiter.CheckQuota(context.Background(), userID), ErrQuotaExceeded)
+
+	usage, err := limiter.Usage(context.Background(), userID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), usage.PromptTokens)
+	require.Equal(t, int64(30), usage.CompletionTokens)
+	require.Equal(t, int32(2), usage.Requests)
+
+	// quotas set by an admin override the default
+	store.limits[userID] = db.UserLimit{
+		AuthID:            userID,
+		MonthlyTokenQuota: sql.NullInt64{Int64: 1000, Valid: true},
+	}
+	limiter.Reset(userID)
+	require.NoError(t, limiter.CheckQuota(context.Background(), userID))
+}
+
+func TestPeriod(t *testing.T) {
+	period := Period(time.Date(2024, time.March, 15, 13, 30, 0, 0, time.UTC))
+	require.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), period)
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// LogMailer is a Mailer that writes emails to a file or the log instead of sending them,
+// for use in tests and local development.
+type LogMailer struct {
+	path  string
+	from  string
+	mutex sync.Mutex
+}
+
+// NewLogMailer initializes a Mailer that appends emails to the file at path, or logs them when path is empty.
+func NewLogMailer(path, from string) Mailer {
+	return &LogMailer{
+		path: path,
+		from: from,
+	}
+}
+
+func (mailer *LogMailer) Send(ctx context.Context, email Email) error {
+	if !validHeader(email.To) || !validHeader(email.Subject) {
+		return fmt.Errorf("invalid email header")
+	}
+
+	message := buildMessage(mailer.from, email)
+
+	if mailer.path == "" {
+		log.Printf("Email:\n%s", message)
+		return nil
+	}
+
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	file, err := os.OpenFile(mailer.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open email log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(file, "%s\r\n\r\n", message); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	return nil
+}
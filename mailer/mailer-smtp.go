@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// SMTPConfig contains the SMTP server and the sender address of emails.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer is a Mailer that sends emails through an SMTP server.
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer initializes a Mailer that sends emails through an SMTP server,
+// it authenticates with PLAIN auth when a username is set.
+func NewSMTPMailer(config SMTPConfig) Mailer {
+	return &SMTPMailer{
+		config: config,
+	}
+}
+
+func (mailer *SMTPMailer) Send(ctx context.Context, email Email) error {
+	if !validHeader(email.To) || !validHeader(email.Subject) {
+		return fmt.Errorf("invalid email header")
+	}
+
+	var auth smtp.Auth
+	if mailer.config.Username != "" {
+		auth = smtp.PlainAuth("", mailer.config.Username, mailer.config.Password, mailer.config.Host)
+	}
+
+	addr := net.JoinHostPort(mailer.config.Host, mailer.config.Port)
+	err := smtp.SendMail(addr, auth, mailer.config.From, []string{email.To}, buildMessage(mailer.config.From, email))
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
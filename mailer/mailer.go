@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Email is a plain text email message.
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails to users.
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// buildMessage formats the email as an RFC 5322 message.
+func buildMessage(from string, email Email) []byte {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "From: %s\r\n", from)
+	fmt.Fprintf(&sb, "To: %s\r\n", email.To)
+	fmt.Fprintf(&sb, "Subject: %s\r\n", email.Subject)
+	fmt.Fprintf(&sb, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+
+	return []byte(sb.String())
+}
+
+// validHeader reports whether the value can be used in a header without injecting other headers.
+func validHeader(value string) bool {
+	return !strings.ContainsAny(value, "\r\n")
+}
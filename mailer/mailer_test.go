@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessage(t *testing.T) {
+	message := string(buildMessage("noreply@example.com", Email{
+		To:      "user@example.com",
+		Subject: "Verify your email",
+		Body:    "Hello,\nverify your email.",
+	}))
+
+	require.Contains(t, message, "From: noreply@example.com\r\n")
+	require.Contains(t, message, "To: user@example.com\r\n")
+	require.Contains(t, message, "Subject: Verify your email\r\n")
+	require.Contains(t, message, "\r\n\r\nHello,\r\nverify your email.")
+}
+
+func TestLogMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "emails.log")
+	mailer := NewLogMailer(path, "noreply@example.com")
+
+	err := mailer.Send(context.Background(), Email{
+		To:      "user@example.com",
+		Subject: "Reset your password",
+		Body:    "token: abc",
+	})
+	require.NoError(t, err)
+
+	err = mailer.Send(context.Background(), Email{
+		To:      "other@example.com",
+		Subject: "Verify your email",
+		Body:    "token: def",
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "token: abc")
+	require.Contains(t, string(data), "To: other@example.com")
+
+	// headers cannot be injected through the recipient or subject
+	err = mailer.Send(context.Background(), Email{
+		To:      "user@example.com\r\nBcc: attacker@example.com",
+		Subject: "Verify your email",
+	})
+	require.Error(t, err)
+}
@@ -1,14 +1,25 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gentcod/nlp-to-sql/api"
 	"github.com/gentcod/nlp-to-sql/chat"
 	conv "github.com/gentcod/nlp-to-sql/converter"
 	"github.com/gentcod/nlp-to-sql/cron"
+	"github.com/gentcod/nlp-to-sql/egress"
 	db "github.com/gentcod/nlp-to-sql/internal/database"
+	"github.com/gentcod/nlp-to-sql/limiter"
+	"github.com/gentcod/nlp-to-sql/mailer"
 
 	"github.com/gentcod/nlp-to-sql/rag"
 	"github.com/gentcod/nlp-to-sql/util"
@@ -16,12 +27,35 @@ import (
 	_ "github.com/lib/pq"
 )
 
+const (
+	readHeaderTimeout = 10 * time.Second
+	// defaultShutdownTimeout is how long requests and chats are waited for on shutdown when SHUTDOWN_TIMEOUT is not set.
+	defaultShutdownTimeout = 30 * time.Second
+)
+
 func main() {
 	config, err := util.LoadConfig(".env")
 	if err != nil {
 		log.Fatal("cannot load config", err)
 	}
 
+	llmOpts := rag.LLMOpts{
+		ApiKey:    config.ApiKey,
+		OrgId:     config.OrgId,
+		ProjectId: config.ProjectId,
+		Model:     config.Model,
+		Temp:      config.Temp,
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		// evaluations always bypass the cache to measure the current prompts and model
+		err = runEval(config, conv.NewSQLConverter(llmOpts, conv.CacheOpts{}, nil), os.Args[2:])
+		if err != nil {
+			log.Fatal("evaluation failed: ", err)
+		}
+		return
+	}
+
 	conn, err := sql.Open(config.DBDriver, config.DBUrl)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
@@ -30,13 +64,33 @@ func main() {
 
 	store := db.NewStore(conn)
 
-	converter := conv.NewSQLConverter(rag.LLMOpts{
-		ApiKey:    config.ApiKey,
-		OrgId:     config.OrgId,
-		ProjectId: config.ProjectId,
-		Model:     config.Model,
-		Temp:      config.Temp,
-	})
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		err = runCreateAdmin(store, os.Args[2:], os.Stdin)
+		if err != nil {
+			log.Fatal("creating admin failed: ", err)
+		}
+		return
+	}
+
+	cache, err := initCache(config, store)
+	if err != nil {
+		log.Fatal("error initializing cache", err)
+	}
+
+	converter := conv.NewSQLConverter(llmOpts, conv.CacheOpts{
+		Cache:     cache,
+		ResultTTL: config.CacheResultTTL,
+	}, conv.NewDBQueryLog(store))
+
+	rateLimiter, err := initLimiter(config, store)
+	if err != nil {
+		log.Fatal("error initializing rate limiter", err)
+	}
+
+	mail, err := initMailer(config)
+	if err != nil {
+		log.Fatal("error initializing mailer", err)
+	}
 
 	dbcron := cron.NewDBCron(store, cron.CronConfig{
 		BatchSize: config.CronBatchSize,
@@ -48,22 +102,145 @@ func main() {
 		log.Fatal("error initializing database cron", err)
 	}
 
-	runGinServer(config, store, converter)
+	runGinServer(config, store, converter, rateLimiter, mail)
 }
 
-func runGinServer(config util.Config, store db.Store, converter conv.Converter) {
-	websocketSrv, err := chat.NewWebSocketServer(config, converter)
+func runGinServer(config util.Config, store db.Store, converter conv.Converter, rateLimiter *limiter.Limiter, mail mailer.Mailer) {
+	policy, err := egress.NewPolicy(store, config.ConnectionPolicy)
+	if err != nil {
+		log.Fatal("couldn't initialize the connection policy:", err)
+	}
+
+	resolver := chat.NewDBConnectionResolver(store, config.TokenSymmetricKey)
+	websocketSrv, err := chat.NewWebSocketServer(config, converter, rateLimiter, resolver, policy)
 	if err != nil {
 		log.Fatal("couldn't initialize the chat-server:", err)
 	}
 
-	server, err := api.NewServer(config, store, websocketSrv)
+	server, err := api.NewServer(config, store, websocketSrv, rateLimiter, mail)
 	if err != nil {
 		log.Fatal("couldn't initialize the server:", err)
 	}
 
-	err = server.Start(config.Port)
-	if err != nil {
+	httpServer := &http.Server{
+		Addr:              config.Port,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
 		log.Fatal("couldn't start up server:", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	shutdownTimeout := config.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+	log.Printf("Shutting down, waiting up to %v for requests and chats to finish", shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// hijacked WebSocket connections are not tracked by the http.Server, the chat server drains them
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Print("error shutting down the server: ", err)
+	}
+	if err := websocketSrv.Shutdown(shutdownCtx); err != nil {
+		log.Print("error shutting down the chat-server: ", err)
+	}
+}
+
+// initCache returns the cache configured by CACHE_TYPE, caching is disabled when it is not set.
+func initCache(config util.Config, store db.Store) (conv.Cache, error) {
+	switch config.CacheType {
+	case "":
+		return nil, nil
+	case "memory":
+		size, err := strconv.Atoi(config.CacheSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache size: %v", err)
+		}
+		if size <= 0 {
+			return nil, fmt.Errorf("cache size must be greater than 0, got %d", size)
+		}
+		return conv.NewMemoryCache(size), nil
+	case "database":
+		return conv.NewDBCache(store), nil
+	}
+
+	return nil, fmt.Errorf("unsupported cache type: %v", config.CacheType)
+}
+
+// initMailer returns the mailer configured by MAILER_TYPE, emails are logged when it is not set.
+func initMailer(config util.Config) (mailer.Mailer, error) {
+	switch config.MailerType {
+	case "", "log":
+		if config.MailerType == "" {
+			log.Print("MAILER_TYPE is not set, emails will be logged instead of sent")
+		}
+		return mailer.NewLogMailer(config.MailLogPath, config.MailFrom), nil
+	case "smtp":
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+			From:     config.MailFrom,
+		}), nil
+	}
+
+	return nil, fmt.Errorf("unsupported mailer type: %v", config.MailerType)
+}
+
+// initLimiter returns the limiter with the default limits configured by RATE_LIMIT_PER_MINUTE, RATE_LIMIT_BURST
+// and MONTHLY_TOKEN_QUOTA, limits that are not set are disabled unless set for a user by an admin.
+func initLimiter(config util.Config, store db.Store) (*limiter.Limiter, error) {
+	requestsPerMinute, err := parseLimit(config.RateLimitPerMinute, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit per minute: %v", err)
+	}
+
+	burst, err := parseLimit(config.RateLimitBurst, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit burst: %v", err)
 	}
+
+	quota, err := parseLimit(config.MonthlyTokenQuota, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid monthly token quota: %v", err)
+	}
+
+	return limiter.NewLimiter(store, limiter.Limits{
+		RequestsPerMinute: int32(requestsPerMinute),
+		Burst:             int32(burst),
+		MonthlyTokenQuota: quota,
+	}), nil
+}
+
+// parseLimit parses a non-negative limit, an empty value is a zero limit.
+func parseLimit(value string, bitSize int) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		return 0, err
+	}
+	if limit < 0 {
+		return 0, fmt.Errorf("limit cannot be negative: %v", limit)
+	}
+
+	return limit, nil
 }
@@ -0,0 +1,29 @@
+package rag
+
+import (
+	"fmt"
+
+	"github.com/gentcod/nlp-to-sql/util"
+)
+
+// dialectHints returns the dialect-specific rules added to query generation prompts
+// to avoid mixing up syntax between database engines.
+func dialectHints(dialect string) string {
+	switch dialect {
+	case util.DialectPostgres:
+		return `The target database is PostgreSQL, generate queries using the PostgreSQL dialect only.
+Use LIMIT to restrict rows, never TOP.
+Quote identifiers with double quotes only when required, never with backticks. Unquoted identifiers are folded to lower case.
+Use NOW(), CURRENT_DATE, INTERVAL '1 year', DATE_TRUNC and EXTRACT for date arithmetic, e.g. created_at >= NOW() - INTERVAL '30 days'.
+LIKE is case-sensitive, use ILIKE for case-insensitive matching.`
+
+	case util.DialectMySQL:
+		return `The target database is MySQL, generate queries using the MySQL dialect only.
+Use LIMIT to restrict rows, never TOP.
+Quote identifiers with backticks only when required, never with double quotes.
+Use NOW(), CURDATE(), DATE_SUB, DATE_ADD, DATE_FORMAT and YEAR for date arithmetic, e.g. created_at >= DATE_SUB(NOW(), INTERVAL 30 DAY).
+LIKE is case-insensitive with the default collations, table names can be case-sensitive depending on the server.`
+	}
+
+	return fmt.Sprintf("The target database is %v, generate queries using standard SQL.", dialect)
+}
@@ -12,6 +12,7 @@ type GeminiLLM struct {
 	Opts     LLMOpts
 	Query    string
 	Response string
+	usage    Usage
 }
 
 // NewGeminiLLM is used to initalize a LLM that communicates with Google's Gemini API.
@@ -57,6 +58,12 @@ func (llm *GeminiLLM) GenerateQuery(que string) (string, error) {
 			},
 			Role: "user",
 		},
+		{
+			Parts: []genai.Part{
+				genai.Text(dialectHints(llm.Opts.Dialect)),
+			},
+			Role: "user",
+		},
 		{
 			Parts: []genai.Part{
 				genai.Text("Omit fields or columns with sensitive data such as password, hashed_password or similar fields no matter the condtions stated in corresponding statements."),
@@ -87,6 +94,7 @@ func (llm *GeminiLLM) GenerateQuery(que string) (string, error) {
 	if err != nil {
 		return llm.Query, nil
 	}
+	llm.addUsage(res)
 	llm.Query, err = getGeminiResponse(res)
 	if err != nil {
 		return llm.Query, nil
@@ -131,6 +139,7 @@ func (llm *GeminiLLM) GenerateResponse(data interface{}, que string) (string, er
 	if err != nil {
 		return llm.Response, nil
 	}
+	llm.addUsage(res)
 	llm.Response, err = getGeminiResponse(res)
 	if err != nil {
 		return llm.Response, nil
@@ -139,6 +148,17 @@ func (llm *GeminiLLM) GenerateResponse(data interface{}, que string) (string, er
 	return llm.Response, nil
 }
 
+func (llm *GeminiLLM) Usage() Usage {
+	return llm.usage
+}
+
+func (llm *GeminiLLM) addUsage(resp *genai.GenerateContentResponse) {
+	if resp.UsageMetadata == nil {
+		return
+	}
+	llm.usage.add(int64(resp.UsageMetadata.PromptTokenCount), int64(resp.UsageMetadata.CandidatesTokenCount))
+}
+
 func getGeminiResponse(resp *genai.GenerateContentResponse) (string, error) {
 	res := ""
 	for _, cand := range resp.Candidates {
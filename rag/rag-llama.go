@@ -12,6 +12,7 @@ type LlamaLLM struct {
 	Opts     LLMOpts
 	Query    string
 	Response string
+	usage    Usage
 }
 
 func NewLlamaLLM(opts LLMOpts) LLM {
@@ -26,9 +27,11 @@ func (llm *LlamaLLM) GenerateQuery(que string) (string, error) {
 
 Generate only SELECT queries or queries to read data. Never include sensitive fields like password, hashed_password etc. Only output valid SQL, no explanations. If the question is not answerable using the schema, return an error message.
 
-Question: %s`, llm.Opts.Context, que)
+%s
 
-	res, err := callOllama("llama3", prompt)
+Question: %s`, llm.Opts.Context, dialectHints(llm.Opts.Dialect), que)
+
+	res, err := llm.callOllama("llama3", prompt)
 	if err != nil {
 		return "", err
 	}
@@ -45,7 +48,7 @@ Data: %v
 
 Write in a concise and human-like way.`, que, data)
 
-	res, err := callOllama("llama3", prompt)
+	res, err := llm.callOllama("llama3", prompt)
 	if err != nil {
 		return "", err
 	}
@@ -53,7 +56,11 @@ Write in a concise and human-like way.`, que, data)
 	return res, nil
 }
 
-func callOllama(model, prompt string) (string, error) {
+func (llm *LlamaLLM) Usage() Usage {
+	return llm.usage
+}
+
+func (llm *LlamaLLM) callOllama(model, prompt string) (string, error) {
 	body := map[string]interface{}{
 		"model":  model,
 		"prompt": prompt,
@@ -73,11 +80,14 @@ func callOllama(model, prompt string) (string, error) {
 	}
 
 	var result struct {
-		Response string `json:"response"`
+		Response        string `json:"response"`
+		PromptEvalCount int64  `json:"prompt_eval_count"`
+		EvalCount       int64  `json:"eval_count"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return "", fmt.Errorf("failed to parse ollama response: %w", err)
 	}
+	llm.usage.add(result.PromptEvalCount, result.EvalCount)
 
 	return result.Response, nil
 }
@@ -12,6 +12,7 @@ type OpenAiLLM struct {
 	Opts     LLMOpts
 	Query    string
 	Response string
+	usage    Usage
 }
 
 // NewGeminiLLM is used to initalize a LLM that communicates with OpenAI's ChatGPT API.
@@ -28,6 +29,7 @@ func (llm *OpenAiLLM) GenerateQuery(que string) (string, error) {
 	payload := map[string]interface{}{
 		"model": llm.Opts.Model,
 		"messages": []map[string]string{
+			{"role": "system", "content": dialectHints(llm.Opts.Dialect)},
 			{"role": openairole, "content": que},
 		},
 		// "context": llm.Opts.context, //Mapper Schema
@@ -70,6 +72,16 @@ func (llm *OpenAiLLM) GenerateQuery(que string) (string, error) {
 
 	fmt.Printf("Response: %s\n", body)
 
+	var result struct {
+		Usage struct {
+			PromptTokens     int64 `json:"prompt_tokens"`
+			CompletionTokens int64 `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &result); err == nil {
+		llm.usage.add(result.Usage.PromptTokens, result.Usage.CompletionTokens)
+	}
+
 	return llm.Query, nil
 }
 
@@ -77,3 +89,7 @@ func (llm *OpenAiLLM) GenerateResponse(data interface{}, que string) (string, er
 
 	return llm.Response, nil
 }
+
+func (llm *OpenAiLLM) Usage() Usage {
+	return llm.usage
+}
@@ -10,11 +10,31 @@ type LLM interface {
 	// to return response in a textual or conversational manner
 	// using the question asked for a furher context-aware response
 	GenerateResponse(data any, que string) (string, error)
+
+	// Usage returns the tokens used by the requests made to the LLM API so far
+	Usage() Usage
+}
+
+// Usage contains the number of tokens used by LLM API requests, as reported by the provider
+type Usage struct {
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// TotalTokens returns the number of prompt and completion tokens used
+func (usage Usage) TotalTokens() int64 {
+	return usage.PromptTokens + usage.CompletionTokens
+}
+
+func (usage *Usage) add(promptTokens, completionTokens int64) {
+	usage.PromptTokens += promptTokens
+	usage.CompletionTokens += completionTokens
 }
 
 // LLMOpts contains fields needed to connect to an LLM
 type LLMOpts struct {
 	Context   any
+	Dialect   string
 	ApiKey    string
 	OrgId     string
 	ProjectId string
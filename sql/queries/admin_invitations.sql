@@ -0,0 +1,50 @@
+-- name: CreateAdminInvitation :one
+INSERT INTO admin_invitations (id, email, token_hash, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: AcceptAdminInvitation :one
+UPDATE admin_invitations
+SET accepted_at = NOW(),
+   accepted_by = sqlc.arg(accepted_by)
+WHERE token_hash = sqlc.arg(token_hash)
+   AND LOWER(email) = LOWER(sqlc.arg(email))
+   AND accepted_at IS NULL
+   AND revoked_at IS NULL
+   AND expires_at > NOW()
+RETURNING *;
+
+-- name: LockPendingAdminInvitation :one
+SELECT * FROM admin_invitations
+WHERE token_hash = sqlc.arg(token_hash)
+   AND LOWER(email) = LOWER(sqlc.arg(email))
+   AND accepted_at IS NULL
+   AND revoked_at IS NULL
+   AND expires_at > NOW()
+FOR UPDATE;
+
+-- name: ListPendingAdminInvitations :many
+SELECT * FROM admin_invitations
+WHERE accepted_at IS NULL
+   AND revoked_at IS NULL
+   AND expires_at > NOW()
+ORDER BY created_at DESC;
+
+-- name: RevokeAdminInvitation :execrows
+UPDATE admin_invitations
+SET revoked_at = NOW()
+WHERE id = $1
+   AND accepted_at IS NULL
+   AND revoked_at IS NULL;
+
+-- name: DeleteExpiredAdminInvitations :execrows
+DELETE FROM admin_invitations
+WHERE accepted_at IS NULL
+   AND expires_at < NOW() - INTERVAL '30 days';
+
+-- name: CountAdmins :one
+SELECT COUNT(*) FROM auth
+WHERE role = 'admin';
+
+-- name: LockAdminBootstrap :exec
+SELECT pg_advisory_xact_lock(hashtext('admin_bootstrap'));
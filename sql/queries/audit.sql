@@ -0,0 +1,15 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (actor_id, target_id, action, metadata, ip_address)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE (sqlc.narg(cursor)::BIGINT IS NULL OR id < sqlc.narg(cursor))
+   AND (sqlc.narg(actor_id)::uuid IS NULL OR actor_id = sqlc.narg(actor_id))
+   AND (sqlc.narg(target_id)::uuid IS NULL OR target_id = sqlc.narg(target_id))
+   AND (sqlc.narg(action)::VARCHAR IS NULL OR action = sqlc.narg(action))
+   AND (sqlc.narg(created_from)::TIMESTAMPTZ IS NULL OR created_at >= sqlc.narg(created_from))
+   AND (sqlc.narg(created_to)::TIMESTAMPTZ IS NULL OR created_at <= sqlc.narg(created_to))
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit);
\ No newline at end of file
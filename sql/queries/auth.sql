@@ -22,6 +22,10 @@ SET
    email = COALESCE(sqlc.narg(email), email),
    harshed_password = COALESCE(sqlc.narg(harshed_password), harshed_password), 
    password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at),
+   email_verified_at = CASE
+      WHEN sqlc.narg(email) IS NOT NULL AND sqlc.narg(email) <> email THEN NULL
+      ELSE email_verified_at
+   END,
    updated_at = sqlc.arg(updated_at)
 WHERE id = sqlc.arg(id)
 RETURNING *;
@@ -31,26 +35,73 @@ UPDATE auth
 SET restricted = TRUE, updated_at = $2
 WHERE id = $1;
 
+-- name: UnrestrictAuth :execrows
+UPDATE auth
+SET restricted = FALSE, updated_at = $2
+WHERE id = $1 AND restricted = TRUE;
+
 -- name: DeleteAuth :exec
 UPDATE auth
-SET deleted = TRUE, updated_at = $2
+SET deleted = TRUE, deleted_at = $2, updated_at = $2
 WHERE id = $1;
 
+-- name: RestoreAuth :execrows
+UPDATE auth
+SET deleted = FALSE, deleted_at = NULL, updated_at = $2
+WHERE id = $1
+   AND deleted = TRUE
+   AND deleted_at > NOW() - INTERVAL '30 days';
+
 -- name: GetDeletedUsers :one
 SELECT COUNT(*) 
    FROM auth 
 WHERE role = 'user' 
    and deleted = TRUE
-   AND updated_at < NOW() - INTERVAL '30 days'
+   AND deleted_at < NOW() - INTERVAL '30 days'
 ;
 
 -- name: DeleteUserAuthCron :many
-DELETE FROM auth 
-WHERE id IN (
+WITH expired AS (
    SELECT id FROM
    auth WHERE role = 'user'
    and deleted = TRUE
-   and updated_at < NOW() - INTERVAL '30 days'
+   and deleted_at < NOW() - INTERVAL '30 days'
    LIMIT $1
+), expired_users AS (
+   DELETE FROM users
+   WHERE auth_id IN (SELECT id FROM expired)
 )
-RETURNING *;
\ No newline at end of file
+DELETE FROM auth 
+WHERE id IN (SELECT id FROM expired)
+RETURNING *;
+
+-- name: RecordAuthLoginFailure :one
+UPDATE auth
+SET failed_attempts = CASE
+      WHEN last_failed_login_at < sqlc.arg(window_start) THEN 1
+      ELSE failed_attempts + 1
+   END,
+   last_failed_login_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING failed_attempts;
+
+-- name: LockAuth :exec
+UPDATE auth
+SET locked_until = $2
+WHERE id = $1;
+
+-- name: UnlockAuth :exec
+UPDATE auth
+SET failed_attempts = 0, locked_until = NULL
+WHERE id = $1;
+
+-- name: RecordAuthLogin :exec
+UPDATE auth
+SET failed_attempts = 0, locked_until = NULL, last_login_at = NOW()
+WHERE id = $1;
+
+
+-- name: VerifyAuthEmail :exec
+UPDATE auth
+SET email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND email_verified_at IS NULL;
@@ -0,0 +1,24 @@
+-- name: CreateAuthToken :one
+INSERT INTO auth_tokens (id, auth_id, purpose, token_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UseAuthToken :one
+UPDATE auth_tokens
+SET used_at = NOW()
+WHERE token_hash = $1
+   AND purpose = $2
+   AND used_at IS NULL
+   AND expires_at > NOW()
+RETURNING *;
+
+-- name: InvalidateAuthTokens :exec
+UPDATE auth_tokens
+SET used_at = NOW()
+WHERE auth_id = $1
+   AND purpose = $2
+   AND used_at IS NULL;
+
+-- name: DeleteExpiredAuthTokens :execrows
+DELETE FROM auth_tokens
+WHERE expires_at < NOW() - INTERVAL '1 day';
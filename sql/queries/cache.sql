@@ -0,0 +1,16 @@
+-- name: GetCacheEntry :one
+SELECT value FROM cache_entries
+WHERE key = $1
+   AND (expires_at IS NULL OR expires_at > NOW())
+LIMIT 1;
+
+-- name: SetCacheEntry :exec
+INSERT INTO cache_entries (key, value, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE
+SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at, created_at = NOW();
+
+-- name: DeleteExpiredCacheEntries :execrows
+DELETE FROM cache_entries
+WHERE expires_at IS NOT NULL
+   AND expires_at < NOW();
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- name: CreateConnectionRule :one
+INSERT INTO connection_rules (id, target, port, db_type, description, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListConnectionRules :many
+SELECT * FROM connection_rules
+ORDER BY created_at;
+
+-- name: DeleteConnectionRule :execrows
+DELETE FROM connection_rules
+WHERE id = $1;
@@ -0,0 +1,31 @@
+-- name: CreateConnection :one
+INSERT INTO connections (id, organization_id, name, db_type, db_name, db_url, created_by, max_estimated_rows, max_estimated_cost, cost_action)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetConnection :one
+SELECT * FROM connections
+WHERE id = $1 LIMIT 1;
+
+-- name: ListConnections :many
+SELECT * FROM connections
+WHERE organization_id = $1
+ORDER BY name;
+
+-- name: UpdateConnection :one
+UPDATE connections
+SET
+   name = COALESCE(sqlc.narg(name), name),
+   db_type = COALESCE(sqlc.narg(db_type), db_type),
+   db_name = COALESCE(sqlc.narg(db_name), db_name),
+   db_url = COALESCE(sqlc.narg(db_url), db_url),
+   max_estimated_rows = COALESCE(sqlc.narg(max_estimated_rows), max_estimated_rows),
+   max_estimated_cost = COALESCE(sqlc.narg(max_estimated_cost), max_estimated_cost),
+   cost_action = COALESCE(sqlc.narg(cost_action), cost_action),
+   updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteConnection :exec
+DELETE FROM connections
+WHERE id = $1;
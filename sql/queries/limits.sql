@@ -0,0 +1,29 @@
+-- name: GetUserLimits :one
+SELECT * FROM user_limits
+WHERE auth_id = $1
+LIMIT 1;
+
+-- name: SetUserLimits :one
+INSERT INTO user_limits (auth_id, requests_per_minute, burst, monthly_token_quota)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (auth_id) DO UPDATE
+SET requests_per_minute = EXCLUDED.requests_per_minute,
+   burst = EXCLUDED.burst,
+   monthly_token_quota = EXCLUDED.monthly_token_quota,
+   updated_at = NOW()
+RETURNING *;
+
+-- name: GetLLMUsage :one
+SELECT * FROM llm_usage
+WHERE auth_id = $1 AND period = $2
+LIMIT 1;
+
+-- name: AddLLMUsage :one
+INSERT INTO llm_usage (auth_id, period, prompt_tokens, completion_tokens, requests)
+VALUES ($1, $2, $3, $4, 1)
+ON CONFLICT (auth_id, period) DO UPDATE
+SET prompt_tokens = llm_usage.prompt_tokens + EXCLUDED.prompt_tokens,
+   completion_tokens = llm_usage.completion_tokens + EXCLUDED.completion_tokens,
+   requests = llm_usage.requests + 1,
+   updated_at = NOW()
+RETURNING *;
@@ -0,0 +1,25 @@
+-- name: GetLoginAttempt :one
+SELECT * FROM login_attempts
+WHERE ip_address = $1
+LIMIT 1;
+
+-- name: RecordLoginAttemptFailure :one
+INSERT INTO login_attempts (ip_address, failed_attempts, last_failed_at)
+VALUES (sqlc.arg(ip_address), 1, NOW())
+ON CONFLICT (ip_address) DO UPDATE
+SET failed_attempts = CASE
+      WHEN login_attempts.last_failed_at < sqlc.arg(window_start) THEN 1
+      ELSE login_attempts.failed_attempts + 1
+   END,
+   last_failed_at = NOW()
+RETURNING *;
+
+-- name: LockLoginAttempt :exec
+UPDATE login_attempts
+SET locked_until = $2
+WHERE ip_address = $1;
+
+-- name: DeleteExpiredLoginAttempts :execrows
+DELETE FROM login_attempts
+WHERE last_failed_at < NOW() - INTERVAL '1 day'
+   AND (locked_until IS NULL OR locked_until < NOW());
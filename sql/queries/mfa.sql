@@ -0,0 +1,51 @@
+-- name: CreateTOTPCredential :one
+INSERT INTO totp_credentials (auth_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (auth_id) DO UPDATE
+SET secret = EXCLUDED.secret,
+   last_used_step = 0,
+   created_at = NOW()
+WHERE totp_credentials.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetTOTPCredential :one
+SELECT * FROM totp_credentials
+WHERE auth_id = $1;
+
+-- name: ConfirmTOTPCredential :execrows
+UPDATE totp_credentials
+SET confirmed_at = NOW(),
+   last_used_step = $2
+WHERE auth_id = $1
+   AND confirmed_at IS NULL;
+
+-- name: UseTOTPStep :execrows
+UPDATE totp_credentials
+SET last_used_step = $2
+WHERE auth_id = $1
+   AND confirmed_at IS NOT NULL
+   AND last_used_step < $2;
+
+-- name: DeleteTOTPCredential :exec
+DELETE FROM totp_credentials
+WHERE auth_id = $1;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (id, auth_id, code_hash)
+VALUES ($1, $2, $3);
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = NOW()
+WHERE auth_id = $1
+   AND code_hash = $2
+   AND used_at IS NULL;
+
+-- name: CountRecoveryCodes :one
+SELECT COUNT(*) FROM recovery_codes
+WHERE auth_id = $1
+   AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE auth_id = $1;
@@ -0,0 +1,10 @@
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions (id, auth_id, admin_id, action, reason)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListModerationActions :many
+SELECT * FROM moderation_actions
+WHERE auth_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
\ No newline at end of file
@@ -0,0 +1,64 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (id, name, created_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetOrganization :one
+SELECT * FROM organizations
+WHERE id = $1 LIMIT 1;
+
+-- name: LockOrganization :exec
+SELECT id FROM organizations
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateOrganization :one
+UPDATE organizations
+SET name = $2,
+   updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteOrganization :exec
+DELETE FROM organizations
+WHERE id = $1;
+
+-- name: ListUserOrganizations :many
+SELECT o.id, o.name, o.created_at, m.role FROM organizations o
+JOIN organization_members m ON m.organization_id = o.id
+WHERE m.auth_id = $1
+ORDER BY o.created_at;
+
+-- name: AddOrganizationMember :one
+INSERT INTO organization_members (organization_id, auth_id, role)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetOrganizationMember :one
+SELECT * FROM organization_members
+WHERE organization_id = $1
+   AND auth_id = $2
+LIMIT 1;
+
+-- name: ListOrganizationMembers :many
+SELECT m.auth_id, a.email, m.role, m.created_at FROM organization_members m
+JOIN auth a ON a.id = m.auth_id
+WHERE m.organization_id = $1
+ORDER BY m.created_at;
+
+-- name: UpdateOrganizationMemberRole :one
+UPDATE organization_members
+SET role = $3
+WHERE organization_id = $1
+   AND auth_id = $2
+RETURNING *;
+
+-- name: RemoveOrganizationMember :execrows
+DELETE FROM organization_members
+WHERE organization_id = $1
+   AND auth_id = $2;
+
+-- name: CountOrganizationOwners :one
+SELECT COUNT(*) FROM organization_members
+WHERE organization_id = $1
+   AND role = 'owner';
@@ -0,0 +1,18 @@
+-- name: CreateQueryLog :one
+INSERT INTO query_logs (
+   user_id, connection_id, db_type, question, sql_query, valid, result_cached, row_count, duration_ms, error
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: ListQueryLogs :many
+SELECT * FROM query_logs
+WHERE (sqlc.narg(cursor)::BIGINT IS NULL OR id < sqlc.narg(cursor))
+   AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id))
+   AND (sqlc.narg(connection_id)::VARCHAR IS NULL OR connection_id = sqlc.narg(connection_id))
+   AND (sqlc.narg(valid)::BOOLEAN IS NULL OR valid = sqlc.narg(valid))
+   AND (sqlc.narg(failed)::BOOLEAN IS NULL OR (error <> '') = sqlc.narg(failed))
+   AND (sqlc.narg(created_from)::TIMESTAMPTZ IS NULL OR created_at >= sqlc.narg(created_from))
+   AND (sqlc.narg(created_to)::TIMESTAMPTZ IS NULL OR created_at <= sqlc.narg(created_to))
+ORDER BY id DESC
+LIMIT sqlc.arg(page_limit);
@@ -0,0 +1,27 @@
+-- name: CreateSavedQuestion :one
+INSERT INTO saved_questions (id, organization_id, connection_id, title, question, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetSavedQuestion :one
+SELECT * FROM saved_questions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSavedQuestions :many
+SELECT * FROM saved_questions
+WHERE organization_id = $1
+ORDER BY created_at DESC;
+
+-- name: UpdateSavedQuestion :one
+UPDATE saved_questions
+SET
+   title = COALESCE(sqlc.narg(title), title),
+   question = COALESCE(sqlc.narg(question), question),
+   connection_id = COALESCE(sqlc.narg(connection_id), connection_id),
+   updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteSavedQuestion :exec
+DELETE FROM saved_questions
+WHERE id = $1;
@@ -13,8 +13,70 @@ WHERE id = sqlc.arg(id)
 RETURNING *;
 
 -- name: GetUser :one
-SELECT id, username, full_name, created_at, updated_at FROM users
-WHERE auth_id = $1 LIMIT 1;
+SELECT users.id, users.username, users.full_name, users.created_at, users.updated_at FROM users
+JOIN auth ON auth.id = users.auth_id
+WHERE users.auth_id = $1 AND auth.deleted = FALSE LIMIT 1;
 
 -- name: DeleteUser :exec
-DELETE FROM users WHERE id = $1;
\ No newline at end of file
+DELETE FROM users WHERE id = $1;
+
+-- name: ListUsers :many
+SELECT
+   auth.id,
+   auth.email,
+   auth.role,
+   auth.restricted,
+   auth.deleted,
+   auth.created_at,
+   auth.updated_at,
+   COALESCE(users.username, admins.username, '')::VARCHAR AS username,
+   COALESCE(users.full_name, admins.full_name, '')::VARCHAR AS full_name
+FROM auth
+LEFT JOIN users ON users.auth_id = auth.id
+LEFT JOIN admins ON admins.auth_id = auth.id
+WHERE (sqlc.narg(restricted)::BOOLEAN IS NULL OR auth.restricted = sqlc.narg(restricted))
+   AND (sqlc.narg(deleted)::BOOLEAN IS NULL OR auth.deleted = sqlc.narg(deleted))
+   AND (sqlc.narg(role)::role_type IS NULL OR auth.role = sqlc.narg(role))
+   AND (sqlc.narg(created_from)::TIMESTAMPTZ IS NULL OR auth.created_at >= sqlc.narg(created_from))
+   AND (sqlc.narg(created_to)::TIMESTAMPTZ IS NULL OR auth.created_at <= sqlc.narg(created_to))
+   AND (sqlc.narg(search)::VARCHAR IS NULL
+      OR auth.email ILIKE '%' || sqlc.narg(search) || '%'
+      OR users.username ILIKE '%' || sqlc.narg(search) || '%'
+      OR admins.username ILIKE '%' || sqlc.narg(search) || '%')
+ORDER BY auth.created_at DESC, auth.id
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountUsers :one
+SELECT COUNT(*)
+FROM auth
+LEFT JOIN users ON users.auth_id = auth.id
+LEFT JOIN admins ON admins.auth_id = auth.id
+WHERE (sqlc.narg(restricted)::BOOLEAN IS NULL OR auth.restricted = sqlc.narg(restricted))
+   AND (sqlc.narg(deleted)::BOOLEAN IS NULL OR auth.deleted = sqlc.narg(deleted))
+   AND (sqlc.narg(role)::role_type IS NULL OR auth.role = sqlc.narg(role))
+   AND (sqlc.narg(created_from)::TIMESTAMPTZ IS NULL OR auth.created_at >= sqlc.narg(created_from))
+   AND (sqlc.narg(created_to)::TIMESTAMPTZ IS NULL OR auth.created_at <= sqlc.narg(created_to))
+   AND (sqlc.narg(search)::VARCHAR IS NULL
+      OR auth.email ILIKE '%' || sqlc.narg(search) || '%'
+      OR users.username ILIKE '%' || sqlc.narg(search) || '%'
+      OR admins.username ILIKE '%' || sqlc.narg(search) || '%');
+
+-- name: GetUserDetail :one
+SELECT
+   auth.id,
+   auth.email,
+   auth.role,
+   auth.restricted,
+   auth.deleted,
+   auth.password_changed_at,
+   auth.last_login_at,
+   auth.failed_attempts,
+   auth.locked_until,
+   auth.created_at,
+   auth.updated_at,
+   COALESCE(users.username, admins.username, '')::VARCHAR AS username,
+   COALESCE(users.full_name, admins.full_name, '')::VARCHAR AS full_name
+FROM auth
+LEFT JOIN users ON users.auth_id = auth.id
+LEFT JOIN admins ON admins.auth_id = auth.id
+WHERE auth.id = $1 LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE cache_entries (
+   key VARCHAR PRIMARY KEY,
+   value JSONB NOT NULL,
+   expires_at TIMESTAMPTZ,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX cache_entries_expires_at_idx ON cache_entries (expires_at);
+
+-- +goose Down
+DROP TABLE cache_entries;
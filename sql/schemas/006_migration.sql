@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE moderation_actions (
+   id uuid PRIMARY KEY,
+   auth_id uuid NOT NULL REFERENCES auth(id) ON DELETE CASCADE,
+   admin_id uuid NOT NULL REFERENCES auth(id),
+   action VARCHAR NOT NULL,
+   reason VARCHAR NOT NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX moderation_actions_auth_id_idx ON moderation_actions (auth_id);
+
+-- +goose Down
+DROP TABLE moderation_actions;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE audit_events (
+   id BIGSERIAL PRIMARY KEY,
+   actor_id uuid,
+   target_id uuid,
+   action VARCHAR NOT NULL,
+   metadata JSONB NOT NULL DEFAULT '{}',
+   ip_address VARCHAR NOT NULL DEFAULT '',
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_events_actor_id_idx ON audit_events (actor_id);
+CREATE INDEX audit_events_target_id_idx ON audit_events (target_id);
+CREATE INDEX audit_events_action_idx ON audit_events (action);
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
+
+-- +goose Down
+DROP TABLE audit_events;
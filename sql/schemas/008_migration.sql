@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE query_logs (
+   id BIGSERIAL PRIMARY KEY,
+   user_id uuid NOT NULL,
+   connection_id VARCHAR NOT NULL,
+   db_type VARCHAR NOT NULL,
+   question TEXT NOT NULL,
+   sql_query TEXT NOT NULL,
+   valid BOOLEAN NOT NULL,
+   result_cached BOOLEAN NOT NULL DEFAULT FALSE,
+   row_count INTEGER NOT NULL DEFAULT 0,
+   duration_ms BIGINT NOT NULL DEFAULT 0,
+   error TEXT NOT NULL DEFAULT '',
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX query_logs_user_id_idx ON query_logs (user_id);
+CREATE INDEX query_logs_connection_id_idx ON query_logs (connection_id);
+CREATE INDEX query_logs_created_at_idx ON query_logs (created_at);
+
+-- +goose Down
+DROP TABLE query_logs;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE user_limits (
+   auth_id uuid PRIMARY KEY REFERENCES auth(id) ON DELETE CASCADE,
+   requests_per_minute INTEGER,
+   burst INTEGER,
+   monthly_token_quota BIGINT,
+   updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE llm_usage (
+   auth_id uuid NOT NULL REFERENCES auth(id) ON DELETE CASCADE,
+   period DATE NOT NULL,
+   prompt_tokens BIGINT NOT NULL DEFAULT 0,
+   completion_tokens BIGINT NOT NULL DEFAULT 0,
+   requests INTEGER NOT NULL DEFAULT 0,
+   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   PRIMARY KEY (auth_id, period)
+);
+
+-- +goose Down
+DROP TABLE llm_usage;
+DROP TABLE user_limits;
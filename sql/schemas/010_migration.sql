@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE auth
+   ADD COLUMN last_login_at TIMESTAMPTZ,
+   ADD COLUMN failed_attempts INTEGER NOT NULL DEFAULT 0,
+   ADD COLUMN locked_until TIMESTAMPTZ;
+
+CREATE TABLE login_attempts (
+   ip_address VARCHAR PRIMARY KEY,
+   failed_attempts INTEGER NOT NULL DEFAULT 0,
+   last_failed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   locked_until TIMESTAMPTZ
+);
+
+-- +goose Down
+DROP TABLE login_attempts;
+
+ALTER TABLE auth
+   DROP COLUMN locked_until,
+   DROP COLUMN failed_attempts,
+   DROP COLUMN last_login_at;
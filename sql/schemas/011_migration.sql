@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE auth ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+-- accounts created before email verification are treated as verified
+UPDATE auth SET email_verified_at = created_at;
+
+CREATE TABLE auth_tokens (
+   id uuid PRIMARY KEY,
+   auth_id uuid NOT NULL REFERENCES auth(id) ON DELETE CASCADE,
+   purpose VARCHAR NOT NULL,
+   token_hash VARCHAR UNIQUE NOT NULL,
+   expires_at TIMESTAMPTZ NOT NULL,
+   used_at TIMESTAMPTZ,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX auth_tokens_auth_id_idx ON auth_tokens (auth_id);
+
+-- +goose Down
+DROP TABLE auth_tokens;
+
+ALTER TABLE auth DROP COLUMN email_verified_at;
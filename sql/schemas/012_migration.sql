@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE totp_credentials (
+   auth_id uuid PRIMARY KEY REFERENCES auth(id) ON DELETE CASCADE,
+   secret VARCHAR NOT NULL,
+   confirmed_at TIMESTAMPTZ,
+   last_used_step BIGINT NOT NULL DEFAULT 0,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE recovery_codes (
+   id uuid PRIMARY KEY,
+   auth_id uuid NOT NULL REFERENCES auth(id) ON DELETE CASCADE,
+   code_hash VARCHAR NOT NULL,
+   used_at TIMESTAMPTZ,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   UNIQUE (auth_id, code_hash)
+);
+
+-- +goose Down
+DROP TABLE recovery_codes;
+
+DROP TABLE totp_credentials;
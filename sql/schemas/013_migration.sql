@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE admin_invitations (
+   id uuid PRIMARY KEY,
+   email VARCHAR NOT NULL,
+   token_hash VARCHAR UNIQUE NOT NULL,
+   invited_by uuid REFERENCES auth(id) ON DELETE SET NULL,
+   expires_at TIMESTAMPTZ NOT NULL,
+   accepted_by uuid REFERENCES auth(id) ON DELETE SET NULL,
+   accepted_at TIMESTAMPTZ,
+   revoked_at TIMESTAMPTZ,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX admin_invitations_created_at_idx ON admin_invitations (created_at);
+
+-- +goose Down
+DROP TABLE admin_invitations;
@@ -0,0 +1,58 @@
+-- +goose Up
+CREATE TYPE workspace_role AS ENUM ('owner', 'editor', 'viewer');
+
+CREATE TABLE organizations (
+   id uuid PRIMARY KEY,
+   name VARCHAR NOT NULL,
+   created_by uuid REFERENCES auth(id) ON DELETE SET NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE organization_members (
+   organization_id uuid NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+   auth_id uuid NOT NULL REFERENCES auth(id) ON DELETE CASCADE,
+   role workspace_role NOT NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   PRIMARY KEY (organization_id, auth_id)
+);
+
+CREATE INDEX organization_members_auth_id_idx ON organization_members (auth_id);
+
+-- db_url is encrypted as it contains the database credentials
+CREATE TABLE connections (
+   id uuid PRIMARY KEY,
+   organization_id uuid NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+   name VARCHAR NOT NULL,
+   db_type VARCHAR NOT NULL,
+   db_name VARCHAR NOT NULL,
+   db_url VARCHAR NOT NULL,
+   created_by uuid REFERENCES auth(id) ON DELETE SET NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   UNIQUE (organization_id, name)
+);
+
+CREATE TABLE saved_questions (
+   id uuid PRIMARY KEY,
+   organization_id uuid NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+   connection_id uuid REFERENCES connections(id) ON DELETE SET NULL,
+   title VARCHAR NOT NULL,
+   question TEXT NOT NULL,
+   created_by uuid REFERENCES auth(id) ON DELETE SET NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX saved_questions_organization_id_idx ON saved_questions (organization_id);
+
+-- +goose Down
+DROP TABLE saved_questions;
+
+DROP TABLE connections;
+
+DROP TABLE organization_members;
+
+DROP TABLE organizations;
+
+DROP TYPE workspace_role;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE connection_rules (
+   id uuid PRIMARY KEY,
+   target VARCHAR NOT NULL,
+   port INTEGER,
+   db_type VARCHAR,
+   description VARCHAR NOT NULL DEFAULT '',
+   created_by uuid REFERENCES auth(id) ON DELETE SET NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE connection_rules;
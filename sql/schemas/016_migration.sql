@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE connections
+   ADD COLUMN max_estimated_rows BIGINT,
+   ADD COLUMN max_estimated_cost DOUBLE PRECISION,
+   ADD COLUMN cost_action VARCHAR CHECK (cost_action IN ('confirm', 'refuse'));
+
+-- +goose Down
+ALTER TABLE connections
+   DROP COLUMN max_estimated_rows,
+   DROP COLUMN max_estimated_cost,
+   DROP COLUMN cost_action;
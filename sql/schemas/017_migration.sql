@@ -0,0 +1,8 @@
+-- +goose Up
+-- deleted_at keys the restore window and the purge of deleted accounts, updated_at moves with any later write
+ALTER TABLE auth ADD COLUMN deleted_at TIMESTAMPTZ;
+
+UPDATE auth SET deleted_at = updated_at WHERE deleted = TRUE;
+
+-- +goose Down
+ALTER TABLE auth DROP COLUMN deleted_at;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- failed logins of an account decay like those of an IP address, counting from the last failure
+ALTER TABLE auth ADD COLUMN last_failed_login_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE auth DROP COLUMN last_failed_login_at;
@@ -0,0 +1,61 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCode(t *testing.T) {
+	// SHA1 test vectors of RFC 6238 appendix B
+	key := []byte("12345678901234567890")
+	vectors := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+
+	for unix, code := range vectors {
+		step := Step(time.Unix(unix, 0))
+		require.Equal(t, code, generateCode(key, uint64(step), 8))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := Code(secret, Step(now))
+	require.NoError(t, err)
+	require.Len(t, code, Digits)
+
+	step, ok := Validate(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, Step(now), step)
+
+	// codes of adjacent periods are accepted to allow for clock drift
+	_, ok = Validate(secret, code, now.Add(Period*time.Second))
+	require.True(t, ok)
+
+	_, ok = Validate(secret, code, now.Add(3*Period*time.Second))
+	require.False(t, ok)
+
+	_, ok = Validate(secret, "abcdef", now)
+	require.False(t, ok)
+
+	_, ok = Validate("not a secret!", code, now)
+	require.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("nlp-to-sql", "admin@example.com", "JBSWY3DPEHPK3PXP")
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/nlp-to-sql:admin@example.com?"))
+	require.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	require.Contains(t, uri, "issuer=nlp-to-sql")
+}
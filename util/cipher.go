@@ -0,0 +1,56 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// EncryptSecret encrypts a secret that has to be stored in a recoverable form, such as a TOTP secret.
+// The encryption key is derived from key and purpose, so that a key can be shared by different purposes.
+func EncryptSecret(key, purpose, secret string) (string, error) {
+	aead, err := chacha20poly1305.NewX(deriveKey(key, purpose))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(secret)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(secret), []byte(purpose))
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret decrypts a secret encrypted by EncryptSecret with the same key and purpose.
+func DecryptSecret(key, purpose, encrypted string) (string, error) {
+	aead, err := chacha20poly1305.NewX(deriveKey(key, purpose))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("failed to decrypt secret: ciphertext too short")
+	}
+
+	secret, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(purpose))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return string(secret), nil
+}
+
+func deriveKey(key, purpose string) []byte {
+	sum := sha256.Sum256([]byte(purpose + ":" + key))
+	return sum[:]
+}
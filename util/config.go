@@ -23,6 +23,43 @@ type Config struct {
 	CronSchedule        string
 	CronBatchSize       string
 	LogPath             string
+	CacheType           string
+	CacheSize           string
+	CacheResultTTL      time.Duration
+	RateLimitPerMinute  string
+	RateLimitBurst      string
+	MonthlyTokenQuota   string
+	AppUrl              string
+	MailerType          string
+	MailFrom            string
+	MailLogPath         string
+	SMTPHost            string
+	SMTPPort            string
+	SMTPUsername        string
+	SMTPPassword        string
+	AdminMFARequired    string
+	AdminBootstrapToken string
+	ConnectionPolicy    string
+	PoolMaxOpenConns    string
+	PoolMaxIdleConns    string
+	ChatMaxInFlight     string
+	TrustedProxies      string
+
+	QueryMaxEstimatedRows string
+	QueryMaxEstimatedCost string
+	QueryCostAction       string
+	QueryMaxRows          string
+
+	AdminTokenSymmetricKey string
+
+	EmailVerificationTokenDuration time.Duration
+	PasswordResetTokenDuration     time.Duration
+	AdminInvitationDuration        time.Duration
+	PoolConnMaxIdleTime            time.Duration
+	PoolConnMaxLifetime            time.Duration
+	PoolHealthCheckInterval        time.Duration
+	ShutdownTimeout                time.Duration
+	QueryTimeout                   time.Duration
 }
 
 func LoadConfig(path string) (config Config, err error) {
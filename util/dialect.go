@@ -0,0 +1,8 @@
+package util
+
+// SQL dialects supported for query generation and validation.
+// They match the database types sent by clients when starting a chat.
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+)
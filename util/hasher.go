@@ -1,6 +1,10 @@
 package util
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
@@ -19,3 +23,22 @@ func HashPassword(password string) (string, error) {
 func CheckPassword(password string, hashedPassowrd string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassowrd), []byte(password))
 }
+
+// NewSecretToken returns a random URL-safe token with 32 bytes of entropy and its hash,
+// only the hash should be stored so that stored tokens cannot be used.
+func NewSecretToken() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashSecretToken(token), nil
+}
+
+// HashSecretToken returns the SHA-256 hash of a token generated by NewSecretToken.
+// A fast hash is sufficient as the token is random rather than chosen by a user.
+func HashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,269 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/auxten/postgresql-parser/pkg/sql/parser"
+	"github.com/auxten/postgresql-parser/pkg/sql/sem/tree"
+	"github.com/xwb1989/sqlparser"
+)
+
+// QueryAnalysis describes a query validated against the policy of ValidQuery.
+// Diagnostics explain why the query is not valid, they are empty for valid queries.
+// Tables are the tables the query reads, and Columns the columns it references, qualified
+// with their table ("table.column") when the table can be resolved from the query.
+type QueryAnalysis struct {
+	Valid       bool
+	Diagnostics []string
+	Tables      []string
+	Columns     []string
+}
+
+// AnalyzeQuery validates a query like ValidQuery and reports why it is not valid, along with the
+// tables and columns it touches. The query is parsed only with the parser of the dialect of the target database,
+// tables and columns are reported whenever it can be parsed, even when it is not a SELECT statement.
+func AnalyzeQuery(query, dialect string) QueryAnalysis {
+	var analysis QueryAnalysis
+
+	if words := restrictedWords(query); len(words) > 0 {
+		analysis.Diagnostics = append(analysis.Diagnostics, fmt.Sprintf("the query references restricted data: %v", words))
+	}
+
+	refs := newQueryRefs()
+	switch dialect {
+	case DialectMySQL:
+		mStmt, err := sqlparser.Parse(query)
+		if err != nil {
+			analysis.Diagnostics = append(analysis.Diagnostics, fmt.Sprintf("the query cannot be parsed as %v: %v", dialect, err))
+			break
+		}
+		if _, ok := mStmt.(*sqlparser.Select); !ok {
+			analysis.Diagnostics = append(analysis.Diagnostics, "the query is not a SELECT statement")
+		}
+		refs.walkMySQL(mStmt)
+
+	case DialectPostgres:
+		pStmt, err := parser.ParseOne(query)
+		if err != nil || pStmt.AST == nil {
+			if err == nil {
+				err = fmt.Errorf("empty statement")
+			}
+			analysis.Diagnostics = append(analysis.Diagnostics, fmt.Sprintf("the query cannot be parsed as %v: %v", dialect, err))
+			break
+		}
+		if tag := pStmt.AST.StatementTag(); tag != "SELECT" {
+			analysis.Diagnostics = append(analysis.Diagnostics, fmt.Sprintf("the query is not a SELECT statement: %v", tag))
+		}
+		if stmt, ok := pStmt.AST.(*tree.Select); ok {
+			refs.walkPostgresSelect(stmt)
+		}
+
+	default:
+		analysis.Diagnostics = append(analysis.Diagnostics, fmt.Sprintf("unsupported dialect: %v", dialect))
+	}
+
+	analysis.Valid = len(analysis.Diagnostics) == 0
+	analysis.Tables, analysis.Columns = refs.result()
+	return analysis
+}
+
+// queryRefs collects the tables and columns referenced by a query.
+// Aliases map the aliases of tables to their table, derived tables and common table expressions
+// map to themselves and are not reported as tables. Outputs are the aliases of the selected expressions.
+type queryRefs struct {
+	tables  map[string]bool
+	aliases map[string]string
+	outputs map[string]bool
+	// columns are the referenced columns with their qualifier, which is empty for unqualified columns.
+	columns [][2]string
+}
+
+func newQueryRefs() *queryRefs {
+	return &queryRefs{
+		tables:  make(map[string]bool),
+		aliases: make(map[string]string),
+		outputs: make(map[string]bool),
+	}
+}
+
+func (refs *queryRefs) addTable(name, alias string) {
+	if _, ok := refs.aliases[name]; ok && !refs.tables[name] {
+		// a common table expression or derived table
+		return
+	}
+	refs.tables[name] = true
+	if alias != "" {
+		refs.aliases[alias] = name
+	}
+}
+
+func (refs *queryRefs) addDerived(alias string) {
+	if alias != "" {
+		refs.aliases[alias] = alias
+	}
+}
+
+func (refs *queryRefs) addColumn(qualifier, name string) {
+	refs.columns = append(refs.columns, [2]string{qualifier, name})
+}
+
+// result returns the sorted tables and columns. Unqualified columns are qualified with the table
+// of the query when it reads a single table, and columns naming a selected expression are left out.
+func (refs *queryRefs) result() ([]string, []string) {
+	tables := sortedKeys(refs.tables)
+
+	columns := make(map[string]bool)
+	for _, col := range refs.columns {
+		qualifier, name := col[0], col[1]
+		switch {
+		case qualifier != "":
+			if table, ok := refs.aliases[qualifier]; ok {
+				qualifier = table
+			}
+			columns[qualifier+"."+name] = true
+		case refs.outputs[name]:
+		case len(tables) == 1:
+			columns[tables[0]+"."+name] = true
+		default:
+			columns[name] = true
+		}
+	}
+
+	return tables, sortedKeys(columns)
+}
+
+// walkMySQL collects the references of a statement parsed by the MySQL parser.
+func (refs *queryRefs) walkMySQL(stmt sqlparser.Statement) {
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.AliasedTableExpr:
+			switch expr := node.Expr.(type) {
+			case sqlparser.TableName:
+				refs.addTable(expr.Name.String(), node.As.String())
+			case *sqlparser.Subquery:
+				refs.addDerived(node.As.String())
+			}
+		case *sqlparser.AliasedExpr:
+			if !node.As.IsEmpty() {
+				refs.outputs[node.As.String()] = true
+			}
+		case *sqlparser.ColName:
+			refs.addColumn(node.Qualifier.Name.String(), node.Name.String())
+		}
+		return true, nil
+	}, stmt)
+}
+
+// walkPostgresSelect collects the references of a select statement parsed by the Postgres parser.
+// Common table expressions are walked first, so that the tables of the query that name them are not reported.
+func (refs *queryRefs) walkPostgresSelect(stmt *tree.Select) {
+	if stmt == nil {
+		return
+	}
+
+	if stmt.With != nil {
+		for _, cte := range stmt.With.CTEList {
+			refs.addDerived(string(cte.Name.Alias))
+			if sel, ok := cte.Stmt.(*tree.Select); ok {
+				refs.walkPostgresSelect(sel)
+			}
+		}
+	}
+
+	refs.walkPostgresSelectStatement(stmt.Select)
+
+	for _, order := range stmt.OrderBy {
+		refs.walkPostgresExpr(order.Expr)
+	}
+}
+
+func (refs *queryRefs) walkPostgresSelectStatement(stmt tree.SelectStatement) {
+	switch stmt := stmt.(type) {
+	case *tree.SelectClause:
+		for _, table := range stmt.From.Tables {
+			refs.walkPostgresTable(table)
+		}
+		for _, expr := range stmt.Exprs {
+			if expr.As != "" {
+				refs.outputs[string(expr.As)] = true
+			}
+			refs.walkPostgresExpr(expr.Expr)
+		}
+		if stmt.Where != nil {
+			refs.walkPostgresExpr(stmt.Where.Expr)
+		}
+		for _, expr := range stmt.GroupBy {
+			refs.walkPostgresExpr(expr)
+		}
+		if stmt.Having != nil {
+			refs.walkPostgresExpr(stmt.Having.Expr)
+		}
+	case *tree.ParenSelect:
+		refs.walkPostgresSelect(stmt.Select)
+	case *tree.UnionClause:
+		refs.walkPostgresSelect(stmt.Left)
+		refs.walkPostgresSelect(stmt.Right)
+	}
+}
+
+func (refs *queryRefs) walkPostgresTable(table tree.TableExpr) {
+	switch table := table.(type) {
+	case *tree.AliasedTableExpr:
+		switch expr := table.Expr.(type) {
+		case *tree.TableName:
+			refs.addTable(expr.Table(), string(table.As.Alias))
+		case *tree.Subquery:
+			refs.addDerived(string(table.As.Alias))
+			refs.walkPostgresSubquery(expr)
+		}
+	case *tree.JoinTableExpr:
+		refs.walkPostgresTable(table.Left)
+		refs.walkPostgresTable(table.Right)
+		switch cond := table.Cond.(type) {
+		case *tree.OnJoinCond:
+			refs.walkPostgresExpr(cond.Expr)
+		case *tree.UsingJoinCond:
+			for _, col := range cond.Cols {
+				refs.addColumn("", string(col))
+			}
+		}
+	case *tree.ParenTableExpr:
+		refs.walkPostgresTable(table.Expr)
+	}
+}
+
+func (refs *queryRefs) walkPostgresSubquery(sub *tree.Subquery) {
+	refs.walkPostgresSelectStatement(sub.Select)
+}
+
+// walkPostgresExpr collects the columns of an expression and the references of its subqueries.
+func (refs *queryRefs) walkPostgresExpr(expr tree.Expr) {
+	if expr == nil {
+		return
+	}
+
+	_, _ = tree.SimpleVisit(expr, func(expr tree.Expr) (bool, tree.Expr, error) {
+		switch expr := expr.(type) {
+		case *tree.Subquery:
+			refs.walkPostgresSubquery(expr)
+			return false, expr, nil
+		case *tree.UnresolvedName:
+			// parts are in reverse order: column, table, schema, catalog
+			if !expr.Star {
+				refs.addColumn(expr.Parts[1], expr.Parts[0])
+			}
+			return false, expr, nil
+		}
+		return true, expr, nil
+	})
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,10 +1,8 @@
 package util
 
 import (
+	"sort"
 	"strings"
-
-	"github.com/auxten/postgresql-parser/pkg/sql/parser"
-	"github.com/xwb1989/sqlparser"
 )
 
 const (
@@ -20,47 +18,26 @@ var allowedKeywords = map[string]bool{
 	keywordHarshedPassword: true,
 }
 
-// containsRestrictedWords checks if the any sensitive keyword is present in a query.
+// restrictedWords returns the sensitive keywords present in a query, sorted.
 // It helps to add checks in a case where prompts could be engineered to disregard safety checks.
-func containsRestrictedWords(input string) bool {
+func restrictedWords(input string) []string {
 	input = strings.ToLower(input)
 
+	var words []string
 	for word := range allowedKeywords {
 		if strings.Contains(input, strings.ToLower(word)) {
-			return true
+			words = append(words, word)
 		}
 	}
-	return false
+	sort.Strings(words)
+	return words
 }
 
 // ValidQuery checks if parsed SQL Query is a valid query
 // a valid query in this case is a correct SQL which is also a SELECT statement.
 // It adds extra security to ensure only SELECT queries are validated.
-func ValidQuery(query string) bool {
-	if containsRestrictedWords(query) {
-		return false
-	}
-
-	mStmt, merr := sqlparser.Parse(query)
-	if mStmt != nil {
-		switch mStmt := mStmt.(type) {
-		case *sqlparser.Select:
-			_ = mStmt
-			return true
-		default:
-			return false
-		}
-	}
-
-	pStmt, perr := parser.ParseOne(query)
-	if pStmt.AST != nil {
-		switch pStmt.AST.StatementTag() {
-		case "SELECT":
-			return true
-		default:
-			return false
-		}
-	}
-
-	return merr == nil || perr == nil
+// The query is parsed only with the parser matching the dialect of the target database,
+// AnalyzeQuery reports why a query is not valid.
+func ValidQuery(query, dialect string) bool {
+	return AnalyzeQuery(query, dialect).Valid
 }
@@ -18,12 +18,18 @@ func TestValidQuery(t *testing.T) {
 		RETURNING *;`
 	stmt := `Hello there`
 
-	require.Equal(t, ValidQuery(postgresQuery), true)
-	require.Equal(t, ValidQuery(mysqlQuery), true)
-	require.Equal(t, ValidQuery(tSQuery), true)
-	require.Equal(t, ValidQuery(tUQuery), false)
-	require.Equal(t, ValidQuery(tDQuery), false)
-	require.Equal(t, ValidQuery(stmt), false)
+	require.Equal(t, ValidQuery(postgresQuery, DialectPostgres), true)
+	require.Equal(t, ValidQuery(mysqlQuery, DialectMySQL), true)
+	require.Equal(t, ValidQuery(tSQuery, DialectPostgres), true)
+	require.Equal(t, ValidQuery(tSQuery, DialectMySQL), true)
+	require.Equal(t, ValidQuery(tUQuery, DialectPostgres), false)
+	require.Equal(t, ValidQuery(tDQuery, DialectPostgres), false)
+	require.Equal(t, ValidQuery(stmt, DialectPostgres), false)
+	require.Equal(t, ValidQuery(stmt, DialectMySQL), false)
+
+	// queries are only validated with the parser of the target dialect
+	require.Equal(t, ValidQuery(mysqlQuery, DialectPostgres), false)
+	require.Equal(t, ValidQuery(tSQuery, "sqlite"), false)
 }
 
 func TestPasswordHash(t *testing.T) {
@@ -34,3 +40,82 @@ func TestPasswordHash(t *testing.T) {
 	err = CheckPassword(password, hashedPassword)
 	require.NoError(t, err)
 }
+
+func TestSecretToken(t *testing.T) {
+	token, hash, err := NewSecretToken()
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEqual(t, token, hash)
+	require.Equal(t, hash, HashSecretToken(token))
+
+	other, _, err := NewSecretToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+}
+
+func TestEncryptSecret(t *testing.T) {
+	key := RandomStr(32)
+	secret := RandomStr(16)
+
+	encrypted, err := EncryptSecret(key, "totp", secret)
+	require.NoError(t, err)
+	require.NotContains(t, encrypted, secret)
+
+	decrypted, err := DecryptSecret(key, "totp", encrypted)
+	require.NoError(t, err)
+	require.Equal(t, secret, decrypted)
+
+	// secrets can only be decrypted with the same key and purpose
+	_, err = DecryptSecret(RandomStr(32), "totp", encrypted)
+	require.Error(t, err)
+
+	_, err = DecryptSecret(key, "other", encrypted)
+	require.Error(t, err)
+}
+
+func TestAnalyzeQuery(t *testing.T) {
+	postgresQuery := `SELECT u.username, COUNT(*) AS total
+		FROM users u JOIN accounts a ON a.owner_id = u.id
+		WHERE a.balance > (SELECT AVG(balance) FROM accounts)
+		GROUP BY u.username
+		ORDER BY total DESC`
+
+	analysis := AnalyzeQuery(postgresQuery, DialectPostgres)
+	require.True(t, analysis.Valid)
+	require.Empty(t, analysis.Diagnostics)
+	require.Equal(t, []string{"accounts", "users"}, analysis.Tables)
+	require.Equal(t, []string{"accounts.balance", "accounts.owner_id", "balance", "users.id", "users.username"}, analysis.Columns)
+
+	mysqlQuery := "SELECT o.id, o.total FROM orders AS o WHERE o.created_at >= DATE_SUB(NOW(), INTERVAL 1 YEAR)"
+	analysis = AnalyzeQuery(mysqlQuery, DialectMySQL)
+	require.True(t, analysis.Valid)
+	require.Equal(t, []string{"orders"}, analysis.Tables)
+	require.Equal(t, []string{"orders.created_at", "orders.id", "orders.total"}, analysis.Columns)
+
+	// unqualified columns of a single table are qualified with it, common table expressions are not tables
+	cteQuery := `WITH recent AS (SELECT id, name FROM users WHERE created_at > NOW()) SELECT name FROM recent`
+	analysis = AnalyzeQuery(cteQuery, DialectPostgres)
+	require.True(t, analysis.Valid)
+	require.Equal(t, []string{"users"}, analysis.Tables)
+	require.Equal(t, []string{"users.created_at", "users.id", "users.name"}, analysis.Columns)
+
+	// invalid queries are reported with diagnostics, along with what they touch
+	analysis = AnalyzeQuery(`DELETE FROM users WHERE id = 1`, DialectPostgres)
+	require.False(t, analysis.Valid)
+	require.Equal(t, []string{"the query is not a SELECT statement: DELETE"}, analysis.Diagnostics)
+
+	analysis = AnalyzeQuery(`SELECT password FROM users`, DialectPostgres)
+	require.False(t, analysis.Valid)
+	require.Equal(t, []string{"the query references restricted data: [password]"}, analysis.Diagnostics)
+	require.Equal(t, []string{"users"}, analysis.Tables)
+	require.Equal(t, []string{"users.password"}, analysis.Columns)
+
+	analysis = AnalyzeQuery(`Hello there`, DialectMySQL)
+	require.False(t, analysis.Valid)
+	require.Len(t, analysis.Diagnostics, 1)
+	require.Contains(t, analysis.Diagnostics[0], "the query cannot be parsed as mysql")
+
+	analysis = AnalyzeQuery(`SELECT * FROM users`, "sqlite")
+	require.False(t, analysis.Valid)
+	require.Equal(t, []string{"unsupported dialect: sqlite"}, analysis.Diagnostics)
+}